@@ -0,0 +1,164 @@
+package ot
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const adminService = "AdminService"
+
+// AdminSession exposes the methods of AdminService, the calls fail on the server when the user
+// has no rights of the system administration.
+type AdminSession struct {
+	s *Session
+}
+
+// Admin returns the administration methods of the session.
+func (s *Session) Admin() *AdminSession {
+	return &AdminSession{s: s}
+}
+
+func (a *AdminSession) exec(ctx context.Context, method string, args oscript.M, res interface{}) error {
+	c, err := a.s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return errIn(c.Exec(adminService, method, a.s.auth, args, res))
+}
+
+// ListLogs returns names of the logs of the server.
+func (a *AdminSession) ListLogs(ctx context.Context) ([]string, error) {
+	var names []string
+	if err := a.exec(ctx, "ListLogs", oscript.M{}, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// LogSegment is a part of the log of the server.
+type LogSegment struct {
+	Name   string `oscript:"LogName"`
+	Offset int64  `oscript:"Offset"`
+	Data   string `oscript:"Data"`
+	Size   int64  `oscript:"LogSize"` // size of the whole log
+}
+
+// Next returns offset of the next segment.
+func (l *LogSegment) Next() int64 {
+	return l.Offset + int64(len(l.Data))
+}
+
+// ReadLog reads up to limit bytes of the log from offset, the log is followed by reading from LogSegment.Next.
+// Negative offset is counted from the end of the log, e.g. -4096 reads the tail.
+func (a *AdminSession) ReadLog(ctx context.Context, name string, offset, limit int64) (*LogSegment, error) {
+	var seg LogSegment
+	if err := a.exec(ctx, "GetLogSegment", oscript.M{"logName": name, "offset": offset, "length": limit}, &seg); err != nil {
+		return nil, err
+	}
+	return &seg, nil
+}
+
+// ThreadDump returns the stacks of the threads of the server.
+func (a *AdminSession) ThreadDump(ctx context.Context) (string, error) {
+	var dump string
+	if err := a.exec(ctx, "GetThreadDump", oscript.M{}, &dump); err != nil {
+		return "", err
+	}
+	return dump, nil
+}
+
+// SystemReport writes the system report of the server to w.
+func (a *AdminSession) SystemReport(ctx context.Context, w io.Writer) error {
+	c, err := a.s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Write(adminService, "GetSystemReport", a.s.auth, oscript.M{}); err != nil {
+		return err
+	}
+
+	fa := &FileAttr{}
+	if err := errIn(c.ReadFile(fa)); err != nil {
+		return err
+	}
+	return c.ReadTo(w, fa.Size)
+}
+
+// Setting is a key of the configuration of the server, e.g. {Section: "general", Key: "MaxFileSize"}.
+type Setting struct {
+	Section string `oscript:"Section"`
+	Key     string `oscript:"Key"`
+}
+
+func (s Setting) String() string {
+	return "[" + s.Section + "]" + s.Key
+}
+
+// settingValue is the value of the setting, Found is false when the key is not set.
+type settingValue struct {
+	Setting
+	Value string `oscript:"Value"`
+	Found bool   `oscript:"Found"`
+}
+
+// GetSettings reads the settings of the server, the missing keys are not in the result.
+// The values are read from the configuration of the server, not from the memory of the running server,
+// so they are the values after the restart.
+func (a *AdminSession) GetSettings(ctx context.Context, keys ...Setting) (map[Setting]string, error) {
+	var values []settingValue
+	if err := a.exec(ctx, "GetSettings", oscript.M{"keys": keys}, &values); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[Setting]string, len(values))
+	for _, v := range values {
+		if v.Found {
+			settings[v.Setting] = v.Value
+		}
+	}
+	return settings, nil
+}
+
+// SettingDrift is the setting of the server which differs from the expected value.
+type SettingDrift struct {
+	Setting
+	Expected string
+	Actual   string
+	Missing  bool // the key is not set on the server
+}
+
+// CheckSettings compares the settings of the server with the expected values and returns the differing ones
+// in the order of the keys, e.g. to verify the configuration after upgrade.
+func (a *AdminSession) CheckSettings(ctx context.Context, expected map[Setting]string) ([]SettingDrift, error) {
+	keys := make([]Setting, 0, len(expected))
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	actual, err := a.GetSettings(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []SettingDrift
+	for _, k := range keys {
+		v, ok := actual[k]
+		if !ok || v != expected[k] {
+			drift = append(drift, SettingDrift{Setting: k, Expected: expected[k], Actual: v, Missing: !ok})
+		}
+	}
+	return drift, nil
+}
+
+// InvalidateCache drops the cache of the server, e.g. after changing settings or the definitions of the objects.
+func (a *AdminSession) InvalidateCache(ctx context.Context, cache string) error {
+	return a.exec(ctx, "InvalidateCache", oscript.M{"cacheName": cache}, nil)
+}
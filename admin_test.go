@@ -0,0 +1,85 @@
+package ot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminSession(t *testing.T) {
+	t.Parallel()
+
+	report := "<report/>"
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "AdminService", req["ServiceName"])
+		switch req["ServiceMethod"] {
+		case "ListLogs":
+			w.WriteString("A<1,?,'_Status'=0,'Results'={'thread1.log','connect.log'}>")
+		case "GetLogSegment":
+			assert.Equal(t, map[string]interface{}{"logName": "thread1.log", "offset": int64(-5), "length": int64(100)}, req["Arguments"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'LogName'='thread1.log','Offset'=10,'Data'='tail\n','LogSize'=15>>")
+		case "GetThreadDump":
+			w.WriteString("A<1,?,'_Status'=0,'Results'='thread 1'>")
+		case "GetSystemReport":
+			fmt.Fprintf(w, "A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=%d>,'_Status'=0>", len(report))
+			w.WriteString(report)
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	admin := s.Admin()
+	logs, err := admin.ListLogs(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, []string{"thread1.log", "connect.log"}, logs)
+
+	seg, err := admin.ReadLog(context.Background(), "thread1.log", -5, 100)
+	require.Nil(t, err)
+	assert.Equal(t, "tail\n", seg.Data)
+	assert.Equal(t, int64(15), seg.Next())
+
+	dump, err := admin.ThreadDump(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "thread 1", dump)
+
+	var buf bytes.Buffer
+	require.Nil(t, admin.SystemReport(context.Background(), &buf))
+	assert.Equal(t, report, buf.String())
+}
+
+func TestAdminSession_CheckSettings(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "GetSettings":
+			assert.Len(t, req["Arguments"].(map[string]interface{})["keys"], 3)
+			w.WriteString("A<1,?,'_Status'=0,'Results'={" +
+				"A<1,?,'Section'='general','Key'='MaxFileSize','Value'='100','Found'=true>," +
+				"A<1,?,'Section'='general','Key'='Timeout','Value'='30','Found'=true>," +
+				"A<1,?,'Section'='search','Key'='Slice','Value'='','Found'=false>}>")
+		case "InvalidateCache":
+			assert.Equal(t, map[string]interface{}{"cacheName": "settings"}, req["Arguments"])
+			w.WriteString("A<1,?,'_Status'=0>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	drift, err := s.Admin().CheckSettings(context.Background(), map[Setting]string{
+		{Section: "general", Key: "MaxFileSize"}: "100",
+		{Section: "general", Key: "Timeout"}:     "60",
+		{Section: "search", Key: "Slice"}:        "all",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []SettingDrift{
+		{Setting: Setting{Section: "general", Key: "Timeout"}, Expected: "60", Actual: "30"},
+		{Setting: Setting{Section: "search", Key: "Slice"}, Expected: "all", Missing: true},
+	}, drift)
+
+	require.Nil(t, s.Admin().InvalidateCache(context.Background(), "settings"))
+}
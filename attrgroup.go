@@ -0,0 +1,199 @@
+package ot
+
+import (
+	"time"
+)
+
+// Types of the attribute groups of the metadata. The categories have the type GroupCategory,
+// other groups are the system groups of the server.
+const (
+	GroupCategory = "Category"
+	GroupExternal = "ExternalAtt"
+	GroupEmail    = "OTEmailProperties"
+)
+
+// IsCategory reports whether the attribute group is a category and not a system group, e.g. GroupExternal.
+func (c Category) IsCategory() bool {
+	return c.Type == GroupCategory
+}
+
+// OnlyCategories returns the categories of the metadata without the system groups, the values are shared with m.
+func (m Metadata) OnlyCategories() []Category {
+	return m.filter(true)
+}
+
+// SystemGroups returns the system groups of the metadata, the values are shared with m.
+func (m Metadata) SystemGroups() []Category {
+	return m.filter(false)
+}
+
+func (m Metadata) filter(category bool) []Category {
+	var groups []Category
+	for _, c := range m.Categories {
+		if c.IsCategory() == category {
+			groups = append(groups, c)
+		}
+	}
+	return groups
+}
+
+// Group finds attribute group by type, e.g. GroupExternal.
+func (m Metadata) Group(typ string) *Category {
+	for i, c := range m.Categories {
+		if c.Type == typ {
+			return &m.Categories[i]
+		}
+	}
+
+	return nil
+}
+
+// group returns attribute group by type, the group is added when it is not found.
+func (m *Metadata) group(typ string) *Category {
+	if c := m.Group(typ); c != nil {
+		return c
+	}
+
+	m.Categories = append(m.Categories, Category{Key: typ, Type: typ})
+	return &m.Categories[len(m.Categories)-1]
+}
+
+// ExternalAttributes is the group GroupExternal, it describes the item in the external system.
+type ExternalAttributes struct {
+	CreateDate   time.Time
+	ModifyDate   time.Time
+	Identity     string // account of the item in the external system, e.g. johndoe@opentext.com
+	IdentityType string // type of the account, e.g. email
+	Source       string // name of the external system, e.g. exchange_mailbox
+}
+
+// External returns the group GroupExternal, false is returned when the metadata has not the group.
+func (m Metadata) External() (ExternalAttributes, bool) {
+	c := m.Group(GroupExternal)
+	if c == nil {
+		return ExternalAttributes{}, false
+	}
+
+	return ExternalAttributes{
+		CreateDate:   c.keyTime("ExternalCreateDate"),
+		ModifyDate:   c.keyTime("ExternalModifyDate"),
+		Identity:     c.keyString("ExternalIdentity"),
+		IdentityType: c.keyString("ExternalIdentityType"),
+		Source:       c.keyString("ExternalSource"),
+	}, true
+}
+
+// SetExternal sets the group GroupExternal, the group is added when the metadata has not it.
+func (m *Metadata) SetExternal(e ExternalAttributes) {
+	c := m.group(GroupExternal)
+	c.setKey("ExternalCreateDate", TimeType, timeValues(e.CreateDate))
+	c.setKey("ExternalModifyDate", TimeType, timeValues(e.ModifyDate))
+	c.setKey("ExternalIdentity", StringType, []interface{}{e.Identity})
+	c.setKey("ExternalIdentityType", StringType, []interface{}{e.IdentityType})
+	c.setKey("ExternalSource", StringType, []interface{}{e.Source})
+}
+
+// EmailProperties is the group GroupEmail, the properties of the email message.
+type EmailProperties struct {
+	Subject      string
+	From         string
+	To           []string
+	CC           []string
+	SentDate     time.Time
+	ReceivedDate time.Time
+}
+
+// Email returns the group GroupEmail, false is returned when the metadata has not the group.
+func (m Metadata) Email() (EmailProperties, bool) {
+	c := m.Group(GroupEmail)
+	if c == nil {
+		return EmailProperties{}, false
+	}
+
+	return EmailProperties{
+		Subject:      c.keyString("OTEmailSubject"),
+		From:         c.keyString("OTEmailFrom"),
+		To:           c.keyStrings("OTEmailTo"),
+		CC:           c.keyStrings("OTEmailCC"),
+		SentDate:     c.keyTime("OTEmailSentDate"),
+		ReceivedDate: c.keyTime("OTEmailReceivedDate"),
+	}, true
+}
+
+// SetEmail sets the group GroupEmail, the group is added when the metadata has not it.
+func (m *Metadata) SetEmail(p EmailProperties) {
+	c := m.group(GroupEmail)
+	c.setKey("OTEmailSubject", StringType, []interface{}{p.Subject})
+	c.setKey("OTEmailFrom", StringType, []interface{}{p.From})
+	c.setKey("OTEmailTo", StringType, stringValues(p.To))
+	c.setKey("OTEmailCC", StringType, stringValues(p.CC))
+	c.setKey("OTEmailSentDate", TimeType, timeValues(p.SentDate))
+	c.setKey("OTEmailReceivedDate", TimeType, timeValues(p.ReceivedDate))
+}
+
+// keyValues returns values of the attribute by key, the attributes of the system groups have not the regions.
+func (c *Category) keyValues(key string) []interface{} {
+	for _, v := range c.Data {
+		if v.Key == key {
+			return v.Value
+		}
+	}
+	return nil
+}
+
+func (c *Category) keyString(key string) string {
+	if values := c.keyValues(key); len(values) > 0 {
+		s, _ := values[0].(string)
+		return s
+	}
+	return ""
+}
+
+func (c *Category) keyStrings(key string) []string {
+	var s []string
+	for _, v := range c.keyValues(key) {
+		if v, ok := v.(string); ok && v != "" {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+func (c *Category) keyTime(key string) time.Time {
+	if values := c.keyValues(key); len(values) > 0 {
+		t, _ := values[0].(time.Time)
+		return t
+	}
+	return time.Time{}
+}
+
+// setKey sets values of the attribute by key, the attribute is added when it is not found.
+func (c *Category) setKey(key string, t TypeValue, values []interface{}) {
+	for i := range c.Data {
+		if c.Data[i].Key == key {
+			c.Data[i].Value = values // full slice is changed, the copies of the category are not affected
+			return
+		}
+	}
+
+	c.Data = append(c.Data, Value{Description: key, Key: key, Value: values, Type: t})
+}
+
+func stringValues(s []string) []interface{} {
+	if len(s) == 0 {
+		return []interface{}{nil}
+	}
+
+	values := make([]interface{}, len(s))
+	for i, v := range s {
+		values[i] = v
+	}
+	return values
+}
+
+func timeValues(t time.Time) []interface{} {
+	if t.IsZero() {
+		return []interface{}{nil}
+	}
+	return []interface{}{t}
+}
@@ -0,0 +1,67 @@
+package ot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadata_Groups(t *testing.T) {
+	t.Parallel()
+
+	var m Metadata
+	for i := range testNode.Metadata.Categories {
+		m.Categories = append(m.Categories, *testNode.Metadata.Categories[i].Copy())
+	}
+	for _, c := range m.OnlyCategories() {
+		assert.True(t, c.IsCategory())
+	}
+	require.Len(t, m.SystemGroups(), 1)
+	assert.Equal(t, GroupExternal, m.SystemGroups()[0].Type)
+	assert.Equal(t, len(m.Categories)-1, len(m.OnlyCategories()))
+
+	ext, ok := m.External()
+	require.True(t, ok)
+	assert.Equal(t, ExternalAttributes{}, ext)
+
+	_, ok = m.Email()
+	assert.False(t, ok)
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.SetExternal(ExternalAttributes{CreateDate: created, Identity: "jdoe@example.com", IdentityType: "email", Source: "exchange_mailbox"})
+	ext, _ = m.External()
+	assert.Equal(t, ExternalAttributes{CreateDate: created, Identity: "jdoe@example.com", IdentityType: "email", Source: "exchange_mailbox"}, ext)
+	assert.Len(t, m.Group(GroupExternal).Data, 5)
+
+	orig, _ := testNode.Metadata.External()
+	assert.Equal(t, ExternalAttributes{}, orig, "the values of the node are not changed")
+}
+
+func TestMetadata_SetEmail(t *testing.T) {
+	t.Parallel()
+
+	sent := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	props := EmailProperties{
+		Subject:  "report",
+		From:     "jdoe@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+		SentDate: sent,
+	}
+
+	var m Metadata
+	m.SetEmail(props)
+	require.Len(t, m.Categories, 1)
+	assert.False(t, m.Categories[0].IsCategory())
+
+	b, err := oscript.Marshal(m)
+	require.Nil(t, err)
+
+	var got Metadata
+	require.Nil(t, oscript.Unmarshal(b, &got))
+	email, ok := got.Email()
+	require.True(t, ok)
+	assert.Equal(t, props, email)
+}
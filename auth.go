@@ -56,3 +56,24 @@ func (s *Session) GetSessionExpiration(ctx context.Context) (time.Time, error) {
 	}
 	return t, nil
 }
+
+// Token returns authentication token of the session to store it externally.
+// Returns empty string when the session authenticates by username and password, use GetToken to get token.
+func (s *Session) Token() string {
+	if a, ok := s.auth.(*auth); ok {
+		return a.token
+	}
+	return ""
+}
+
+// Valid reports whether the session is not expired.
+func (s *Session) Valid(ctx context.Context) (bool, error) {
+	t, err := s.GetSessionExpiration(ctx)
+	if err != nil {
+		if err == ErrTokenExpire {
+			return false, nil
+		}
+		return false, err
+	}
+	return time.Now().Before(t), nil
+}
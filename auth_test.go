@@ -0,0 +1,42 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Token(t *testing.T) {
+	t.Parallel()
+
+	endp := NewEndpoint("127.0.0.1")
+	assert.Equal(t, "", endp.User("u", "p").Token())
+	assert.Equal(t, "token", endp.SessionFromToken("token").Token())
+	assert.Equal(t, "'_Cookie'='token'", endp.SessionFromToken("token").auth.String())
+}
+
+func TestSession_Valid(t *testing.T) {
+	t.Parallel()
+
+	for i, tt := range []struct {
+		resp  string
+		valid bool
+	}{
+		{resp: "A<1,?,'_Status'=0,'Results'=D/2200/1/1:0:0:0>", valid: true},
+		{resp: "A<1,?,'_Status'=0,'Results'=D/2000/1/1:0:0:0>", valid: false},
+		{resp: "A<1,?,'_Status'=-2147482642,'_StatusMessage'='expired'>", valid: false},
+	} {
+		valid, err := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+			assert.Equal(t, "GetSessionExpirationDate", req["ServiceMethod"])
+			w.WriteString(tt.resp)
+			assert.Nil(t, w.Flush())
+		}).SessionFromToken("token").Valid(context.Background())
+		require.Nil(t, err, fmt.Sprintf("#%d", i))
+		assert.Equal(t, tt.valid, valid, fmt.Sprintf("#%d", i))
+	}
+}
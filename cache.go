@@ -1,6 +1,14 @@
 package ot
 
-import "sync"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
 
 type cacheCategory struct {
 	cat sync.Map
@@ -24,5 +32,81 @@ func (c *cacheCategory) Find(name string) *Category {
 	return v.(*Category).Copy()
 }
 
+// Stale reports whether the cached category with the name of cat is older than cat, e.g. the category
+// of the node got from the server has been upgraded after the cache was saved. The missing category is stale.
+func (c *cacheCategory) Stale(cat Category) bool {
+	cached := c.Find(cat.DisplayName)
+	if cached == nil {
+		return true
+	}
+
+	id, version := cat.IDVersion()
+	cachedID, cachedVersion := cached.IDVersion()
+	return id != cachedID || version > cachedVersion
+}
+
+// cacheFormat is the version of the format of the saved cache, the cache of other format is not loaded.
+const cacheFormat = 1
+
+type savedCache struct {
+	Format     int             `json:"format"`
+	Saved      time.Time       `json:"saved"`
+	Categories []savedCategory `json:"categories"`
+}
+
+type savedCategory struct {
+	Key  string `json:"key"`
+	Data string `json:"data"` // oscript encoding keeps the types of the values
+}
+
+// Save writes the cached categories to w as JSON, it allows the short-lived processes to load the templates
+// instead of getting them from the server on every run.
+func (c *cacheCategory) Save(w io.Writer) error {
+	saved := savedCache{Format: cacheFormat, Saved: time.Now().UTC(), Categories: []savedCategory{}}
+
+	var err error
+	c.cat.Range(func(_, v interface{}) bool {
+		cat := v.(*Category)
+
+		var b []byte
+		if b, err = oscript.Marshal(cat); err != nil {
+			return false
+		}
+		saved.Categories = append(saved.Categories, savedCategory{Key: cat.Key, Data: string(b)})
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("ot: save category cache: %w", err)
+	}
+	return json.NewEncoder(w).Encode(saved)
+}
+
+// Load stores the categories saved by Save, the cache older than maxAge or of other format is ignored
+// and false is returned, zero maxAge means no expiration. The loaded categories replace the cached ones
+// of the same name, use Stale to check them against the categories of the nodes.
+func (c *cacheCategory) Load(r io.Reader, maxAge time.Duration) (bool, error) {
+	var saved savedCache
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return false, fmt.Errorf("ot: load category cache: %w", err)
+	}
+
+	if saved.Format != cacheFormat || maxAge > 0 && time.Since(saved.Saved) > maxAge {
+		return false, nil
+	}
+
+	cats := make([]*Category, len(saved.Categories))
+	for i, sc := range saved.Categories {
+		cats[i] = &Category{}
+		if err := oscript.Unmarshal([]byte(sc.Data), cats[i]); err != nil {
+			return false, fmt.Errorf("ot: load category cache %q: %w", sc.Key, err)
+		}
+	}
+
+	for _, cat := range cats {
+		c.Store(cat)
+	}
+	return true, nil
+}
+
 // CacheCategory is a cache of the categories.
 var CacheCategory = &cacheCategory{}
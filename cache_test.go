@@ -1,8 +1,11 @@
 package ot
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,3 +46,42 @@ func TestCacheCategory_Find(t *testing.T) {
 		assert.Equal(t, tt.exp, cat, fmt.Sprintf("#%d", i))
 	}
 }
+
+func TestCacheCategory_SaveLoad(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	cat := &Category{DisplayName: "Invoice", Key: "100.3", Type: "Category", Data: []Value{
+		{Description: "Number", Key: "100.3.2", Type: StringType, Value: []interface{}{"A-1"}},
+		{Description: "Due", Key: "100.3.3", Type: TimeType, Value: []interface{}{due}},
+	}}
+
+	cache := &cacheCategory{}
+	cache.Store(cat)
+
+	var buf bytes.Buffer
+	require.Nil(t, cache.Save(&buf))
+	saved := buf.String()
+
+	loaded := &cacheCategory{}
+	ok, err := loaded.Load(strings.NewReader(saved), time.Hour)
+	require.Nil(t, err)
+	require.True(t, ok)
+	assert.Equal(t, cat, loaded.Find("Invoice"))
+
+	assert.False(t, loaded.Stale(Category{DisplayName: "Invoice", Key: "100.3"}))
+	assert.True(t, loaded.Stale(Category{DisplayName: "Invoice", Key: "100.4"}), "upgraded category")
+	assert.True(t, loaded.Stale(Category{DisplayName: "Invoice", Key: "101.1"}), "other category with the same name")
+	assert.True(t, loaded.Stale(Category{DisplayName: "Other", Key: "102.1"}))
+
+	expired := &cacheCategory{}
+	old := saved[:strings.Index(saved, `"saved":"`)+9] + "2001-01-01T00:00:00Z" + saved[strings.Index(saved, `","categories"`):]
+	ok, err = expired.Load(strings.NewReader(old), time.Hour)
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, expired.Find("Invoice"))
+
+	ok, err = expired.Load(strings.NewReader(strings.Replace(saved, `"format":1`, `"format":0`, 1)), 0)
+	require.Nil(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,182 @@
+package ot
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// CallCacheOptions configures CallCache.
+type CallCacheOptions struct {
+	Methods    []string      // cached methods as "Service.Method", e.g. "DocumentManagement.GetNode"
+	TTL        time.Duration // zero means no expiration
+	MaxEntries int           // zero means no limit, the entries are evicted only by TTL
+}
+
+// CallCache is a LRU cache of the results of the read methods keyed by the authentication of the session,
+// the method and the canonical encoding of the arguments, see oscript.MarshalCanonical.
+// The cache may be shared by the sessions of different users, the users never get the results of each other.
+// The changes are visible after expiration of the entries, Purge drops all entries.
+type CallCache struct {
+	methods map[string]bool
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[[sha256.Size]byte]*list.Element
+}
+
+type callEntry struct {
+	key     [sha256.Size]byte
+	raw     oscript.RawMessage
+	expires time.Time
+}
+
+// NewCallCache creates cache of the results of the methods.
+func NewCallCache(opts CallCacheOptions) *CallCache {
+	methods := make(map[string]bool, len(opts.Methods))
+	for _, m := range opts.Methods {
+		methods[m] = true
+	}
+
+	return &CallCache{
+		methods: methods,
+		ttl:     opts.TTL,
+		size:    opts.MaxEntries,
+		now:     time.Now,
+		ll:      list.New(),
+		items:   make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// Len returns the number of the entries.
+func (c *CallCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Purge removes all entries.
+func (c *CallCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[[sha256.Size]byte]*list.Element)
+}
+
+// key returns key of the call, it reports false when the method is not cached or the arguments
+// can not be encoded canonically. The key is hashed, the credentials of the session are not kept in the cache.
+func (c *CallCache) key(auth, service, method string, args interface{}) ([sha256.Size]byte, bool) {
+	if c == nil || !c.methods[service+"."+method] {
+		return [sha256.Size]byte{}, false
+	}
+
+	b, err := oscript.MarshalCanonical(args)
+	if err != nil {
+		return [sha256.Size]byte{}, false
+	}
+
+	h := sha256.New()
+	for _, s := range []string{auth, service, method} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	h.Write(b)
+
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key, true
+}
+
+func (c *CallCache) get(key [sha256.Size]byte) (oscript.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*callEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		c.ll.Remove(e)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(e)
+	return entry.raw, true
+}
+
+func (c *CallCache) add(key [sha256.Size]byte, raw oscript.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &callEntry{key: key, raw: raw, expires: c.now().Add(c.ttl)}
+	if e, ok := c.items[key]; ok {
+		e.Value = entry
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(entry)
+	if c.size > 0 && c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*callEntry).key)
+	}
+}
+
+// WithCallCache returns session which gets the results of the cached methods from the cache.
+// The results are decoded from the cached response on every call, so the returned values are not shared.
+// Call with *Outputs as reply bypasses the cache, the named outputs are not cached.
+func (s *Session) WithCallCache(c *CallCache) *Session {
+	cs := s.clone()
+	cs.calls = c
+	return cs
+}
+
+// exec calls the method and decodes Results into res, the results of the cached methods are got from the call cache.
+func (s *Session) exec(ctx context.Context, service, method string, args, res interface{}) error {
+	key, cached := s.calls.key(s.auth.String(), service, method, args)
+	if cached {
+		if raw, ok := s.calls.get(key); ok {
+			return decodeRaw(raw, res)
+		}
+	}
+
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if !cached {
+		return errIn(c.Exec(service, method, s.auth, args, res))
+	}
+
+	var raw oscript.RawMessage
+	if err := errIn(c.Exec(service, method, s.auth, args, &raw)); err != nil {
+		return err
+	}
+
+	if err := decodeRaw(raw, res); err != nil {
+		return err
+	}
+	s.calls.add(key, raw)
+	return nil
+}
+
+func decodeRaw(raw oscript.RawMessage, res interface{}) error {
+	if res == nil {
+		return nil
+	}
+	return oscript.Unmarshal(raw, res)
+}
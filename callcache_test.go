@@ -0,0 +1,109 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCallCache(CallCacheOptions{Methods: []string{"S.Get"}, TTL: time.Minute, MaxEntries: 2})
+	c.now = func() time.Time { return now }
+
+	_, ok := c.key("user", "S", "Set", oscript.M{})
+	assert.False(t, ok, "method is not cached")
+
+	k1, ok := c.key("user", "S", "Get", oscript.M{"a": 1, "b": []interface{}{oscript.M{"y": 1, "x": 2}}})
+	require.True(t, ok)
+	k2, _ := c.key("user", "S", "Get", oscript.M{"b": []interface{}{oscript.M{"x": 2, "y": 1}}, "a": 1})
+	assert.Equal(t, k1, k2)
+
+	other, _ := c.key("admin", "S", "Get", oscript.M{"a": 1, "b": []interface{}{oscript.M{"y": 1, "x": 2}}})
+	assert.NotEqual(t, k1, other)
+
+	k3, _ := c.key("user", "S", "Get", oscript.M{"a": 3})
+	c.add(k1, oscript.RawMessage("1"))
+	c.add(other, oscript.RawMessage("2"))
+	c.get(k1)
+	c.add(k3, oscript.RawMessage("3")) // evicts other
+
+	_, ok = c.get(other)
+	assert.False(t, ok)
+
+	raw, ok := c.get(k1)
+	require.True(t, ok)
+	assert.Equal(t, "1", string(raw))
+
+	now = now.Add(time.Minute)
+	_, ok = c.get(k3)
+	assert.False(t, ok)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+
+	var nilCache *CallCache
+	_, ok = nilCache.key("user", "S", "Get", oscript.M{})
+	assert.False(t, ok)
+
+	unbounded := NewCallCache(CallCacheOptions{Methods: []string{"S.Get"}})
+	for i := 0; i < 3; i++ {
+		k, _ := unbounded.key("user", "S", "Get", oscript.M{"a": i})
+		unbounded.add(k, oscript.RawMessage("1"))
+	}
+	assert.Equal(t, 3, unbounded.Len(), "zero MaxEntries is not limited")
+}
+
+func TestSession_WithCallCache(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		atomic.AddInt32(&calls, 1)
+		switch req["ServiceMethod"] {
+		case "GetNode":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='gopher'>>")
+		default:
+			w.WriteString("A<1,?,'_Status'=0,'Results'='done'>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	cache := NewCallCache(CallCacheOptions{Methods: []string{"DocumentManagement.GetNode", "Service.Get"}, MaxEntries: 10})
+	cs := s.WithCallCache(cache)
+	for i := 0; i < 2; i++ {
+		node, err := cs.GetNode(context.Background(), 1)
+		require.Nil(t, err)
+		assert.Equal(t, "gopher", node.Name)
+
+		node.Name = "changed" // the cached response is decoded again
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err := cs.As(TokenAuth("token")).GetNode(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "the results of other user are not shared")
+
+	for i := 0; i < 2; i++ {
+		var reply string
+		require.Nil(t, cs.Call(context.Background(), "Service.Get", oscript.M{"ID": 1}, &reply))
+		assert.Equal(t, "done", reply)
+		require.Nil(t, cs.Call(context.Background(), "Service.Set", oscript.M{"ID": 1}, nil))
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+	assert.Equal(t, 3, cache.Len())
+
+	var reply string
+	require.Nil(t, cs.Call(context.Background(), "Service.Get", oscript.M{"ID": 1}, &Outputs{Results: &reply}))
+	assert.Equal(t, "done", reply)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls), "the call with outputs is not cached")
+}
@@ -0,0 +1,70 @@
+package ot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/itcomusic/ot/internal/client"
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// CaptureRecord is the messages of the call as they are sent to and received from the server,
+// except the framing of the protocol and the content of the files.
+type CaptureRecord struct {
+	Service  string    // service and method, e.g. "DocumentManagement.UpdateNode"
+	Start    time.Time // start of the call
+	Request  []byte    // the request with the secrets replaced by "***", see WithCapture
+	Response []byte    // nil when the response has not been read or can not be redacted
+}
+
+// CaptureSink is the interface implemented by types that archive the messages of the calls.
+// Capture is called concurrently after every call, the record must not be retained after it returns.
+type CaptureSink interface {
+	Capture(rec *CaptureRecord)
+}
+
+// redacted replaces the secrets in the captured messages.
+const redacted = "***"
+
+// captureFunc returns the function which redacts the secrets of the captured messages.
+func (s *Session) captureFunc() client.CaptureFunc {
+	return func(c *client.Capture) {
+		s.ep.capture.Capture(&CaptureRecord{
+			Service:  c.Service,
+			Start:    c.Start,
+			Request:  redactMessage(c.Request, false),
+			Response: redactMessage(c.Response, strings.HasPrefix(c.Service, authService+".")),
+		})
+	}
+}
+
+// secretKeys are the keys of the credentials in the requests.
+var secretKeys = map[string]bool{
+	"_UserPassword":          true,
+	"_UserPasswordEncrypted": true,
+	"_Cookie":                true,
+	"userPassword":           true,
+	"oldPassword":            true,
+	"newPassword":            true,
+}
+
+// redactMessage returns the message with the values of secretKeys replaced by redacted, Results is replaced as well
+// when results is true, e.g. the tokens of the authentication service. Only the bytes of the secret values are
+// replaced, the other bytes are kept as sent. nil is returned when the message can not be scanned,
+// so the secrets are never archived.
+func redactMessage(b []byte, results bool) []byte {
+	if b == nil {
+		return nil
+	}
+
+	out, err := oscript.ReplaceValues(b, func(depth int, key string) []byte {
+		if secretKeys[key] || results && depth == 0 && key == "Results" {
+			return []byte("'" + redacted + "'")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package ot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type captureRecorder struct {
+	mu      sync.Mutex
+	records []CaptureRecord
+}
+
+func (c *captureRecorder) Capture(rec *CaptureRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, *rec)
+}
+
+func TestEndpoint_Capture(t *testing.T) {
+	t.Parallel()
+
+	const resp = "A<1,?,'_Status'=0,'Results'='hello'>"
+	endp := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "GetVersionContents":
+			fmt.Fprintf(w, "A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=%d>,'_Status'=0>content", len("content"))
+		default:
+			w.WriteString(resp)
+		}
+		assert.Nil(t, w.Flush())
+	})
+	rec := &captureRecorder{}
+	endp.capture = rec
+
+	require.Nil(t, endp.User("gopher", "secret").Call(context.Background(), "Service.Method", oscript.M{"ID": 1}, nil))
+	require.Nil(t, endp.Token("token").Call(context.Background(), "Service.Method", nil, nil))
+
+	var b bytes.Buffer
+	_, err := endp.User("gopher", "secret").ReadFile(context.Background(), 1, 0, &b)
+	require.Nil(t, err)
+
+	require.Len(t, rec.records, 3)
+	assert.Equal(t, "Service.Method", rec.records[0].Service)
+	var req map[string]interface{}
+	require.Nil(t, oscript.Unmarshal(rec.records[0].Request, &req))
+	assert.Equal(t, map[string]interface{}{
+		"_ApiName":      "InvokeService",
+		"ServiceName":   "Service",
+		"ServiceMethod": "Method",
+		"_UserName":     "gopher",
+		"_UserPassword": "***",
+		"Arguments":     map[string]interface{}{"ID": int64(1)},
+	}, req)
+	assert.Equal(t, resp, string(rec.records[0].Response))
+	assert.False(t, rec.records[0].Start.IsZero())
+
+	assert.Contains(t, string(rec.records[1].Request), "'_Cookie'='***'")
+	assert.NotContains(t, string(rec.records[1].Request), "token")
+
+	assert.Equal(t, "DocumentManagement.GetVersionContents", rec.records[2].Service)
+	assert.True(t, strings.HasSuffix(string(rec.records[2].Response), "'_Status'=0>"), "the content is not captured")
+}
+
+func TestEndpoint_CaptureGetToken(t *testing.T) {
+	t.Parallel()
+
+	endp := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=0,'Results'='token'>")
+		assert.Nil(t, w.Flush())
+	})
+	rec := &captureRecorder{}
+	endp.capture = rec
+
+	token, err := endp.User("admin", "secret").GetToken(context.Background(), "gopher", "password")
+	require.Nil(t, err)
+	assert.Equal(t, "token", token)
+
+	require.Len(t, rec.records, 1)
+	assert.Equal(t, "Authentication.AuthenticateUser", rec.records[0].Service)
+	for _, b := range [][]byte{rec.records[0].Request, rec.records[0].Response} {
+		assert.NotContains(t, string(b), "secret")
+		assert.NotContains(t, string(b), "password")
+		assert.NotContains(t, string(b), "token")
+	}
+	assert.Contains(t, string(rec.records[0].Request), "'userName'='gopher'")
+	assert.Contains(t, string(rec.records[0].Request), "'userPassword'='***'")
+	assert.Contains(t, string(rec.records[0].Response), "'Results'='***'")
+}
+
+func TestRedactMessage(t *testing.T) {
+	t.Parallel()
+
+	const msg = "A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1>>"
+	assert.Equal(t, msg, string(redactMessage([]byte(msg), false)), "the message without the secrets is kept")
+	assert.Equal(t, "A<1,?,'Results'=A<1,?,'Items'={A<1,?,'newPassword'='***'>}>>",
+		string(redactMessage([]byte("A<1,?,'Results'=A<1,?,'Items'={A<1,?,'newPassword'='p'>}>>"), false)))
+	assert.Nil(t, redactMessage([]byte("A<1,?,'_Cookie'='tok"), false), "the message which can not be redacted is dropped")
+
+	assert.Equal(t, "A<1,?,'_UserName'='u','_UserPassword'='***','Arguments'=A<1,?,'ID'=L123,'f'=G1.50,'tokenType'='x'>>",
+		string(redactMessage([]byte("A<1,?,'_UserName'='u','_UserPassword'='p','Arguments'=A<1,?,'ID'=L123,'f'=G1.50,'tokenType'='x'>>"), false)),
+		"the other bytes are kept as sent")
+}
@@ -21,6 +21,7 @@ type Value struct {
 	Description string        `oscript:"Description"`
 	Key         string        `oscript:"Key"`
 	Value       []interface{} `oscript:"Values"`
+	MaxValues   int           `oscript:"MaxValues,omitempty"` // set in the template, more than one is multi-valued attribute
 
 	Type TypeValue `oscript:"_SDOName"`
 }
@@ -32,6 +33,7 @@ type Category struct {
 	Type        string  `oscript:"Type"`
 	Data        []Value `oscript:"Values"`
 
+	tracked *tracking       // state of Track, shared by the values of the category
 	sdoName oscript.SDOName `oscript:"DocMan.AttributeGroup,public"`
 }
 
@@ -48,6 +50,7 @@ func (c *Category) Copy() *Category {
 		Key:         c.Key,
 		Type:        c.Type,
 		Data:        values,
+		tracked:     c.tracked.copy(),
 	}
 }
 
@@ -72,9 +75,13 @@ func (c *Category) Upgrade(new Category) error {
 	for _, o := range c.Data {
 		for i, n := range new.Data {
 			if o.Description == n.Description {
-				// TODO: converts auto int, time, bool -> string
 				if o.Type != n.Type {
-					return fmt.Errorf("invalid type attribute \"%s\" \"%s\"", n.Description, n.Type)
+					// values are converted to the new type, e.g. int, time, bool -> string
+					cv, err := o.Convert(n.Type)
+					if err != nil {
+						return fmt.Errorf("invalid type attribute \"%s\" \"%s\": %w", n.Description, n.Type, err)
+					}
+					o = cv
 				}
 
 				// new value ref to old value, that is why not allocate new slice.
@@ -163,12 +170,17 @@ func (t TypeValue) MarshalOscript() ([]byte, error) {
 	}
 }
 
-// Set sets values.
+// Set sets values. Set of the tracked category is synchronized with Track and Changes.
 func (c *Category) Set(v ...NameValueType) error {
 	if c == nil {
 		return errCategory
 	}
 
+	if c.tracked != nil {
+		c.tracked.mu.Lock()
+		defer c.tracked.mu.Unlock()
+	}
+
 	indAttr := make([]int, 0, len(v))
 	// TODO: need optimization, using maps to find value
 loop:
@@ -249,6 +261,19 @@ func (c *Category) attr(desc string, t TypeValue) ([]interface{}, error) {
 	return nil, fmt.Errorf("not found attribute \"%s\"", desc)
 }
 
+// Values returns all values of the attribute, the slice is copied.
+func (c *Category) Values(name string) ([]interface{}, error) {
+	if c == nil {
+		return nil, errCategory
+	}
+
+	value, err := c.attr(name, NilType)
+	if err != nil {
+		return nil, err
+	}
+	return append([]interface{}(nil), value...), nil
+}
+
 // String returns string value.
 func (c *Category) String(name string, v *string) error {
 	if c == nil {
@@ -268,6 +268,21 @@ func TestCategory_GetTime(t *testing.T) {
 	assert.Equal(t, time.Time{}, got)
 }
 
+func TestCategory_Values(t *testing.T) {
+	t.Parallel()
+
+	cat := &Category{Data: []Value{{Description: "tags", Value: []interface{}{"a", "b"}, Type: StringType}}}
+	values, err := cat.Values("tags")
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, values)
+
+	values[0] = "c"
+	assert.Equal(t, "a", cat.Data[0].Value[0])
+
+	_, err = cat.Values("unknown")
+	assert.EqualError(t, err, `not found attribute "unknown"`)
+}
+
 func TestCategory_Copy(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,79 @@
+package ot
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// CheckOut reserves the node by the user of the session and writes the latest version to the local path.
+// The reservation is cancelled and the local file is removed when the download fails.
+func (s *Session) CheckOut(ctx context.Context, nodeID int64, localPath string) (*FileAttr, error) {
+	user, err := s.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ReserveNode(ctx, nodeID, user.ID); err != nil {
+		return nil, err
+	}
+
+	fa, err := s.download(ctx, nodeID, localPath)
+	if err != nil {
+		s.UnreserveNode(ctx, nodeID) // rollback, error of the download is more important
+		return nil, err
+	}
+	return fa, nil
+}
+
+func (s *Session) download(ctx context.Context, nodeID int64, localPath string) (*FileAttr, error) {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fa, err := s.ReadFile(ctx, nodeID, 0, f) // 0 is the latest version
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = fa.ApplyTimes(f)
+	}
+
+	if err != nil {
+		os.Remove(localPath)
+		return nil, err
+	}
+	return fa, nil
+}
+
+// CheckIn adds the local file as new version of the node with the comment and cancels the reservation.
+// The reservation is kept when the upload fails, so the check-in may be repeated. The comment is set in the created
+// version, the error of setting it is returned after the reservation is cancelled, the version is kept without the comment.
+func (s *Session) CheckIn(ctx context.Context, nodeID int64, localPath, comment string) error {
+	f, fa, err := OpenFile(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fa.NodeID = nodeID
+	v, err := s.AddVersion(ctx, fa, f)
+	if err != nil {
+		return err
+	}
+
+	var commentErr error
+	if comment != "" {
+		v.Comment = comment
+		if err := s.UpdateVersion(ctx, *v); err != nil {
+			commentErr = fmt.Errorf("ot: version %d is created without the comment: %w", v.Number, err)
+		}
+	}
+
+	if err := s.UnreserveNode(ctx, nodeID); err != nil {
+		return err
+	}
+	return commentErr
+}
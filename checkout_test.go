@@ -0,0 +1,107 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_CheckOut(t *testing.T) {
+	t.Parallel()
+
+	content := "content of the file"
+	local := filepath.Join(t.TempDir(), "file")
+	fa, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "GetAuthenticatedUser":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=7,'Name'='u'>>")
+		case "ReserveNode":
+			assert.Equal(t, int64(7), req["Arguments"].(map[string]interface{})["userID"])
+			w.WriteString("A<1,?,'_Status'=0>")
+		case "GetVersionContents":
+			b, err := ioutil.ReadFile("testdata/read-file")
+			require.Nil(t, err)
+			w.Write(b)
+			w.WriteString(content)
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).CheckOut(context.Background(), 1, local)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), fa.NodeID)
+
+	b, err := ioutil.ReadFile(local)
+	require.Nil(t, err)
+	assert.Equal(t, content, string(b))
+}
+
+func TestSession_CheckOutRollback(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+	local := filepath.Join(t.TempDir(), "file")
+	_, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		mu.Lock()
+		methods = append(methods, req["ServiceMethod"].(string))
+		mu.Unlock()
+
+		switch req["ServiceMethod"] {
+		case "GetAuthenticatedUser":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=7>>")
+		case "GetVersionContents":
+			w.WriteString("A<1,?,'_Status'=1,'_errMsg'='failed'>")
+		default:
+			w.WriteString("A<1,?,'_Status'=0>")
+		}
+		assert.Nil(t, w.Flush())
+	}).CheckOut(context.Background(), 1, local)
+	assert.EqualError(t, err, "ot: failed")
+	assert.Equal(t, []string{"GetAuthenticatedUser", "ReserveNode", "GetVersionContents", "UnreserveNode"}, methods)
+
+	_, err = os.Stat(local)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSession_CheckIn(t *testing.T) {
+	t.Parallel()
+
+	const content = "content"
+	local := filepath.Join(t.TempDir(), "file")
+	require.Nil(t, ioutil.WriteFile(local, []byte(content), 0o600))
+
+	var methods []string
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		methods = append(methods, req["ServiceMethod"].(string))
+		switch req["ServiceMethod"] {
+		case "AddVersion":
+			b := make([]byte, len(content))
+			_, err := io.ReadFull(r, b)
+			require.Nil(t, err)
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Number'=3>>")
+		case "UpdateVersion":
+			v := req["Arguments"].(map[string]interface{})["version"].(map[string]interface{})
+			assert.Equal(t, int64(3), v["Number"], "the comment is set in the created version")
+			assert.Equal(t, "comment", v["Comment"])
+			w.WriteString("A<1,?,'_Status'=1,'_errMsg'='failed'>")
+		case "UnreserveNode":
+			w.WriteString("A<1,?,'_Status'=0>")
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).CheckIn(context.Background(), 1, local, "comment")
+	assert.EqualError(t, err, "ot: version 3 is created without the comment: ot: failed")
+	assert.Equal(t, []string{"AddVersion", "UpdateVersion", "UnreserveNode"}, methods)
+}
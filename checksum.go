@@ -0,0 +1,75 @@
+package ot
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// Checksum is a hash of the content of the version computed by the storage provider.
+type Checksum struct {
+	Algorithm string `oscript:"Algorithm"` // e.g. MD5, SHA-1, SHA-256
+	Value     string `oscript:"Value"`     // hex encoded
+}
+
+// newHash returns hash of the algorithm, the case and dashes of the name are ignored.
+func (c *Checksum) newHash() (hash.Hash, error) {
+	switch strings.ReplaceAll(strings.ToUpper(c.Algorithm), "-", "") {
+	case "MD5":
+		return md5.New(), nil
+	case "SHA1":
+		return sha1.New(), nil
+	case "SHA256":
+		return sha256.New(), nil
+	case "SHA512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("ot: unsupported checksum algorithm %q", c.Algorithm)
+	}
+}
+
+// Match reports whether the content of r has the checksum, e.g. the local copy of the version
+// does not need to be downloaded again.
+func (c *Checksum) Match(r io.Reader) (bool, error) {
+	h, err := c.newHash()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(hex.EncodeToString(h.Sum(nil)), c.Value), nil
+}
+
+// GetVersionChecksum gets checksum of the version kept by the storage provider, zero number means the latest version.
+// ErrChecksumUnavailable is returned when the provider does not keep checksums.
+func (s *Session) GetVersionChecksum(ctx context.Context, id, number int64) (*Checksum, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var sum *Checksum
+	if err := errIn(c.Exec(docmanService, "GetVersionChecksum", s.auth, oscript.M{"ID": id, "versionNum": number}, &sum)); err != nil {
+		if _, ok := err.(*ServiceNotFoundError); ok {
+			return nil, ErrChecksumUnavailable
+		}
+		return nil, err
+	}
+
+	if sum == nil || sum.Value == "" {
+		return nil, ErrChecksumUnavailable
+	}
+	return sum, nil
+}
@@ -0,0 +1,59 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum_Match(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		sum   Checksum
+		match bool
+	}{
+		{sum: Checksum{Algorithm: "MD5", Value: "5d41402abc4b2a76b9719d911017c592"}, match: true},
+		{sum: Checksum{Algorithm: "sha-1", Value: "AAF4C61DDCC5E8A2DABEDE0F3B482CD9AEA9434D"}, match: true},
+		{sum: Checksum{Algorithm: "SHA256", Value: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}, match: true},
+		{sum: Checksum{Algorithm: "SHA-256", Value: "00"}, match: false},
+	} {
+		ok, err := tt.sum.Match(strings.NewReader("hello"))
+		require.Nil(t, err)
+		assert.Equal(t, tt.match, ok, tt.sum.Algorithm)
+	}
+
+	_, err := (&Checksum{Algorithm: "CRC32"}).Match(strings.NewReader("hello"))
+	assert.NotNil(t, err)
+}
+
+func TestSession_GetVersionChecksum(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["Arguments"].(map[string]interface{})["ID"] {
+		case int64(1):
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'Algorithm'='MD5','Value'='5d41402abc4b2a76b9719d911017c592'>>")
+		case int64(2):
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		default:
+			w.WriteString("A<1,?,'_Status'=903102,'_StatusMessage'='not found service'>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	sum, err := s.GetVersionChecksum(context.Background(), 1, 0)
+	require.Nil(t, err)
+	assert.Equal(t, &Checksum{Algorithm: "MD5", Value: "5d41402abc4b2a76b9719d911017c592"}, sum)
+
+	_, err = s.GetVersionChecksum(context.Background(), 2, 0)
+	assert.Equal(t, ErrChecksumUnavailable, err)
+
+	_, err = s.GetVersionChecksum(context.Background(), 3, 0)
+	assert.Equal(t, ErrChecksumUnavailable, err)
+}
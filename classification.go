@@ -0,0 +1,123 @@
+package ot
+
+import (
+	"context"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const classificationService = "Classifications"
+
+// Classification is a node of the taxonomy tree. Classifications are not records management codes.
+type Classification struct {
+	Description string `oscript:"Description"`
+	ID          int64  `oscript:"ID"`
+	Name        string `oscript:"Name"`
+	Parent      int64  `oscript:"ParentID"`
+	Selectable  bool   `oscript:"Selectable"`
+
+	Children []Classification `oscript:"-"`
+
+	sdoName oscript.SDOName `oscript:"Classifications.Classification,public"`
+}
+
+// ListClassifications returns children of the classification in the taxonomy tree.
+func (s *Session) ListClassifications(ctx context.Context, parentID int64) ([]Classification, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var cl []Classification
+	if err := errIn(c.Exec(classificationService, "ListClassifications", s.auth, oscript.M{"parentID": parentID}, &cl)); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// GetClassification gets classification.
+func (s *Session) GetClassification(ctx context.Context, id int64) (*Classification, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var cl Classification
+	if err := errIn(c.Exec(classificationService, "GetClassification", s.auth, oscript.M{"ID": id}, &cl)); err != nil {
+		return nil, err
+	}
+	return &cl, nil
+}
+
+// GetClassificationTree returns the taxonomy tree of the classification with all descendants in Children.
+func (s *Session) GetClassificationTree(ctx context.Context, id int64) (*Classification, error) {
+	root, err := s.GetClassification(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.classificationChildren(ctx, root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (s *Session) classificationChildren(ctx context.Context, parent *Classification) error {
+	children, err := s.ListClassifications(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range children {
+		if err := s.classificationChildren(ctx, &children[i]); err != nil {
+			return err
+		}
+	}
+	parent.Children = children
+	return nil
+}
+
+// GetNodeClassifications returns classifications applied to the node.
+func (s *Session) GetNodeClassifications(ctx context.Context, nodeID int64) ([]Classification, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var cl []Classification
+	if err := errIn(c.Exec(classificationService, "GetClassifications", s.auth, oscript.M{"nodeID": nodeID}, &cl)); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// ApplyClassification files the node against the classifications.
+func (s *Session) ApplyClassification(ctx context.Context, nodeID int64, ids ...int64) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := errIn(c.Exec(classificationService, "ApplyClassifications", s.auth, oscript.M{"nodeID": nodeID, "classificationIDs": ids}, nil)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveClassification removes the classifications from the node.
+func (s *Session) RemoveClassification(ctx context.Context, nodeID int64, ids ...int64) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := errIn(c.Exec(classificationService, "RemoveClassifications", s.auth, oscript.M{"nodeID": nodeID, "classificationIDs": ids}, nil)); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetClassificationTree(t *testing.T) {
+	t.Parallel()
+
+	tree, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetClassification":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='root'>>")
+		case "ListClassifications":
+			if args["parentID"] == int64(1) {
+				w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=2,'Name'='leaf','ParentID'=1>}>")
+			} else {
+				w.WriteString("A<1,?,'_Status'=0,'Results'={}>")
+			}
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).GetClassificationTree(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, &Classification{ID: 1, Name: "root", Children: []Classification{{ID: 2, Name: "leaf", Parent: 1, Children: []Classification{}}}}, tree)
+}
+
+func Test_ApplyClassification(t *testing.T) {
+	t.Parallel()
+
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "Classifications", req["ServiceName"])
+		assert.Equal(t, "ApplyClassifications", req["ServiceMethod"])
+		assert.Equal(t, fmt.Sprint(map[string]interface{}{"nodeID": int64(1), "classificationIDs": []interface{}{int64(2), int64(3)}}), fmt.Sprint(req["Arguments"]))
+
+		w.WriteString("A<1,?,'_Status'=0>")
+		assert.Nil(t, w.Flush())
+	}).ApplyClassification(context.Background(), 1, 2, 3)
+	require.Nil(t, err)
+}
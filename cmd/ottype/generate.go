@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/itcomusic/ot"
+)
+
+// attr is the attribute of the category as it is generated.
+type attr struct {
+	Name   string // name of the attribute in the category
+	Field  string // name of the accessor
+	GoType string
+	Type   string // name of the ot.TypeValue constant
+	Getter string // method of ot.Category getting single value
+	Ctor   string // function creating ot.NameValueType of single value
+	Multi  bool
+}
+
+var types = map[ot.TypeValue]attr{
+	ot.StringType: {GoType: "string", Type: "StringType", Getter: "String", Ctor: "AttrString"},
+	ot.IntType:    {GoType: "int", Type: "IntType", Getter: "Int", Ctor: "AttrInt"},
+	ot.BoolType:   {GoType: "bool", Type: "BoolType", Getter: "Bool", Ctor: "AttrBool"},
+	ot.TimeType:   {GoType: "time.Time", Type: "TimeType", Getter: "Time", Ctor: "AttrTime"},
+}
+
+// generate returns the source of the typed wrapper of the category.
+func generate(pkg, name string, cat *ot.Category) ([]byte, error) {
+	data := struct {
+		Package  string
+		Name     string
+		Category *ot.Category
+		Attrs    []attr
+		Time     bool
+		Multi    bool
+	}{Package: pkg, Name: name, Category: cat}
+
+	used := map[string]bool{"Category": true}
+	for _, v := range cat.Data {
+		a, ok := types[v.Type]
+		if !ok {
+			return nil, fmt.Errorf("attribute %q has unsupported type %s", v.Description, v.Type)
+		}
+
+		a.Name = v.Description
+		a.Field = fieldName(v.Description, used)
+		a.Multi = v.MaxValues > 1
+		data.Attrs = append(data.Attrs, a)
+
+		data.Time = data.Time || v.Type == ot.TimeType
+		data.Multi = data.Multi || a.Multi
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// fieldName returns exported identifier of the attribute, the identifiers are unique within used.
+func fieldName(desc string, used map[string]bool) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range desc {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Attr" + name
+	}
+
+	unique := name
+	for i := 2; used[unique] || used["Set"+unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by ottype. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	{{if .Multi}}"fmt"{{end}}
+	{{if .Time}}"time"{{end}}
+
+	"github.com/itcomusic/ot"
+)
+
+// {{.Name}} is the category {{printf "%q" .Category.DisplayName}} ({{.Category.Key}}).
+type {{.Name}} struct {
+	cat *ot.Category
+}
+
+// New{{.Name}} wraps the category, use Metadata.Find to get the category of the node.
+func New{{.Name}}(cat *ot.Category) *{{.Name}} {
+	return &{{.Name}}{cat: cat}
+}
+
+// Category returns the wrapped category.
+func (c *{{.Name}}) Category() *ot.Category {
+	return c.cat
+}
+{{range .Attrs}}{{if .Multi}}
+// {{.Field}} returns the values of the attribute {{printf "%q" .Name}}.
+func (c *{{$.Name}}) {{.Field}}() ([]{{.GoType}}, error) {
+	values, err := c.cat.Values({{printf "%q" .Name}})
+	if err != nil {
+		return nil, err
+	}
+
+	vs := make([]{{.GoType}}, 0, len(values))
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		tv, ok := v.({{.GoType}})
+		if !ok {
+			return nil, fmt.Errorf("failed cast to {{.GoType}}")
+		}
+		vs = append(vs, tv)
+	}
+	return vs, nil
+}
+
+// Set{{.Field}} sets the values of the attribute {{printf "%q" .Name}}.
+func (c *{{$.Name}}) Set{{.Field}}(vs ...{{.GoType}}) error {
+	values := make([]interface{}, len(vs))
+	for i, v := range vs {
+		values[i] = v
+	}
+	return c.cat.Set(ot.NameValueType{Name: {{printf "%q" .Name}}, Value: values, Type: ot.{{.Type}}})
+}
+{{else}}
+// {{.Field}} returns the value of the attribute {{printf "%q" .Name}}.
+func (c *{{$.Name}}) {{.Field}}() ({{.GoType}}, error) {
+	var v {{.GoType}}
+	err := c.cat.{{.Getter}}({{printf "%q" .Name}}, &v)
+	return v, err
+}
+
+// Set{{.Field}} sets the value of the attribute {{printf "%q" .Name}}.
+func (c *{{$.Name}}) Set{{.Field}}(v {{.GoType}}) error {
+	return c.cat.Set(ot.{{.Ctor}}({{printf "%q" .Name}}, v))
+}
+{{end}}{{end}}`))
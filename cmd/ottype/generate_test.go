@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/itcomusic/ot"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	cat := &ot.Category{
+		DisplayName: "Invoice",
+		Key:         "12345.2",
+		Data: []ot.Value{
+			{Description: "Invoice Number", Type: ot.StringType},
+			{Description: "amount", Type: ot.IntType},
+			{Description: "Paid", Type: ot.BoolType},
+			{Description: "Due date", Type: ot.TimeType},
+			{Description: "Tags", Type: ot.StringType, MaxValues: 10},
+			{Description: "Invoice-Number", Type: ot.StringType},
+			{Description: "1st", Type: ot.IntType},
+		},
+	}
+
+	src, err := generate("invoice", "Invoice", cat)
+	require.Nil(t, err)
+
+	if *update {
+		require.Nil(t, os.WriteFile("testdata/invoice.golden", src, 0o644))
+	}
+
+	exp, err := os.ReadFile("testdata/invoice.golden")
+	require.Nil(t, err)
+	assert.Equal(t, string(exp), string(src))
+}
+
+func TestGenerate_UnsupportedType(t *testing.T) {
+	_, err := generate("invoice", "Invoice", &ot.Category{Data: []ot.Value{{Description: "a"}}})
+	assert.EqualError(t, err, `attribute "a" has unsupported type NilType`)
+}
+
+func TestFieldName(t *testing.T) {
+	used := map[string]bool{"Category": true}
+	for _, v := range []struct{ desc, exp string }{
+		{"invoice number", "InvoiceNumber"},
+		{"Invoice Number", "InvoiceNumber2"},
+		{"1st", "Attr1st"},
+		{"Category", "Category2"},
+		{"Сумма", "Сумма"},
+	} {
+		assert.Equal(t, v.exp, fieldName(v.desc, used), v.desc)
+	}
+}
@@ -0,0 +1,59 @@
+// Command ottype generates typed Go wrapper of the category template.
+//
+// Usage:
+//
+//	ottype -addr 127.0.0.1 -user test -password test -id 12345 -pkg invoice -type Invoice -o invoice_category.go
+//
+// The generated type has the getter and setter for each attribute of the category,
+// multi-valued attributes are got and set as slices.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/itcomusic/ot"
+)
+
+func main() {
+	var (
+		addr     = flag.String("addr", "127.0.0.1", "address of the server")
+		user     = flag.String("user", "", "name of the user")
+		password = flag.String("password", "", "password of the user")
+		id       = flag.Int64("id", 0, "id of the category")
+		pkg      = flag.String("pkg", "main", "package of the generated file")
+		name     = flag.String("type", "", "name of the generated type")
+		out      = flag.String("o", "", "output file, standard output by default")
+	)
+	flag.Parse()
+
+	if *id == 0 || *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cat, err := ot.NewEndpoint(*addr).User(*user, *password).GetCategory(ctx, *id)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(*pkg, *name, cat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,136 @@
+package ot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDateLayout is the layout of the dates converted to strings by Upgrade.
+const DefaultDateLayout = "2006-01-02"
+
+// isoLayouts are the layouts which are parsed in every locale.
+var isoLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", DefaultDateLayout}
+
+// localeLayouts are the short date layouts of the locales, with and without time.
+var localeLayouts = map[string][]string{
+	"en-US": {"1/2/2006 3:04 PM", "1/2/2006 15:04", "1/2/2006"},
+	"en-GB": {"2/1/2006 15:04", "2/1/2006"},
+	"de-DE": {"2.1.2006 15:04", "2.1.2006"},
+	"fr-FR": {"2/1/2006 15:04", "2/1/2006"},
+	"ru-RU": {"2.1.2006 15:04", "2.1.2006"},
+	"ja-JP": {"2006/1/2 15:04", "2006/1/2"},
+}
+
+// localeLangs are the locales used for the language without the region.
+var localeLangs = map[string]string{"en": "en-US", "de": "de-DE", "fr": "fr-FR", "ru": "ru-RU", "ja": "ja-JP"}
+
+// DateLayouts returns layouts of the dates written in the locale (e.g. "de-DE" or "de"), the ISO layouts
+// are always included. Only the ISO layouts are returned for the unknown locale.
+func DateLayouts(locale string) []string {
+	layouts, ok := localeLayouts[locale]
+	if !ok {
+		layouts = localeLayouts[localeLangs[strings.SplitN(locale, "-", 2)[0]]]
+	}
+	return append(append([]string(nil), isoLayouts...), layouts...)
+}
+
+// convert returns the copy of the value with the values converted by fn, nil values are kept.
+func (v Value) convert(t TypeValue, fn func(x interface{}) (interface{}, error)) (Value, error) {
+	values := make([]interface{}, len(v.Value))
+	for i, x := range v.Value {
+		if x == nil {
+			continue
+		}
+
+		c, err := fn(x)
+		if err != nil {
+			return Value{}, fmt.Errorf("attribute \"%s\": %w", v.Description, err)
+		}
+		values[i] = c
+	}
+
+	v.Value, v.Type = values, t
+	return v, nil
+}
+
+// ToString converts the values to strings, the times are formatted by the layout.
+func (v Value) ToString(layout string) (Value, error) {
+	return v.convert(StringType, func(x interface{}) (interface{}, error) {
+		switch x := x.(type) {
+		case string:
+			return x, nil
+		case int:
+			return strconv.Itoa(x), nil
+		case bool:
+			return strconv.FormatBool(x), nil
+		case time.Time:
+			return x.Format(layout), nil
+		default:
+			return nil, fmt.Errorf("failed convert %T to string", x)
+		}
+	})
+}
+
+// ToInt converts the values to integers, the strings are parsed as decimal numbers.
+func (v Value) ToInt() (Value, error) {
+	return v.convert(IntType, func(x interface{}) (interface{}, error) {
+		switch x := x.(type) {
+		case int:
+			return x, nil
+		case string:
+			return strconv.Atoi(strings.TrimSpace(x))
+		case bool:
+			if x {
+				return 1, nil
+			}
+			return 0, nil
+		default:
+			return nil, fmt.Errorf("failed convert %T to int", x)
+		}
+	})
+}
+
+// ToTime converts the values to times, the strings are parsed by the first matching layout in the location.
+// Use DateLayouts to parse the dates written in the locale.
+func (v Value) ToTime(loc *time.Location, layouts ...string) (Value, error) {
+	return v.convert(TimeType, func(x interface{}) (interface{}, error) {
+		switch x := x.(type) {
+		case time.Time:
+			return x, nil
+		case string:
+			s := strings.TrimSpace(x)
+			for _, layout := range layouts {
+				if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("failed parse time %q", x)
+		default:
+			return nil, fmt.Errorf("failed convert %T to time.Time", x)
+		}
+	})
+}
+
+// Convert converts the values to the type. The times are formatted by DefaultDateLayout and
+// the strings are parsed as ISO dates in UTC, the booleans are converted only to strings and integers.
+func (v Value) Convert(t TypeValue) (Value, error) {
+	switch t {
+	case StringType:
+		return v.ToString(DefaultDateLayout)
+	case IntType:
+		return v.ToInt()
+	case TimeType:
+		return v.ToTime(time.UTC, isoLayouts...)
+	case BoolType:
+		return v.convert(BoolType, func(x interface{}) (interface{}, error) {
+			if b, ok := x.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("failed convert %T to bool", x)
+		})
+	default:
+		return Value{}, errNilTypeValue
+	}
+}
@@ -0,0 +1,78 @@
+package ot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue_Convert(t *testing.T) {
+	t.Parallel()
+
+	tm := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		v   Value
+		to  TypeValue
+		exp []interface{}
+		err string
+	}{
+		{v: Value{Type: IntType, Value: []interface{}{1, nil}}, to: StringType, exp: []interface{}{"1", nil}},
+		{v: Value{Type: BoolType, Value: []interface{}{true}}, to: StringType, exp: []interface{}{"true"}},
+		{v: Value{Type: TimeType, Value: []interface{}{tm}}, to: StringType, exp: []interface{}{"2020-01-02"}},
+		{v: Value{Type: StringType, Value: []interface{}{" 12 "}}, to: IntType, exp: []interface{}{12}},
+		{v: Value{Type: BoolType, Value: []interface{}{true, false}}, to: IntType, exp: []interface{}{1, 0}},
+		{v: Value{Type: StringType, Value: []interface{}{"2020-01-02"}}, to: TimeType, exp: []interface{}{tm}},
+		{v: Value{Description: "a", Type: StringType, Value: []interface{}{"x"}}, to: IntType, err: `attribute "a": strconv.Atoi: parsing "x": invalid syntax`},
+		{v: Value{Description: "a", Type: StringType, Value: []interface{}{"02.01.2020"}}, to: TimeType, err: `attribute "a": failed parse time "02.01.2020"`},
+		{v: Value{Description: "a", Type: StringType, Value: []interface{}{"true"}}, to: BoolType, err: `attribute "a": failed convert string to bool`},
+	}
+
+	for i, tt := range testCases {
+		v, err := tt.v.Convert(tt.to)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, i)
+			continue
+		}
+
+		require.Nil(t, err, i)
+		assert.Equal(t, tt.to, v.Type, i)
+		assert.Equal(t, tt.exp, v.Value, i)
+	}
+}
+
+func TestValue_ToTimeLocale(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("MSK", 3*60*60)
+	testCases := []struct {
+		locale string
+		value  string
+		exp    time.Time
+	}{
+		{locale: "de-DE", value: "2.1.2020", exp: time.Date(2020, 1, 2, 0, 0, 0, 0, loc)},
+		{locale: "ru", value: "02.01.2020 10:30", exp: time.Date(2020, 1, 2, 10, 30, 0, 0, loc)},
+		{locale: "en-US", value: "1/2/2020 3:04 PM", exp: time.Date(2020, 1, 2, 15, 4, 0, 0, loc)},
+		{locale: "en-GB", value: "02/01/2020", exp: time.Date(2020, 1, 2, 0, 0, 0, 0, loc)},
+		{locale: "xx", value: "2020-01-02", exp: time.Date(2020, 1, 2, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range testCases {
+		v, err := Value{Value: []interface{}{tt.value}}.ToTime(loc, DateLayouts(tt.locale)...)
+		require.Nil(t, err, tt.locale)
+		assert.True(t, tt.exp.Equal(v.Value[0].(time.Time)), tt.locale)
+	}
+}
+
+func TestCategory_UpgradeConvert(t *testing.T) {
+	t.Parallel()
+
+	cat := &Category{Key: "1.1", Data: []Value{{Description: "a", Type: IntType, Value: []interface{}{1}}}}
+	require.Nil(t, cat.Upgrade(Category{Key: "1.2", Data: []Value{{Description: "a", Type: StringType, Value: []interface{}{nil}}}}))
+	assert.Equal(t, []Value{{Description: "a", Type: StringType, Value: []interface{}{"1"}}}, cat.Data)
+
+	cat = &Category{Key: "1.1", Data: []Value{{Description: "a", Type: StringType, Value: []interface{}{"x"}}}}
+	err := cat.Upgrade(Category{Key: "1.2", Data: []Value{{Description: "a", Type: IntType}}})
+	assert.EqualError(t, err, `invalid type attribute "a" "Core.IntegerValue": attribute "a": strconv.Atoi: parsing "x": invalid syntax`)
+}
@@ -0,0 +1,59 @@
+package ot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+)
+
+// ContentIndex is the interface implemented by types that find the document by hash of the content.
+type ContentIndex interface {
+	// Find returns id of the document with the content hash, zero if not found.
+	Find(ctx context.Context, hash string) (int64, error)
+	// Store saves id of the document with the content hash.
+	Store(ctx context.Context, hash string, id int64) error
+}
+
+// Dedup returns session which skips uploading of the content already found in index by sha256 hash.
+// CreateFile and UpsertFile set NodeID of the found document instead of uploading.
+// The content is checked only when it may be read twice, e.g. implements io.Seeker.
+func (s *Session) Dedup(index ContentIndex) *Session {
+	c := s.clone()
+	c.index = index
+	return c
+}
+
+// findContent returns hash of the content and id of the document with the same content.
+// Hash is empty when deduplication is disabled.
+func (s *Session) findContent(ctx context.Context, body BodyFunc, resend bool) (string, int64, error) {
+	if s.index == nil || !resend {
+		return "", 0, nil
+	}
+
+	r, err := body()
+	if err != nil {
+		return "", 0, err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", 0, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	id, err := s.index.Find(ctx, hash)
+	if err != nil {
+		return "", 0, err
+	}
+	return hash, id, nil
+}
+
+// storeContent saves id of the uploaded document in the index.
+func (s *Session) storeContent(ctx context.Context, hash string, id int64) error {
+	if hash == "" {
+		return nil
+	}
+	return s.index.Store(ctx, hash, id)
+}
@@ -0,0 +1,68 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapIndex struct {
+	mu sync.Mutex
+	m  map[string]int64
+}
+
+func (i *mapIndex) Find(_ context.Context, hash string) (int64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.m[hash], nil
+}
+
+func (i *mapIndex) Store(_ context.Context, hash string, id int64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.m[hash] = id
+	return nil
+}
+
+func TestSession_Dedup(t *testing.T) {
+	t.Parallel()
+
+	contentFile := "content of the file"
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	ss := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		mu.Lock()
+		calls = append(calls, req["ServiceMethod"].(string))
+		mu.Unlock()
+
+		switch req["ServiceMethod"] {
+		case "GetNodeByName":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		case "CreateSimpleDocument":
+			file := make([]byte, len(contentFile))
+			_, err := io.ReadFull(r, file)
+			require.Nil(t, err)
+			w.WriteString("A<1,?,'_Status'=0,'Results'=3>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).Dedup(&mapIndex{m: map[string]int64{}})
+
+	fa := &FileAttr{Name: "file.txt", Size: int64(len(contentFile))}
+	require.Nil(t, ss.UpsertFile(context.Background(), 1, "name", fa, strings.NewReader(contentFile)))
+	assert.Equal(t, int64(3), fa.NodeID)
+
+	fa = &FileAttr{Name: "copy.txt", Size: int64(len(contentFile))}
+	require.Nil(t, ss.CreateFile(context.Background(), 1, "copy", fa, strings.NewReader(contentFile)))
+	assert.Equal(t, int64(3), fa.NodeID)
+	assert.Equal(t, []string{"GetNodeByName", "CreateSimpleDocument"}, calls)
+}
@@ -0,0 +1,91 @@
+package ot
+
+import (
+	"context"
+	"path"
+)
+
+// DeleteAction is the decision of DeleteFunc about the node.
+type DeleteAction int
+
+const (
+	// DeleteContinue deletes the node.
+	DeleteContinue DeleteAction = iota
+	// DeleteSkip keeps the node with the subtree, the parents of the node are kept too.
+	DeleteSkip
+	// DeleteAbort stops deleting, the nodes deleted before are not restored.
+	DeleteAbort
+)
+
+// DeleteFunc is called before deleting of every node, the path is the names of the nodes from the root joined by slash.
+// The number of the children is in Node.ContainerInfo.ChildCount.
+type DeleteFunc func(path string, node *Node) DeleteAction
+
+// DeleteOptions are options of DeleteNodeRecursive.
+type DeleteOptions struct {
+	Before DeleteFunc // nil deletes every node
+	DryRun bool       // counts the nodes without deleting
+}
+
+// DeleteResult is the number of the nodes visited by DeleteNodeRecursive.
+type DeleteResult struct {
+	Deleted int // in dry run the nodes which would be deleted
+	Skipped int // the nodes skipped by DeleteFunc and their parents
+}
+
+// DeleteNodeRecursive deletes the node with the subtree from the leaves to the root, so the folder is deleted
+// only when it is empty and never depends on the server configuration. ErrDeleteAborted is returned
+// when DeleteFunc aborts deleting, the result counts the nodes visited before.
+func (s *Session) DeleteNodeRecursive(ctx context.Context, id int64, opts DeleteOptions) (*DeleteResult, error) {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var res DeleteResult
+	_, err = s.deleteRecursive(ctx, node.Name, node, opts, &res)
+	return &res, err
+}
+
+// deleteRecursive reports whether the node is deleted.
+func (s *Session) deleteRecursive(ctx context.Context, p string, node *Node, opts DeleteOptions, res *DeleteResult) (bool, error) {
+	if opts.Before != nil {
+		switch opts.Before(p, node) {
+		case DeleteSkip:
+			res.Skipped++
+			return false, nil
+		case DeleteAbort:
+			return false, ErrDeleteAborted
+		}
+	}
+
+	empty := true
+	if node.IsContainer {
+		children, err := s.ListNodes(ctx, node.ID)
+		if err != nil {
+			return false, err
+		}
+
+		for i := range children {
+			deleted, err := s.deleteRecursive(ctx, path.Join(p, children[i].Name), &children[i], opts, res)
+			if err != nil {
+				return false, err
+			}
+			empty = empty && deleted
+		}
+	}
+
+	if !empty {
+		res.Skipped++
+		return false, nil
+	}
+
+	if !opts.DryRun {
+		if err := s.DeleteNode(ctx, node.ID); err != nil {
+			return false, err
+		}
+	}
+
+	res.Deleted++
+	return true, nil
+}
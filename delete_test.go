@@ -0,0 +1,86 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deleteServer(t *testing.T, deleted *[]int64) *Session {
+	var mu sync.Mutex
+	return session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetNode":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='root','IsContainer'=true>>")
+		case "ListNodes":
+			switch args["parentID"] {
+			case int64(1):
+				w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=2,'Name'='a','IsContainer'=true>,A<1,?,'ID'=3,'Name'='b'>}>")
+			case int64(2):
+				w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=4,'Name'='x'>,A<1,?,'ID'=5,'Name'='keep'>}>")
+			}
+		case "DeleteNode":
+			mu.Lock()
+			*deleted = append(*deleted, args["ID"].(int64))
+			mu.Unlock()
+			w.WriteString("A<1,?,'_Status'=0>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+}
+
+func TestSession_DeleteNodeRecursive(t *testing.T) {
+	t.Parallel()
+
+	var deleted []int64
+	var paths []string
+	res, err := deleteServer(t, &deleted).DeleteNodeRecursive(context.Background(), 1, DeleteOptions{
+		Before: func(p string, node *Node) DeleteAction {
+			paths = append(paths, p)
+			if node.Name == "keep" {
+				return DeleteSkip
+			}
+			return DeleteContinue
+		},
+	})
+
+	require.Nil(t, err)
+	assert.Equal(t, &DeleteResult{Deleted: 2, Skipped: 3}, res)
+	assert.Equal(t, []int64{4, 3}, deleted)
+	assert.Equal(t, []string{"root", "root/a", "root/a/x", "root/a/keep", "root/b"}, paths)
+}
+
+func TestSession_DeleteNodeRecursiveDryRun(t *testing.T) {
+	t.Parallel()
+
+	var deleted []int64
+	res, err := deleteServer(t, &deleted).DeleteNodeRecursive(context.Background(), 1, DeleteOptions{DryRun: true})
+
+	require.Nil(t, err)
+	assert.Equal(t, &DeleteResult{Deleted: 5}, res)
+	assert.Empty(t, deleted)
+}
+
+func TestSession_DeleteNodeRecursiveAbort(t *testing.T) {
+	t.Parallel()
+
+	var deleted []int64
+	res, err := deleteServer(t, &deleted).DeleteNodeRecursive(context.Background(), 1, DeleteOptions{
+		Before: func(p string, node *Node) DeleteAction {
+			if node.Name == "keep" {
+				return DeleteAbort
+			}
+			return DeleteContinue
+		},
+	})
+
+	assert.Equal(t, ErrDeleteAborted, err)
+	assert.Equal(t, &DeleteResult{Deleted: 1}, res)
+	assert.Equal(t, []int64{4}, deleted)
+}
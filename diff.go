@@ -0,0 +1,274 @@
+package ot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// Differ compares contents of two versions. The contents are streamed from the server while they are read,
+// so a and b should be read concurrently or the connection of the unread content waits.
+type Differ interface {
+	Diff(ctx context.Context, a, b io.Reader) error
+}
+
+// CompareVersions streams contents of the versions of the document into differ without temporary files.
+// The errors of the reading are returned to differ by the readers.
+func (s *Session) CompareVersions(ctx context.Context, nodeID, verA, verB int64, differ Differ) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		readers [2]*io.PipeReader
+	)
+	for i, version := range []int64{verA, verB} {
+		r, w := io.Pipe()
+		readers[i] = r
+
+		wg.Add(1)
+		go func(version int64) {
+			defer wg.Done()
+
+			_, err := s.ReadFile(ctx, nodeID, version, w)
+			w.CloseWithError(err) // nil error is io.EOF for the reader
+		}(version)
+	}
+
+	err := differ.Diff(ctx, readers[0], readers[1])
+	for _, r := range readers {
+		r.CloseWithError(errDiffDone) // releases the reading when differ has not read the content to the end
+	}
+	cancel()
+	wg.Wait()
+	return err
+}
+
+// errDiffDone is the error of the writing of the content after differ has returned.
+var errDiffDone = errors.New("ot: differ has returned")
+
+// TextDiffer compares the versions as text by lines and writes the changes in the unified format to W,
+// nothing is written when the contents are equal. Both contents are kept in memory, it is intended for
+// the text documents of the moderate size.
+type TextDiffer struct {
+	W       io.Writer
+	A, B    string // names of the versions in the header, the header is omitted when both are empty
+	Context int    // number of the unchanged lines around the changes
+}
+
+// Diff implements Differ.
+func (d TextDiffer) Diff(_ context.Context, a, b io.Reader) error {
+	var (
+		wg         sync.WaitGroup
+		textA      []byte
+		errA, errB error
+	)
+	wg.Add(1)
+	go func() { // the contents are read concurrently
+		defer wg.Done()
+		textA, errA = ioutil.ReadAll(a)
+	}()
+
+	textB, errB := ioutil.ReadAll(b)
+	wg.Wait()
+
+	if errA != nil {
+		return errA
+	}
+
+	if errB != nil {
+		return errB
+	}
+
+	linesA, linesB := splitLines(string(textA)), splitLines(string(textB))
+	ops := diffLines(linesA, linesB)
+	hunks := diffHunks(ops, d.Context)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	var out strings.Builder
+	if d.A != "" || d.B != "" {
+		fmt.Fprintf(&out, "--- %s\n+++ %s\n", d.A, d.B)
+	}
+
+	for _, h := range hunks {
+		h.write(&out, linesA, linesB)
+	}
+	_, err := io.WriteString(d.W, out.String())
+	return err
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is an operation of the edit script: ' ' keeps, '-' deletes line a, '+' inserts line b.
+type diffOp struct {
+	kind byte
+	a, b int // indexes of the lines, the index of the other side is the position of the operation
+}
+
+// diffLines returns the shortest edit script of the lines by the Myers algorithm.
+// The trace keeps only the diagonals [-d, d] of the step d, so the memory is O(D²) of the number of the changes D.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	v := make([]int, 2*max+2)
+
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[max-d:max+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[max+k-1] < v[max+k+1] {
+				x = v[max+k+1] // insertion
+			} else {
+				x = v[max+k-1] + 1 // deletion
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack returns the edit script from the end of the trace, trace[d][d+k] is the furthest x of the diagonal k.
+func backtrack(trace [][]int, x, y int) []diffOp {
+	var ops []diffOp
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || k != d && v[d+k-1] < v[d+k+1] {
+			prevK = k + 1
+		}
+
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: ' ', a: x, b: y})
+		}
+
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{kind: '+', a: x, b: y})
+		} else {
+			x--
+			ops = append(ops, diffOp{kind: '-', a: x, b: y})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{kind: ' ', a: x, b: y})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// diffHunk is a range of the edit script with the changes and the unchanged lines around them.
+type diffHunk []diffOp
+
+func diffHunks(ops []diffOp, context int) []diffHunk {
+	var (
+		hunks    []diffHunk
+		start    = -1
+		lastEdit = -1
+	)
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+
+		if start >= 0 && i-lastEdit > 2*context+1 {
+			hunks = append(hunks, ops[start:lastEdit+context+1])
+			start = -1
+		}
+
+		if start < 0 {
+			start = i - context
+			if start < 0 {
+				start = 0
+			}
+		}
+		lastEdit = i
+	}
+
+	if start >= 0 {
+		end := lastEdit + context + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		hunks = append(hunks, ops[start:end])
+	}
+	return hunks
+}
+
+func (h diffHunk) write(w io.Writer, a, b []string) {
+	var lenA, lenB int
+	for _, op := range h {
+		if op.kind != '+' {
+			lenA++
+		}
+
+		if op.kind != '-' {
+			lenB++
+		}
+	}
+
+	fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(h[0].a, lenA), hunkRange(h[0].b, lenB))
+	for _, op := range h {
+		var line string
+		if op.kind == '+' {
+			line = b[op.b]
+		} else {
+			line = a[op.a]
+		}
+
+		fmt.Fprintf(w, "%c%s", op.kind, line)
+		if !strings.HasSuffix(line, "\n") {
+			fmt.Fprint(w, "\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange returns range of the lines in the unified format, the empty range refers to the line before it.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if n == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
@@ -0,0 +1,105 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextDiffer(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{name: "empty", a: "", b: "", want: ""},
+		{name: "insert into empty", a: "", b: "a\n", want: "@@ -0,0 +1 @@\n+a\n"},
+		{name: "delete all", a: "a\nb\n", b: "", want: "@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{
+			name:    "change",
+			a:       "a\nb\nc\nd\n",
+			b:       "a\nB\nc\nd\n",
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "0\n1\n2\n3\n4\n5\n6\n8\n",
+			context: 1,
+			want:    "@@ -1 +1,2 @@\n+0\n 1\n@@ -6,3 +7,2 @@\n 6\n-7\n 8\n",
+		},
+		{
+			name:    "merged hunks",
+			a:       "1\n2\n3\n",
+			b:       "0\n1\n2\n",
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n+0\n 1\n 2\n-3\n",
+		},
+		{
+			name: "no newline",
+			a:    "a\nb",
+			b:    "a\nc",
+			want: "@@ -2 +2 @@\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	} {
+		var out strings.Builder
+		require.Nil(t, TextDiffer{W: &out, Context: tt.context}.Diff(context.Background(), strings.NewReader(tt.a), strings.NewReader(tt.b)), tt.name)
+		assert.Equal(t, tt.want, out.String(), tt.name)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	t.Parallel()
+
+	a := make([]string, 10000)
+	for i := range a {
+		a[i] = fmt.Sprintln(i)
+	}
+	b := append([]string{"first\n"}, a[:5000]...)
+	b = append(b, a[5001:]...)
+	b = append(b, "last\n")
+
+	var changes int
+	var got []string
+	for _, op := range diffLines(a, b) {
+		switch op.kind {
+		case ' ':
+			assert.Equal(t, a[op.a], b[op.b])
+			got = append(got, a[op.a])
+		case '+':
+			changes++
+			got = append(got, b[op.b])
+		case '-':
+			changes++
+		}
+	}
+	assert.Equal(t, 3, changes)
+	assert.Equal(t, b, got)
+}
+
+func TestSession_CompareVersions(t *testing.T) {
+	t.Parallel()
+
+	contents := map[int64]string{1: "a\nb\nc\n", 2: "a\nB\nc\n"}
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		content := contents[req["Arguments"].(map[string]interface{})["versionNum"].(int64)]
+		fmt.Fprintf(w, "A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=%d>,'_Status'=0>", len(content))
+		w.WriteString(content)
+		assert.Nil(t, w.Flush())
+	})
+
+	var out strings.Builder
+	require.Nil(t, s.CompareVersions(context.Background(), 10, 1, 2, TextDiffer{W: &out, A: "v1", B: "v2", Context: 1}))
+	assert.Equal(t, "--- v1\n+++ v2\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n", out.String())
+}
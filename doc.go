@@ -3,10 +3,12 @@ package ot
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/itcomusic/ot/internal/client"
 	"github.com/itcomusic/ot/pkg/oscript"
 )
 
@@ -19,6 +21,7 @@ type FileAttr struct {
 	Modified time.Time `oscript:"ModifiedDate"`
 	Name     string    `oscript:"Name"`
 	Size     int64     `oscript:"DataForkSize"`
+	Version  int64     `oscript:"-"` // number of the version
 }
 
 // MarshalOscriptBuf marshals to specific format, the fields for process unmarshal/marshal are identified differently.
@@ -36,8 +39,43 @@ func (f *FileAttr) MarshalOscriptBuf(buf oscript.Buffer) error {
 	return nil
 }
 
-// OpenFile opens the named file for reading.
+// UnmarshalOscript implements the oscript.Unmarshaler interface. It accepts both the file attributes
+// of the response (Name, DataForkSize) and Core.FileAtts (FileName, FileSize).
+func (f *FileAttr) UnmarshalOscript(data []byte) error {
+	var v struct {
+		Created  time.Time        `oscript:"CreatedDate"`
+		Modified time.Time        `oscript:"ModifiedDate"`
+		Name     Nullable[string] `oscript:"Name"`
+		FileName Nullable[string] `oscript:"FileName"`
+		Size     Nullable[int64]  `oscript:"DataForkSize"`
+		FileSize Nullable[int64]  `oscript:"FileSize"`
+		Version  int64            `oscript:"Version"`
+	}
+
+	if err := oscript.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	f.Created, f.Modified = v.Created, v.Modified
+	f.Name, f.Size, f.Version = v.Name.Value, v.Size.Value, v.Version
+	if v.FileName.Valid {
+		f.Name = v.FileName.Value
+	}
+
+	if v.FileSize.Valid {
+		f.Size = v.FileSize.Value
+	}
+	return nil
+}
+
+// OpenFile opens the named file for reading. Created of the file is the current time, use OpenFileCreated
+// to preserve the original time.
 func OpenFile(name string) (*os.File, *FileAttr, error) {
+	return OpenFileCreated(name, time.Now())
+}
+
+// OpenFileCreated opens the named file for reading with the time of creation.
+func OpenFileCreated(name string, created time.Time) (*os.File, *FileAttr, error) {
 	f, err := os.Open(name)
 	if err != nil {
 		return nil, nil, err
@@ -45,72 +83,231 @@ func OpenFile(name string) (*os.File, *FileAttr, error) {
 
 	s, err := f.Stat()
 	if err != nil {
+		f.Close()
 		return nil, nil, err
 	}
 
 	return f, &FileAttr{
 		Name:     s.Name(),
 		Size:     s.Size(),
-		Created:  time.Now(),
+		Created:  created,
 		Modified: s.ModTime(),
 	}, nil
 }
 
-// CreateFile creates a document.
-func (s *Session) CreateFile(ctx context.Context, parent int64, name string, file *FileAttr, r io.Reader) error {
+// ApplyTimes sets modification time of the file to Modified, creation time can not be set portably.
+func (f *FileAttr) ApplyTimes(file *os.File) error {
+	return os.Chtimes(file.Name(), f.Modified, f.Modified)
+}
+
+// BodyFunc returns new reader of the content on every call, it allows to resend the content on retry.
+type BodyFunc func() (io.ReadCloser, error)
+
+// bodyOf returns factory of the content and reports whether the content may be resent.
+// The content may be resent when r implements io.Seeker.
+func bodyOf(r io.Reader) (BodyFunc, bool) {
+	once := func() (io.ReadCloser, error) { return ioutil.NopCloser(r), nil }
+
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		return once, false
+	}
+
+	offset, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return once, false
+	}
+
+	return func() (io.ReadCloser, error) {
+		if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(rs), nil
+	}, true
+}
+
+// upload sends request with the content and reads response. Sending is repeated according to the retry policy
+// when the content may be resent and writing has failed, the failed reading of the response is never repeated
+// because the server may have processed the request.
+func (s *Session) upload(ctx context.Context, body BodyFunc, resend bool, size int64, write, read func(c *client.Client) error) error {
+	for attempt := 0; ; attempt++ {
+		written, err := s.uploadOnce(ctx, body, size, write, read)
+		if err == nil || written || !resend || !s.ep.retry.wait(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+// uploadOnce makes one attempt of the upload and reports whether the request was written.
+func (s *Session) uploadOnce(ctx context.Context, body BodyFunc, size int64, write, read func(c *client.Client) error) (bool, error) {
+	r, err := body()
+	if err != nil {
+		return true, err
+	}
+	defer r.Close()
+
 	c, err := s.connect(ctx)
 	if err != nil {
-		return err
+		return true, err // dial is already retried
 	}
 	defer c.Close()
 
-	if err := c.Write(docmanService, "CreateSimpleDocument", s.auth,
-		oscript.M{
-			"parentID": parent,
-			"name":     name,
-			"fileAtts": file,
-		}); err != nil {
+	if err := write(c); err != nil {
+		return err == ErrReadOnlySession, err // the rejected request is not repeated
+	}
+
+	if err := c.WriteFrom(r, size); err != nil {
+		_, short := err.(*ShortTransferError)
+		return short, err // short content is not fixed by resending
+	}
+
+	return true, read(c)
+}
+
+// CreateFile creates a document. The content is resent on retry when r implements io.Seeker.
+func (s *Session) CreateFile(ctx context.Context, parent int64, name string, file *FileAttr, r io.Reader) error {
+	body, resend := bodyOf(r)
+	return s.createFile(ctx, parent, name, file, body, resend)
+}
+
+// CreateFileBody creates a document, the content is got from body on every attempt.
+func (s *Session) CreateFileBody(ctx context.Context, parent int64, name string, file *FileAttr, body BodyFunc) error {
+	return s.createFile(ctx, parent, name, file, body, true)
+}
+
+func (s *Session) createFile(ctx context.Context, parent int64, name string, file *FileAttr, body BodyFunc, resend bool) error {
+	hash, id, err := s.findContent(ctx, body, resend)
+	if err != nil {
 		return err
 	}
 
-	if err := c.WriteFrom(r); err != nil {
+	if id != 0 {
+		file.NodeID = id
+		return nil
+	}
+
+	if err := s.uploadFile(ctx, parent, name, file, body, resend); err != nil {
 		return err
 	}
+	return s.storeContent(ctx, hash, file.NodeID)
+}
 
-	if err := errIn(c.Read(&file.NodeID)); err != nil {
+func (s *Session) uploadFile(ctx context.Context, parent int64, name string, file *FileAttr, body BodyFunc, resend bool) error {
+	if err := s.upload(ctx, body, resend, file.Size, func(c *client.Client) error {
+		return c.Write(docmanService, "CreateSimpleDocument", s.auth,
+			oscript.M{
+				"parentID": parent,
+				"name":     name,
+				"fileAtts": file,
+			})
+	}, func(c *client.Client) error {
+		return errIn(c.Read(&file.NodeID))
+	}); err != nil {
 		return err
 	}
+
+	s.events.publish(Event{Type: NodeCreated, NodeID: file.NodeID, Parent: parent})
 	return nil
 }
 
-// AddVersionFile adds new version of the file.
+// AddVersionFile adds new version of the file, the number of the created version is set in file.Version.
+// The content is resent on retry when r implements io.Seeker.
 func (s *Session) AddVersionFile(ctx context.Context, file *FileAttr, r io.Reader) error {
-	c, err := s.connect(ctx)
+	_, err := s.AddVersion(ctx, file, r)
+	return err
+}
+
+// AddVersion adds new version of the file and returns the created version.
+// The content is resent on retry when r implements io.Seeker.
+func (s *Session) AddVersion(ctx context.Context, file *FileAttr, r io.Reader) (*Version, error) {
+	body, resend := bodyOf(r)
+	return s.addVersionFile(ctx, file, body, resend)
+}
+
+func (s *Session) addVersionFile(ctx context.Context, file *FileAttr, body BodyFunc, resend bool) (*Version, error) {
+	var v Version
+	if err := s.upload(ctx, body, resend, file.Size, func(c *client.Client) error {
+		return c.Write(docmanService, "AddVersion", s.auth,
+			oscript.M{
+				"ID":       file.NodeID,
+				"Metadata": nil,
+				"fileAtts": file,
+			})
+	}, func(c *client.Client) error {
+		return errIn(c.Read(&v))
+	}); err != nil {
+		return nil, err
+	}
+
+	s.nodes.remove(file.NodeID)
+	s.events.publish(Event{Type: VersionAdded, NodeID: file.NodeID, Version: v.Number})
+	file.Version = v.Number
+	return &v, nil
+}
+
+// UpsertFile creates a document or adds new version when the document with the name exists in the parent.
+// The id of the document is taken from the path cache, it is checked by GetNode before sending the content
+// when r does not implement io.Seeker, so the content is never sent twice.
+func (s *Session) UpsertFile(ctx context.Context, parent int64, name string, file *FileAttr, r io.Reader) error {
+	body, resend := bodyOf(r)
+	hash, id, err := s.findContent(ctx, body, resend)
 	if err != nil {
 		return err
 	}
-	defer c.Close()
 
-	if err := c.Write(docmanService, "AddVersion", s.auth,
-		oscript.M{
-			"ID":       file.NodeID,
-			"Metadata": nil,
-			"fileAtts": file,
-		}); err != nil {
-		return err
+	if id != 0 {
+		file.NodeID = id
+		return nil
 	}
 
-	if err := c.WriteFrom(r); err != nil {
-		return err
+	key := pathKey(parent, name)
+	id, cached := s.ep.paths.get(key)
+	if cached && !resend {
+		// the content is read once, so the cached id is checked before sending the content
+		node, err := s.GetNode(ctx, id)
+		if re, ok := err.(*NodeRetrievalError); ok && re.NotFound() || err == nil && (node.Parent != parent || node.Name != name) {
+			s.ep.paths.removeID(id) // stale cache
+			cached = false
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if cached {
+		file.NodeID = id
+		_, err := s.addVersionFile(ctx, file, body, resend)
+		if err == nil {
+			return s.storeContent(ctx, hash, id)
+		}
+
+		if re, ok := err.(*NodeRetrievalError); !ok || !re.NotFound() || !resend {
+			return err
+		}
+		s.ep.paths.removeID(id) // stale cache, the content is sent again
 	}
 
-	if err := errIn(c.Read(nil)); err != nil {
+	node, err := s.GetNodeByName(ctx, parent, name)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	if node == nil {
+		if err := s.uploadFile(ctx, parent, name, file, body, resend); err != nil {
+			return err
+		}
+	} else {
+		file.NodeID = node.ID
+		if _, err := s.addVersionFile(ctx, file, body, resend); err != nil {
+			return err
+		}
+	}
+
+	s.ep.paths.add(key, file.NodeID)
+	return s.storeContent(ctx, hash, file.NodeID)
 }
 
 // ReadFile reads content and returns information about the file.
+// Use FileAttr.ApplyTimes to preserve times of the file when w is a file.
 func (s *Session) ReadFile(ctx context.Context, id, version int64, w io.Writer) (*FileAttr, error) {
 	c, err := s.connect(ctx)
 	if err != nil {
@@ -131,7 +328,7 @@ func (s *Session) ReadFile(ctx context.Context, id, version int64, w io.Writer)
 		return nil, err
 	}
 
-	if err := c.ReadTo(w); err != nil {
+	if err := c.ReadTo(w, fa.Size); err != nil {
 		return nil, err
 	}
 
@@ -147,37 +344,38 @@ type Document struct {
 	VersionControl bool
 	File           *FileAttr
 	Reader         io.Reader
+	Body           BodyFunc // used instead of Reader to resend the content on retry
 }
 
-// CreateDocument creates document.
-func (s *Session) CreateDocument(ctx context.Context, doc Document) error {
-	c, err := s.connect(ctx)
-	if err != nil {
-		return err
-	}
-	defer c.Close()
-
-	if err := c.Write(docmanService, "CreateDocument", s.auth,
-		oscript.M{
-			"parentID":               doc.Parent,
-			"name":                   doc.Name,
-			"comment":                doc.Comment,
-			"advancedVersionControl": doc.VersionControl,
-			"metadata":               doc.Metadata, // inherit metadata from the parent object if metadata not set
-			"fileAtts":               doc.File,
-		}); err != nil {
-		return err
-	}
-
-	if err := c.WriteFrom(doc.Reader); err != nil {
-		return err
+// CreateDocument creates document and returns the created node, the initial version is in Node.VersionInfo.
+func (s *Session) CreateDocument(ctx context.Context, doc Document) (*Node, error) {
+	body, resend := doc.Body, true
+	if body == nil {
+		body, resend = bodyOf(doc.Reader)
 	}
+	return s.createDocument(ctx, doc, body, resend)
+}
 
+func (s *Session) createDocument(ctx context.Context, doc Document, body BodyFunc, resend bool) (*Node, error) {
 	var node Node
-	if err := errIn(c.Read(&node)); err != nil {
-		return err
+	if err := s.upload(ctx, body, resend, doc.File.Size, func(c *client.Client) error {
+		return c.Write(docmanService, "CreateDocument", s.auth,
+			oscript.M{
+				"parentID":               doc.Parent,
+				"name":                   doc.Name,
+				"comment":                doc.Comment,
+				"advancedVersionControl": doc.VersionControl,
+				"metadata":               doc.Metadata, // inherit metadata from the parent object if metadata not set
+				"fileAtts":               doc.File,
+			})
+	}, func(c *client.Client) error {
+		return errIn(c.Read(&node))
+	}); err != nil {
+		return nil, err
 	}
 
 	doc.File.NodeID = node.ID
-	return nil
+	doc.File.Version = node.VersionInfo.VersionNum
+	s.events.publish(Event{Type: NodeCreated, NodeID: node.ID, Parent: doc.Parent})
+	return &node, nil
 }
@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -36,6 +40,17 @@ func TestFileAttr_UnmarshalOscript(t *testing.T) {
 	err := oscript.Unmarshal([]byte("A<1,?,'CreatedDate'=D/2019/12/4:11:47:16,'Name'='test','DataForkSize'=1,'ModifiedDate'=D/2018/12/4:11:47:16,'_SDOName'='Core.FileAtts'>"), got)
 	require.Nil(t, err)
 	assert.Equal(t, &FileAttr{Created: cr, Modified: md, Name: "test", Size: 1}, got)
+
+	got = &FileAttr{NodeID: 2}
+	err = oscript.Unmarshal([]byte("A<1,?,'CreatedDate'=D/2019/12/4:11:47:16,'FileName'='test','FileSize'=1,'ModifiedDate'=D/2018/12/4:11:47:16,'_SDOName'='Core.FileAtts'>"), got)
+	require.Nil(t, err)
+	assert.Equal(t, &FileAttr{NodeID: 2, Created: cr, Modified: md, Name: "test", Size: 1}, got)
+
+	roundTrip, err := oscript.Marshal(got)
+	require.Nil(t, err)
+	got = &FileAttr{}
+	require.Nil(t, oscript.Unmarshal(roundTrip, got))
+	assert.Equal(t, &FileAttr{Created: cr, Modified: md, Name: "test", Size: 1}, got)
 }
 
 func TestSession_ReadFile(t *testing.T) {
@@ -49,6 +64,7 @@ func TestSession_ReadFile(t *testing.T) {
 		Name:     "test",
 		Size:     int64(len(contentFile)),
 		NodeID:   1,
+		Version:  2,
 	}
 
 	w := bytes.Buffer{}
@@ -176,4 +192,161 @@ func TestSession_AddVersionFile(t *testing.T) {
 		assert.Nil(t, w.Flush())
 	}).AddVersionFile(context.Background(), fa, r)
 	require.Nil(t, err)
+	assert.Equal(t, int64(2), fa.Version)
+}
+
+func TestSession_CreateFileRetry(t *testing.T) {
+	t.Parallel()
+
+	contentFile := "content of the file"
+	fa := &FileAttr{Name: "file.pdf", Size: int64(len(contentFile))}
+
+	var attempts int32
+	endp := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return // connection is closed before reading content
+		}
+
+		file := make([]byte, len(contentFile))
+		_, err := io.ReadFull(r, file)
+		require.Nil(t, err)
+		assert.Equal(t, contentFile, string(file))
+
+		w.WriteString("A<1,?,'Results'=3,'_Status'=0>")
+		assert.Nil(t, w.Flush())
+	})
+	endp.retry = RetryPolicy{Attempts: 2}
+
+	err := endp.User("u", "p").CreateFile(context.Background(), 1, "name", fa, strings.NewReader(contentFile))
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), fa.NodeID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestBodyOf(t *testing.T) {
+	t.Parallel()
+
+	_, resend := bodyOf(&bytes.Buffer{})
+	assert.False(t, resend)
+
+	r := strings.NewReader("skip content")
+	_, err := r.Seek(5, io.SeekStart)
+	require.Nil(t, err)
+
+	body, resend := bodyOf(r)
+	require.True(t, resend)
+	for i := 0; i < 2; i++ {
+		rc, err := body()
+		require.Nil(t, err)
+		b, err := ioutil.ReadAll(rc)
+		require.Nil(t, err)
+		assert.Equal(t, "content", string(b))
+	}
+}
+
+func TestOpenFileCreated(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "file")
+	require.Nil(t, ioutil.WriteFile(name, []byte("content"), 0600))
+
+	created := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	f, fa, err := OpenFileCreated(name, created)
+	require.Nil(t, err)
+	defer f.Close()
+	assert.Equal(t, created, fa.Created)
+	assert.Equal(t, int64(7), fa.Size)
+
+	fa.Modified = time.Date(2002, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.Nil(t, fa.ApplyTimes(f))
+
+	s, err := os.Stat(name)
+	require.Nil(t, err)
+	assert.True(t, fa.Modified.Equal(s.ModTime()))
+}
+
+func TestSession_CreateDocument(t *testing.T) {
+	t.Parallel()
+
+	content := "content of the file"
+	fa := &FileAttr{Name: "test", Size: int64(len(content))}
+	node, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "CreateDocument", req["ServiceMethod"])
+
+		file := make([]byte, len(content))
+		_, err := io.ReadFull(r, file)
+		require.Nil(t, err)
+		assert.Equal(t, content, string(file))
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=5,'Name'='test','VersionInfo'=A<1,?,'VersionNum'=1,'Versions'={A<1,?,'NodeID'=5,'Number'=1>}>>>")
+		assert.Nil(t, w.Flush())
+	}).CreateDocument(context.Background(), Document{Parent: 1, Name: "test", File: fa, Reader: strings.NewReader(content)})
+	require.Nil(t, err)
+
+	assert.Equal(t, int64(5), node.ID)
+	assert.Equal(t, []Version{{NodeID: 5, Number: 1}}, node.VersionInfo.Versions)
+	assert.Equal(t, &FileAttr{NodeID: 5, Name: "test", Size: int64(len(content)), Version: 1}, fa)
+}
+
+func TestSession_ReadFileCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+
+	var w bytes.Buffer
+	_, err := session(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		b, err := ioutil.ReadFile("testdata/read-file")
+		require.Nil(t, err)
+
+		buf.Write(b)
+		buf.WriteString("part of the content")
+		assert.Nil(t, buf.Flush())
+
+		cancel()
+		<-release // the stream is never finished
+	}).ReadFile(ctx, 1, 0, &w)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestSession_AddVersionFileCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		<-release // the content is never read
+	}).AddVersionFile(ctx, &FileAttr{NodeID: 1}, strings.NewReader("content of the file"))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestSession_ReadFileShort(t *testing.T) {
+	t.Parallel()
+
+	var w bytes.Buffer
+	_, err := session(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		b, err := ioutil.ReadFile("testdata/read-file")
+		require.Nil(t, err)
+
+		buf.Write(b)
+		buf.WriteString("content")
+		assert.Nil(t, buf.Flush())
+	}).ReadFile(context.Background(), 1, 0, &w)
+	assert.Equal(t, &ShortTransferError{Service: "DocumentManagement.GetVersionContents", Expected: 19, Actual: 7}, err)
+}
+
+func TestSession_AddVersionFileShort(t *testing.T) {
+	t.Parallel()
+
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		io.Copy(ioutil.Discard, r) // the response is never written
+	}).AddVersionFile(context.Background(), &FileAttr{NodeID: 1, Size: 19}, strings.NewReader("content"))
+	assert.Equal(t, &ShortTransferError{Service: "DocumentManagement.AddVersion", Expected: 19, Actual: 7}, err)
 }
@@ -0,0 +1,133 @@
+package ot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// CreateEmailDocument creates document of the email message in the RFC 822 format (.eml) and returns the created node.
+// The message is uploaded as is, the name of the document is the subject of the message with the suffix of the message,
+// so the messages with the same subject do not conflict, see emailName. The headers Subject, From,
+// To, Cc and Date are set in the group GroupEmail of the created node, so the categories of the parent are inherited.
+// The node is returned with the error when the created document is not updated.
+func (s *Session) CreateEmailDocument(ctx context.Context, parent int64, msg io.Reader) (*Node, error) {
+	b, err := ioutil.ReadAll(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := ParseEmail(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	file := &FileAttr{Name: emailName(props.Subject, emailID(b)), Size: int64(len(b)), Created: time.Now(), Modified: props.SentDate}
+	if file.Modified.IsZero() {
+		file.Modified = file.Created
+	}
+
+	node, err := s.CreateDocument(ctx, Document{Parent: parent, Name: file.Name, File: file, Reader: bytes.NewReader(b)})
+	if err != nil {
+		return nil, err
+	}
+
+	node.Metadata.SetEmail(props)
+	if err := s.UpdateNode(ctx, node); err != nil {
+		return node, err
+	}
+	return node, nil
+}
+
+// ParseEmail returns the properties of the email message in the RFC 822 format, the body of the message is not read.
+// The encoded words of the headers are decoded, the addresses are formatted as "Name <address>".
+// The malformed address header is kept as the raw text and the malformed date is left zero,
+// the error is returned only when the message is not read.
+func ParseEmail(r io.Reader) (EmailProperties, error) {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return EmailProperties{}, fmt.Errorf("ot: invalid email message: %w", err)
+	}
+
+	var (
+		p   EmailProperties
+		dec mime.WordDecoder
+	)
+	if p.Subject, err = dec.DecodeHeader(m.Header.Get("Subject")); err != nil {
+		p.Subject = m.Header.Get("Subject") // the undecodable subject is kept as is
+	}
+
+	if from := emailAddresses(m.Header, "From"); len(from) > 0 {
+		p.From = from[0]
+	}
+	p.To = emailAddresses(m.Header, "To")
+	p.CC = emailAddresses(m.Header, "Cc")
+	p.SentDate, _ = m.Header.Date() // the malformed date is unknown
+	return p, nil
+}
+
+// emailAddresses returns the addresses of the header, the raw text of the malformed header is returned as is.
+func emailAddresses(h mail.Header, key string) []string {
+	raw := h.Get(key)
+	if raw == "" {
+		return nil
+	}
+
+	list, err := h.AddressList(key)
+	if err != nil {
+		var dec mime.WordDecoder
+		if s, err := dec.DecodeHeader(raw); err == nil {
+			raw = s
+		}
+		return []string{raw}
+	}
+
+	addrs := make([]string, len(list))
+	for i, a := range list {
+		addrs[i] = a.Address
+		if a.Name != "" {
+			addrs[i] = a.Name + " <" + a.Address + ">"
+		}
+	}
+	return addrs
+}
+
+// emailID returns the short identifier of the message: the hash of the header Message-ID
+// or the hash of the message when it has not the header.
+func emailID(msg []byte) string {
+	data := msg
+	if m, err := mail.ReadMessage(bytes.NewReader(msg)); err == nil {
+		if id := m.Header.Get("Message-Id"); id != "" {
+			data = []byte(id)
+		}
+	}
+
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:4])
+}
+
+// maxNameLength is the maximum number of the characters of the name of the node.
+const maxNameLength = 248
+
+// emailName returns name of the document of the message: the subject with the identifier of the message.
+// The colon is not allowed in the names of the nodes, the long subject is truncated to the maximum length of the name.
+func emailName(subject, id string) string {
+	name := strings.TrimSpace(strings.ReplaceAll(subject, ":", "_"))
+	if name == "" {
+		name = "message"
+	}
+
+	suffix := " (" + id + ").eml"
+	if r := []rune(name); len(r)+len([]rune(suffix)) > maxNameLength {
+		name = strings.TrimSpace(string(r[:maxNameLength-len([]rune(suffix))]))
+	}
+	return name + suffix
+}
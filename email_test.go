@@ -0,0 +1,93 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEmail = "From: John Doe <jdoe@example.com>\r\n" +
+	"To: a@example.com, \"Bob\" <b@example.com>\r\n" +
+	"Subject: =?utf-8?q?Re:_report?=\r\n" +
+	"Date: Thu, 2 Jan 2020 03:04:05 +0000\r\n" +
+	"\r\n" +
+	"body of the message\r\n"
+
+func TestParseEmail(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseEmail(strings.NewReader(testEmail))
+	require.Nil(t, err)
+	assert.Equal(t, "Re: report", p.Subject)
+	assert.Equal(t, "John Doe <jdoe@example.com>", p.From)
+	assert.Equal(t, []string{"a@example.com", "Bob <b@example.com>"}, p.To)
+	assert.Nil(t, p.CC)
+	assert.True(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Equal(p.SentDate))
+
+	p, err = ParseEmail(strings.NewReader("From: b@example.com\r\nTo: a@\r\nCc: =?utf-8?q?Bob?= <b@\r\nDate: yesterday\r\n\r\n"))
+	require.Nil(t, err)
+	assert.Equal(t, "b@example.com", p.From)
+	assert.Equal(t, []string{"a@"}, p.To, "the malformed header is kept as is")
+	assert.Equal(t, []string{"Bob <b@"}, p.CC)
+	assert.True(t, p.SentDate.IsZero())
+
+	_, err = ParseEmail(strings.NewReader("invalid header\r\n"))
+	assert.NotNil(t, err)
+}
+
+func TestEmailName(t *testing.T) {
+	t.Parallel()
+
+	a := emailID([]byte("Message-ID: <1@example.com>\r\n\r\nbody"))
+	b := emailID([]byte("Message-ID: <2@example.com>\r\n\r\nbody"))
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, a, emailID([]byte("Message-ID: <1@example.com>\r\n\r\nother body")))
+	assert.NotEqual(t, emailID([]byte("\r\nbody")), emailID([]byte("\r\nother body")))
+
+	assert.Equal(t, "Re_ report (01234567).eml", emailName("Re: report", "01234567"))
+	assert.Equal(t, "message (01234567).eml", emailName(" ", "01234567"))
+
+	name := emailName(strings.Repeat("ж", 300), "01234567")
+	assert.Len(t, []rune(name), maxNameLength)
+	assert.True(t, strings.HasSuffix(name, "ж (01234567).eml"))
+}
+
+func TestSession_CreateEmailDocument(t *testing.T) {
+	t.Parallel()
+
+	node, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "CreateDocument":
+			assert.Equal(t, emailName("Re: report", emailID([]byte(testEmail))), args["name"])
+
+			file := make([]byte, len(testEmail))
+			_, err := io.ReadFull(r, file)
+			require.Nil(t, err)
+			assert.Equal(t, testEmail, string(file))
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=5,'Name'='Re_ report.eml','VersionInfo'=A<1,?,'VersionNum'=1>>>")
+
+		case "UpdateNode":
+			groups := args["node"].(map[string]interface{})["Metadata"].(map[string]interface{})["AttributeGroups"].([]interface{})
+			require.Len(t, groups, 1)
+			assert.Equal(t, GroupEmail, groups[0].(map[string]interface{})["Type"])
+			w.WriteString("A<1,?,'_Status'=0>")
+
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).CreateEmailDocument(context.Background(), 1, strings.NewReader(testEmail))
+	require.Nil(t, err)
+
+	assert.Equal(t, int64(5), node.ID)
+	p, ok := node.Metadata.Email()
+	require.True(t, ok)
+	assert.Equal(t, "Re: report", p.Subject)
+}
@@ -1,50 +1,203 @@
 package ot
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/itcomusic/ot/internal/conn"
+	"github.com/itcomusic/ot/pkg/oscript"
+	"golang.org/x/text/encoding"
 )
 
 var (
 	// errServiceMethod returned by invalid service method.
 	errServiceMethod = errors.New("ot: service/method requester ill-formed")
+	// errArguments returned by arguments of unsupported type.
+	errArguments = errors.New("ot: arguments must be oscript.M, oscript.RawMessage or oscript.MarshalerBuf")
 )
 
-var (
-	defaultPort = ":2099"
-)
+const defaultPort = 2099
 
 // Endpoint represents connection address.
 type Endpoint struct {
-	dialer conn.Dialer
+	dialer  conn.Dialer
+	conns   *conn.Group
+	paths   *pathCache
+	appID   string
+	metrics Metrics
+	retry   RetryPolicy
+	capture CaptureSink
+	drift   *schemaDrift
+	charset encoding.Encoding
+
+	handshakeTimeout time.Duration
 }
 
 // NewEndpoint creates information about connection to the server opentext. No creates connection to server.
-func NewEndpoint(addr string) *Endpoint {
-	if !strings.Contains(addr, ":") {
-		addr += defaultPort
+// The address is host with optional port, IPv6 address with port must be in brackets, e.g. "[::1]:2099".
+// The port 2099 is used when the port is missing, use WithDefaultPort to change it.
+func NewEndpoint(addr string, opts ...Option) *Endpoint {
+	o := options{defaultPort: defaultPort}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	addr = hostPort(addr, o.defaultPort)
+
+	var d conn.Dialer = &conn.Dial{Addr: addr, TLS: o.tls, Timeout: o.dialTimeout, IdleTimeout: o.idleTimeout}
+	if o.dialer != nil {
+		d = o.dialer
+	}
+	if o.poolSize > 0 {
+		d = conn.NewPool(d, o.poolSize)
+	}
+
+	if o.rate > 0 {
+		l := conn.NewLimiter(d, o.rate, o.burst)
+		if m, ok := o.metrics.(WaitMetrics); ok {
+			l.Wait = m.ObserveWait
+		}
+		d = l
+	}
+
+	if o.debug != nil {
+		d = &conn.DialDebug{Dial: d, Out: o.debug, MaxBytes: o.debugOpts.MaxBytes, Sample: o.debugOpts.Sample, JSON: o.debugOpts.JSON}
+	}
+
+	e := &Endpoint{dialer: d, conns: &conn.Group{}, appID: o.appID, metrics: o.metrics, retry: o.retry, capture: o.capture, charset: o.charset, handshakeTimeout: o.handshakeTimeout}
+	if o.pathCache > 0 {
+		e.paths = newPathCache(o.pathCache)
+	}
+
+	if o.drift != nil {
+		e.drift = &schemaDrift{report: o.drift}
+	}
+	return e
+}
+
+// hostPort returns address with the port, the default port is added when the address has no port.
+// The scheme otcs:// of the address is skipped.
+func hostPort(addr string, port int) string {
+	addr = strings.TrimPrefix(addr, "otcs://")
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
 	}
 
-	return &Endpoint{dialer: &conn.Dial{Addr: addr}}
+	host := addr
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Close stops accepting new calls and waits for in-flight calls up to the context deadline.
+// The connections that are still open when the context is done are closed forcibly.
+func (e *Endpoint) Close(ctx context.Context) error {
+	return e.conns.Shutdown(ctx)
+}
+
+// InvalidatePath removes from the path cache the path relative to the root node and all paths under it.
+func (e *Endpoint) InvalidatePath(root int64, path string) {
+	e.paths.remove(pathKey(root, splitPath(path)...))
+}
+
+// InvalidateNode removes from the path cache the node and all paths under it.
+func (e *Endpoint) InvalidateNode(id int64) {
+	e.paths.removeID(id)
 }
 
 // User creates new session with auth authentication.
 func (e *Endpoint) User(username, password string) *Session {
-	return &Session{
-		ep:   e,
-		auth: &auth{enc: fmt.Sprintf("'_UserName'='%s','_UserPassword'='%s'", username, password)},
+	return &Session{ep: e, auth: UserAuth(username, password)}
+}
+
+// Credentials are the fields of the user authentication in the request envelope.
+type Credentials struct {
+	Username          string
+	Password          string
+	Domain            string            // domain of the login, it is sent when is not empty
+	EncryptedPassword bool              // password is encrypted and sent as _UserPasswordEncrypted
+	Extra             map[string]string // additional fields of the envelope
+}
+
+// Login creates new session with the credentials.
+func (e *Endpoint) Login(c Credentials) *Session {
+	return &Session{ep: e, auth: CredentialsAuth(c)}
+}
+
+// Auth is an authentication of the session, it is created by UserAuth, TokenAuth or CredentialsAuth.
+type Auth interface {
+	fmt.Stringer
+	isAuth()
+}
+
+// UserAuth returns authentication by username and password.
+func UserAuth(username, password string) Auth {
+	return CredentialsAuth(Credentials{Username: username, Password: password})
+}
+
+// TokenAuth returns authentication by token.
+func TokenAuth(token string) Auth {
+	return &auth{enc: quote("_Cookie") + "=" + quote(token), token: token}
+}
+
+// CredentialsAuth returns authentication by the credentials.
+func CredentialsAuth(c Credentials) Auth {
+	return &auth{enc: c.encode()}
+}
+
+// encode returns the fields of the envelope.
+func (c Credentials) encode() string {
+	var b strings.Builder
+	field := func(k, v string) {
+		if b.Len() != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(quote(k) + "=" + quote(v))
+	}
+
+	field("_UserName", c.Username)
+	if c.EncryptedPassword {
+		field("_UserPasswordEncrypted", c.Password)
+	} else {
+		field("_UserPassword", c.Password)
+	}
+
+	if c.Domain != "" {
+		field("_DomainName", c.Domain)
+	}
+
+	keys := make([]string, 0, len(c.Extra))
+	for k := range c.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		field(k, c.Extra[k])
 	}
+	return b.String()
+}
+
+// quote returns s as oscript string.
+func quote(s string) string {
+	b, _ := oscript.Marshal(s) // string is always marshaled
+	return string(b)
 }
 
 // Token creates new session with token authentication.
 func (e *Endpoint) Token(token string) *Session {
-	return &Session{
-		ep:   e,
-		auth: &auth{enc: fmt.Sprintf("'_Cookie'='%s'", token)},
-	}
+	return &Session{ep: e, auth: TokenAuth(token)}
+}
+
+// SessionFromToken resumes session by token which was exported by Session.Token, for instance in other process.
+func (e *Endpoint) SessionFromToken(token string) *Session {
+	return e.Token(token)
 }
 
 // dial using for tests.
@@ -54,9 +207,12 @@ func (e Endpoint) dial(d conn.Dialer) *Endpoint {
 }
 
 type auth struct {
-	enc string
+	enc   string
+	token string
 }
 
 func (u *auth) String() string {
 	return u.enc
 }
+
+func (*auth) isAuth() {}
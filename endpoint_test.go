@@ -4,20 +4,24 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"sync"
 	"testing"
-
-	"github.com/itcomusic/ot/internal/client"
+	"time"
 
 	"github.com/itcomusic/ot/internal/conn"
+	"github.com/itcomusic/ot/internal/frame"
 	"github.com/itcomusic/ot/pkg/oscript"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
 )
 
-var statusRequest = []byte{0, 9, 0, 0, 0, 0, 0, 1, 0}
+var statusRequest = frame.EncodeStatus(true)
 
 type mockServer struct {
 	t      *testing.T
@@ -53,7 +57,7 @@ func (s *mockServer) DialContext(_ context.Context) (io.ReadWriteCloser, error)
 		w.Write(statusRequest)
 
 		r := make([]byte, 8)
-		if n, err := server.Read(r); err != nil || n != 8 || !bytes.Equal(r, client.OpenRequest) {
+		if n, err := server.Read(r); err != nil || n != frame.OpenRequestLen || !bytes.Equal(r, frame.Default.OpenRequest()) {
 			s.t.Error("open request server failed")
 			return
 		}
@@ -70,7 +74,7 @@ func (s *mockServer) DialContext(_ context.Context) (io.ReadWriteCloser, error)
 }
 
 func endpoint(t *testing.T, f func(r io.Reader, buf *bufio.Writer, req map[string]interface{})) *Endpoint {
-	return &Endpoint{dialer: &mockServer{t: t, handle: f}}
+	return &Endpoint{dialer: &mockServer{t: t, handle: f}, conns: &conn.Group{}}
 }
 
 func session(t *testing.T, f func(r io.Reader, buf *bufio.Writer, req map[string]interface{})) *Session {
@@ -86,6 +90,12 @@ func TestNewEndpoint(t *testing.T) {
 	}{
 		{addr: "127.0.0.1", exp: "127.0.0.1:2099"},
 		{addr: "127.0.0.1:8080", exp: "127.0.0.1:8080"},
+		{addr: "host", exp: "host:2099"},
+		{addr: "::1", exp: "[::1]:2099"},
+		{addr: "[::1]", exp: "[::1]:2099"},
+		{addr: "[::1]:8080", exp: "[::1]:8080"},
+		{addr: "fe80::1%eth0", exp: "[fe80::1%eth0]:2099"},
+		{addr: "otcs://host:8080", exp: "host:8080"},
 	}
 
 	for i, v := range testCases {
@@ -93,3 +103,307 @@ func TestNewEndpoint(t *testing.T) {
 		assert.Equal(t, v.exp, endp.dialer.(*conn.Dial).Addr, fmt.Sprintf("#%d", i))
 	}
 }
+
+func TestNewEndpoint_DefaultPort(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "host:3000", NewEndpoint("host", WithDefaultPort(3000)).dialer.(*conn.Dial).Addr)
+	assert.Equal(t, "[::1]:3000", NewEndpoint("::1", WithDefaultPort(3000)).dialer.(*conn.Dial).Addr)
+	assert.Equal(t, "host:8080", NewEndpoint("host:8080", WithDefaultPort(3000)).dialer.(*conn.Dial).Addr)
+}
+
+func TestNewEndpoint_Dialer(t *testing.T) {
+	t.Parallel()
+
+	d := &mockServer{t: t, handle: func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=0,'Results'=1>")
+		assert.Nil(t, w.Flush())
+	}}
+
+	ep := NewEndpoint("unused", WithDialer(d), WithPool(1))
+	pool, ok := ep.dialer.(*conn.Pool)
+	require.True(t, ok)
+	assert.Equal(t, d, pool.Dial)
+
+	var res int
+	require.Nil(t, ep.User("u", "p").Call(context.Background(), "service.method", nil, &res))
+	assert.Equal(t, 1, res)
+}
+
+func TestNewEndpoint_Options(t *testing.T) {
+	t.Parallel()
+
+	var w bytes.Buffer
+	cfg := &tls.Config{ServerName: "localhost"}
+	m := &metricsRecorder{}
+	endp := NewEndpoint("127.0.0.1", WithTLS(cfg), WithDialTimeout(time.Second), WithPool(2), WithDebugWriter(&w), WithMetrics(m), WithRetry(RetryPolicy{Attempts: 3}))
+
+	debug, ok := endp.dialer.(*conn.DialDebug)
+	require.True(t, ok)
+	assert.Equal(t, &w, debug.Out)
+
+	pool, ok := debug.Dial.(*conn.Pool)
+	require.True(t, ok)
+	assert.Equal(t, &conn.Dial{Addr: "127.0.0.1:2099", TLS: cfg, Timeout: time.Second}, pool.Dial)
+	assert.Equal(t, m, endp.metrics)
+	assert.Equal(t, RetryPolicy{Attempts: 3}, endp.retry)
+}
+
+type metricsRecorder struct {
+	mu    sync.Mutex
+	calls []string
+	errs  []error
+}
+
+func (m *metricsRecorder) ObserveCall(serviceMethod string, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, serviceMethod)
+	m.errs = append(m.errs, err)
+}
+
+type failDialer struct {
+	n   int
+	err error
+}
+
+func (d *failDialer) DialContext(_ context.Context) (io.ReadWriteCloser, error) {
+	d.n++
+	return nil, d.err
+}
+
+func TestSession_Retry(t *testing.T) {
+	t.Parallel()
+
+	d := &failDialer{err: errors.New("refused")}
+	ss := (&Endpoint{dialer: d, retry: RetryPolicy{Attempts: 3, Backoff: time.Millisecond}}).User("u", "p")
+	assert.Equal(t, d.err, ss.Call(context.Background(), "service.method", nil, nil))
+	assert.Equal(t, 3, d.n)
+}
+
+func TestSession_RetryBudget(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := NewRetryBudget(3, time.Minute)
+	budget.now = func() time.Time { return now }
+
+	d := &failDialer{err: errors.New("refused")}
+	endp := &Endpoint{dialer: d, retry: RetryPolicy{Attempts: 3, Budget: budget}}
+	for _, ss := range []*Session{endp.User("u", "p"), endp.User("u2", "p")} {
+		assert.Equal(t, d.err, ss.Call(context.Background(), "service.method", nil, nil))
+	}
+	assert.Equal(t, 5, d.n, "the second session has one retry left")
+	assert.Equal(t, 0, budget.Remaining())
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, 3, budget.Remaining())
+}
+
+func TestSession_Metrics(t *testing.T) {
+	t.Parallel()
+
+	m := &metricsRecorder{}
+	endp := endpoint(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		buf.WriteString("A<1,?,'_Status'=0,'_apiError'='','_StatusMessage'='','_errMsg'='','Results'='hello'>")
+		assert.Nil(t, buf.Flush())
+	})
+	endp.metrics = m
+
+	var result string
+	require.Nil(t, endp.User("u", "p").Call(context.Background(), "service.method", nil, &result))
+	assert.Equal(t, []string{"service.method"}, m.calls)
+	assert.Equal(t, []error{nil}, m.errs)
+}
+
+func TestEndpoint_Close(t *testing.T) {
+	t.Parallel()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	endp := endpoint(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		close(started)
+		<-release
+		buf.WriteString("A<1,?,'_Status'=0,'_apiError'='','_StatusMessage'='','_errMsg'='','Results'='hello'>")
+		assert.Nil(t, buf.Flush())
+	})
+
+	called := make(chan error)
+	go func() {
+		var result string
+		called <- endp.User("u", "p").Call(context.Background(), "service.method", nil, &result)
+	}()
+	<-started
+
+	closed := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		closed <- endp.Close(ctx)
+	}()
+
+	// waits for closing of the endpoint
+	for {
+		if _, err := endp.conns.DialContext(context.Background(), &failDialer{err: io.EOF}); err == ErrClosed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, ErrClosed, endp.User("u", "p").Call(context.Background(), "service.method", nil, nil))
+	close(release)
+
+	assert.Nil(t, <-called)
+	assert.Nil(t, <-closed)
+}
+
+func TestEndpoint_CloseTimeout(t *testing.T) {
+	t.Parallel()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	endp := endpoint(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		close(started)
+		<-release
+	})
+
+	called := make(chan error)
+	go func() {
+		called <- endp.User("u", "p").Call(context.Background(), "service.method", nil, nil)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, endp.Close(ctx))
+	assert.NotNil(t, <-called)
+}
+
+func TestEndpoint_Login(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		in  Credentials
+		exp string
+	}{
+		{in: Credentials{Username: "u", Password: "p"}, exp: "'_UserName'='u','_UserPassword'='p'"},
+		{
+			in:  Credentials{Username: "u", Password: "enc", Domain: "corp", EncryptedPassword: true, Extra: map[string]string{"_B": "2", "_A": "1"}},
+			exp: "'_UserName'='u','_UserPasswordEncrypted'='enc','_DomainName'='corp','_A'='1','_B'='2'",
+		},
+	}
+
+	for i, v := range testCases {
+		assert.Equal(t, v.exp, NewEndpoint("127.0.0.1").Login(v.in).auth.String(), fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "'_Cookie'='token'", TokenAuth("token").String())
+	assert.Equal(t, `'_Cookie'='a\'b'`, TokenAuth("a'b").String())
+}
+
+type waitRecorder struct {
+	metricsRecorder
+	waits []time.Duration
+}
+
+func (m *waitRecorder) ObserveWait(d time.Duration) {
+	m.mu.Lock()
+	m.waits = append(m.waits, d)
+	m.mu.Unlock()
+}
+
+func TestEndpoint_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	m := &waitRecorder{}
+	endp := NewEndpoint("127.0.0.1", WithRateLimit(20, 2), WithMetrics(m))
+	l, ok := endp.dialer.(*conn.Limiter)
+	require.True(t, ok)
+	l.Dial = &failDialer{}
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err := l.DialContext(ctx)
+		require.Nil(t, err)
+	}
+	assert.True(t, time.Since(start) < 25*time.Millisecond, "burst must not wait")
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := l.DialContext(short)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, err = l.DialContext(ctx)
+	require.Nil(t, err)
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+	require.Len(t, m.waits, 1)
+	assert.True(t, m.waits[0] > 0)
+}
+
+// handshakeServer accepts the open request before reading the request when accept is set,
+// otherwise it never answers like a half-open connection.
+type handshakeServer struct {
+	t      *testing.T
+	accept bool
+}
+
+func (s *handshakeServer) DialContext(_ context.Context) (io.ReadWriteCloser, error) {
+	cl, server := net.Pipe()
+
+	go func() {
+		defer server.Close()
+
+		r := make([]byte, frame.OpenRequestLen)
+		if _, err := io.ReadFull(server, r); err != nil {
+			return
+		}
+
+		if !s.accept {
+			io.Copy(io.Discard, server) // until the client closes the connection
+			return
+		}
+
+		server.Write(statusRequest)
+		var req map[string]interface{}
+		if err := oscript.NewDecoder(server).Decode(&req); err != nil {
+			s.t.Error(err)
+			return
+		}
+		server.Write([]byte("A<1,?,'_Status'=0,'Results'=1>"))
+	}()
+	return cl, nil
+}
+
+func TestSession_HandshakeTimeout(t *testing.T) {
+	t.Parallel()
+
+	ep := NewEndpoint("127.0.0.1", WithHandshakeTimeout(50*time.Millisecond))
+
+	var res int
+	s := ep.dial(&handshakeServer{t: t, accept: true}).User("u", "p")
+	require.Nil(t, s.Call(context.Background(), "service.method", nil, &res))
+	assert.Equal(t, 1, res)
+
+	start := time.Now()
+	s = ep.dial(&handshakeServer{t: t}).User("u", "p")
+	err := s.Call(context.Background(), "service.method", nil, &res)
+	assert.True(t, errors.Is(err, ErrHandshakeTimeout), "%v", err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestEndpoint_Charset(t *testing.T) {
+	t.Parallel()
+
+	endp := NewEndpoint("unused", WithCharset(charmap.ISO8859_1), WithDialer(&mockServer{t: t, handle: func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, map[string]interface{}{"Name": "Отчёт"}, req["Arguments"], "unrepresentable runes are escaped")
+		w.WriteString("A<1,?,'_Status'=0,'Results'='Caf\xe9'>")
+		assert.Nil(t, w.Flush())
+	}}))
+
+	var res string
+	require.Nil(t, endp.User("u", "p").Call(context.Background(), "Service.Method", oscript.M{"Name": "Отчёт"}, &res))
+	assert.Equal(t, "Café", res)
+}
@@ -0,0 +1,273 @@
+package ot
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KMS wraps data keys of the encrypted content by key encryption keys which never leave the KMS.
+type KMS interface {
+	// WrapKey encrypts the data key and returns it with id of the key encryption key.
+	WrapKey(ctx context.Context, key []byte) (wrapped []byte, keyID string, err error)
+	// UnwrapKey decrypts the data key by the key encryption key.
+	UnwrapKey(ctx context.Context, wrapped []byte, keyID string) ([]byte, error)
+}
+
+// Names of the string attributes of the envelope category.
+const (
+	EnvelopeKeyID      = "Key ID"
+	EnvelopeWrappedKey = "Wrapped Key"
+	EnvelopeNonce      = "Nonce"
+)
+
+var errEnvelope = errors.New("ot: node has no envelope category")
+
+const (
+	envelopeChunk     = 64 << 10 // size of the plain chunk
+	envelopeTag       = 16
+	envelopeNonceSize = 7 // random prefix of the nonce, the rest is counter and flag of the last chunk
+)
+
+// Envelope encrypts content on the client, the server stores only the encrypted content and the wrapped key.
+// The content is encrypted by AES-256-GCM in chunks by the data key unique for the document, the data key
+// is wrapped by KMS and kept with the nonce in the category of the node.
+type Envelope struct {
+	KMS KMS
+	// Category is the template of the category with the string attributes EnvelopeKeyID,
+	// EnvelopeWrappedKey and EnvelopeNonce, see Session.GetCategory.
+	Category *Category
+}
+
+// CreateDocument encrypts content and creates the document, the envelope category is added to doc.Metadata.
+// doc.File.Size is the size of the plain content, it is replaced by the size of the encrypted content.
+// The versions added later are not encrypted by the envelope.
+func (e *Envelope) CreateDocument(ctx context.Context, s *Session, doc Document) (*Node, error) {
+	key := make([]byte, 32)
+	nonce := make([]byte, envelopeNonceSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	wrapped, keyID, err := e.KMS.WrapKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := envelopeAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	cat := e.Category.Copy()
+	if err := cat.Set(
+		AttrString(EnvelopeKeyID, keyID),
+		AttrString(EnvelopeWrappedKey, base64.StdEncoding.EncodeToString(wrapped)),
+		AttrString(EnvelopeNonce, base64.StdEncoding.EncodeToString(nonce))); err != nil {
+		return nil, err
+	}
+	doc.Metadata.Categories = append(append([]Category(nil), doc.Metadata.Categories...), *cat)
+
+	body, resend := doc.Body, true
+	if body == nil {
+		body, resend = bodyOf(doc.Reader)
+	}
+
+	plain := doc.File
+	file := *plain
+	file.Size = sealedSize(plain.Size)
+	doc.File = &file
+
+	node, err := s.createDocument(ctx, doc, func() (io.ReadCloser, error) {
+		r, err := body()
+		if err != nil {
+			return nil, err
+		}
+		return &sealReader{r: r, aead: aead, nonce: nonce, remain: plain.Size}, nil
+	}, resend)
+	if err != nil {
+		return nil, err
+	}
+
+	plain.NodeID, plain.Version = file.NodeID, file.Version
+	return node, nil
+}
+
+// ReadFile reads and decrypts content of the document created by CreateDocument, the returned size is the size
+// of the plain content. The content is authenticated by chunks, so w may get the part of the content before
+// the error of the authentication.
+func (e *Envelope) ReadFile(ctx context.Context, s *Session, id, version int64, w io.Writer) (*FileAttr, error) {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cat := node.Metadata.Find(e.Category.DisplayName)
+	if cat == nil {
+		return nil, errEnvelope
+	}
+
+	var keyID, wrapped, nonce string
+	if err := cat.String(EnvelopeKeyID, &keyID); err != nil {
+		return nil, err
+	}
+
+	if err := cat.String(EnvelopeWrappedKey, &wrapped); err != nil {
+		return nil, err
+	}
+
+	if err := cat.String(EnvelopeNonce, &nonce); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("ot: invalid wrapped key: %w", err)
+	}
+
+	nonceBytes, err := base64.StdEncoding.DecodeString(nonce)
+	if err != nil || len(nonceBytes) != envelopeNonceSize {
+		return nil, fmt.Errorf("ot: invalid nonce of the envelope")
+	}
+
+	key, err := e.KMS.UnwrapKey(ctx, wrappedKey, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := envelopeAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ow := &openWriter{w: w, aead: aead, nonce: nonceBytes}
+	fa, err := s.ReadFile(ctx, id, version, ow)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ow.finish(); err != nil {
+		return nil, err
+	}
+	fa.Size = ow.size
+	return fa, nil
+}
+
+func envelopeAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealedSize returns size of the encrypted content, the last chunk is always written even when it is empty.
+func sealedSize(size int64) int64 {
+	return size + (size/envelopeChunk+1)*envelopeTag
+}
+
+// chunkNonce returns nonce of the chunk: prefix, counter and flag of the last chunk.
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, envelopeNonceSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[envelopeNonceSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// sealReader encrypts the plain content of the known size by chunks.
+type sealReader struct {
+	r       io.ReadCloser
+	aead    cipher.AEAD
+	nonce   []byte
+	remain  int64
+	counter uint32
+	buf     []byte // sealed chunk not yet read
+	done    bool
+}
+
+func (r *sealReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		n := int64(envelopeChunk)
+		if r.remain < n {
+			n = r.remain
+		}
+
+		plain := make([]byte, n, n+envelopeTag)
+		if _, err := io.ReadFull(r.r, plain); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+
+		r.remain -= n
+		r.done = n < envelopeChunk
+		r.buf = r.aead.Seal(plain[:0], chunkNonce(r.nonce, r.counter, r.done), plain, nil)
+		r.counter++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *sealReader) Close() error {
+	return r.r.Close()
+}
+
+// openWriter decrypts the sealed chunks, the last chunk is kept until finish.
+type openWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+	buf     []byte
+	size    int64
+}
+
+func (w *openWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) > envelopeChunk+envelopeTag { // the chunk is not last when more content follows
+		if err := w.open(w.buf[:envelopeChunk+envelopeTag], false); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[envelopeChunk+envelopeTag:]
+	}
+	return len(p), nil
+}
+
+func (w *openWriter) open(sealed []byte, last bool) error {
+	plain, err := w.aead.Open(nil, chunkNonce(w.nonce, w.counter, last), sealed, nil)
+	if err != nil {
+		return fmt.Errorf("ot: decrypt chunk %d: %w", w.counter, err)
+	}
+
+	w.counter++
+	w.size += int64(len(plain))
+	_, err = w.w.Write(plain)
+	return err
+}
+
+func (w *openWriter) finish() error {
+	if len(w.buf) == envelopeChunk+envelopeTag { // full chunk is never last
+		return fmt.Errorf("ot: decrypt chunk %d: content is truncated", w.counter+1)
+	}
+	return w.open(w.buf, true)
+}
@@ -0,0 +1,79 @@
+package ot
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seal(t *testing.T, key, nonce, plain []byte) []byte {
+	aead, err := envelopeAEAD(key)
+	require.Nil(t, err)
+
+	sealed, err := ioutil.ReadAll(&sealReader{r: ioutil.NopCloser(bytes.NewReader(plain)), aead: aead, nonce: nonce, remain: int64(len(plain))})
+	require.Nil(t, err)
+	return sealed
+}
+
+func open(key, nonce, sealed []byte, w io.Writer) (int64, error) {
+	aead, err := envelopeAEAD(key)
+	if err != nil {
+		return 0, err
+	}
+
+	ow := &openWriter{w: w, aead: aead, nonce: nonce}
+	for len(sealed) > 0 { // writes by parts as the content is read from the connection
+		n := 1000
+		if n > len(sealed) {
+			n = len(sealed)
+		}
+
+		if _, err := ow.Write(sealed[:n]); err != nil {
+			return 0, err
+		}
+		sealed = sealed[n:]
+	}
+	return ow.size, ow.finish()
+}
+
+func TestEnvelope_SealOpen(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{1}, 32)
+	nonce := bytes.Repeat([]byte{2}, envelopeNonceSize)
+	for _, size := range []int{0, 1, envelopeChunk - 1, envelopeChunk, envelopeChunk + 1, 2*envelopeChunk + 7} {
+		plain := bytes.Repeat([]byte("abcdefg"), size/7+1)[:size]
+		sealed := seal(t, key, nonce, plain)
+		assert.Equal(t, sealedSize(int64(size)), int64(len(sealed)), size)
+
+		var got bytes.Buffer
+		n, err := open(key, nonce, sealed, &got)
+		require.Nil(t, err, size)
+		assert.Equal(t, int64(size), n)
+		assert.Equal(t, string(plain), got.String())
+	}
+}
+
+func TestEnvelope_OpenCorrupted(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{1}, 32)
+	nonce := bytes.Repeat([]byte{2}, envelopeNonceSize)
+	sealed := seal(t, key, nonce, bytes.Repeat([]byte{3}, 2*envelopeChunk))
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[10] ^= 1
+	_, err := open(key, nonce, tampered, ioutil.Discard)
+	assert.NotNil(t, err)
+
+	// the chunks removed from the end are detected by the flag of the last chunk
+	_, err = open(key, nonce, sealed[:envelopeChunk+envelopeTag], ioutil.Discard)
+	assert.NotNil(t, err)
+
+	_, err = open(bytes.Repeat([]byte{9}, 32), nonce, sealed, ioutil.Discard)
+	assert.NotNil(t, err)
+}
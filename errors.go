@@ -4,13 +4,33 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sync"
 
 	"github.com/itcomusic/ot/internal/client"
+	"github.com/itcomusic/ot/internal/conn"
 )
 
 var (
-	regDuplicate = regexp.MustCompile(`^An item with the name '.*' already exists.$`)
 	ErrTokenExpire = fmt.Errorf("ot: token expired")
+	// ErrClosed returned by calls after closing of the endpoint.
+	ErrClosed = conn.ErrClosed
+	// ErrReadOnlySession returned by calls of the read-only session which may change data.
+	ErrReadOnlySession = errors.New("ot: read-only session")
+	// ErrNotModified returned by GetNodeIfModifiedSince when the node has not been modified.
+	ErrNotModified = errors.New("ot: not modified")
+	// ErrDeleteAborted returned by DeleteNodeRecursive when DeleteFunc aborts deleting.
+	ErrDeleteAborted = errors.New("ot: delete aborted")
+	// ErrSignatureUnavailable returned by the signature methods when the content signature module is not installed.
+	ErrSignatureUnavailable = errors.New("ot: content signature is not available")
+	// ErrHandshakeTimeout returned in OpError when the server has not accepted the open request in the time
+	// set by WithHandshakeTimeout.
+	ErrHandshakeTimeout = client.ErrHandshakeTimeout
+	// ErrChecksumUnavailable returned by GetVersionChecksum when the storage provider does not keep checksums.
+	ErrChecksumUnavailable = errors.New("ot: checksum is not available")
+	// ErrNotAttachment returned by RemoveAttachment when the node is not in the attachment folder of the work item.
+	ErrNotAttachment = errors.New("ot: node is not attachment of the work item")
+	// ErrThumbnailNotReady returned by GetThumbnail when the thumbnail is not generated yet.
+	ErrThumbnailNotReady = errors.New("ot: thumbnail is not ready")
 )
 
 type NodeRetrievalError struct {
@@ -26,6 +46,138 @@ type DuplicateNameError struct {
 	*client.OpError
 }
 
+// PartialDataError returned when the server has returned the node with partial data, e.g. the user
+// has not the permission to see the metadata. Node is the partial node, its Metadata is incomplete.
+type PartialDataError struct {
+	Node *Node
+}
+
+func (e *PartialDataError) Error() string {
+	return fmt.Sprintf("ot: node %d has partial data", e.Node.ID)
+}
+
+// ShortTransferError returned when the content of the file is shorter than FileAttr.Size.
+type ShortTransferError = client.ShortTransferError
+
+// ServiceNotFoundError returned when the service or the method is not available on the server.
+type ServiceNotFoundError struct {
+	*client.OpError
+}
+
+// ServerError returned when the call has failed with the status which is not mapped to the specific error.
+type ServerError struct {
+	Status  int
+	Message string   // status message, e.g. DocMan.NodeCreationError
+	Desc    string   // description in the language of the server
+	Codes   []string // codes [E<code>] of the description
+	Reason  Reason
+}
+
+func (e *ServerError) Error() string {
+	return "ot: " + e.Desc
+}
+
+// Reason is a machine-readable reason of the error of the server, it does not depend on the language of the server.
+type Reason string
+
+const (
+	ReasonUnknown         Reason = ""
+	ReasonNotFound        Reason = "NotFound"
+	ReasonDuplicateName   Reason = "DuplicateName"
+	ReasonTokenExpired    Reason = "TokenExpired"
+	ReasonLoginFailed     Reason = "LoginFailed"
+	ReasonServiceNotFound Reason = "ServiceNotFound"
+)
+
+type reasonMessage struct {
+	re     *regexp.Regexp
+	reason Reason
+}
+
+var (
+	reasonMu    sync.RWMutex
+	reasonCodes = map[string]Reason{
+		"662241287": ReasonNotFound,
+	}
+	// reasonMessages are the descriptions which have no distinct code, only the English ones are mapped by default.
+	// The other language packs are mapped by RegisterDuplicateMessage or RegisterErrorCode.
+	reasonMessages = []reasonMessage{
+		{re: regexp.MustCompile(`^An item with (the name '.*'|this name) already exists\.?$`), reason: ReasonDuplicateName},
+	}
+)
+
+// RegisterErrorCode maps the code [E<code>] of the description of the error to the reason.
+func RegisterErrorCode(code string, reason Reason) {
+	reasonMu.Lock()
+	reasonCodes[code] = reason
+	reasonMu.Unlock()
+}
+
+// RegisterErrorMessage maps the description of the error matched by re to the reason, it allows to support
+// the language of the server which is missing in the default mapping. The description is matched without the codes.
+func RegisterErrorMessage(re *regexp.Regexp, reason Reason) {
+	reasonMu.Lock()
+	reasonMessages = append(reasonMessages, reasonMessage{re: re, reason: reason})
+	reasonMu.Unlock()
+}
+
+// RegisterDuplicateMessage maps the site-specific description of the duplicate name to DuplicateNameError,
+// the pattern is the regular expression of the description without the codes, e.g. read from configuration.
+func RegisterDuplicateMessage(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("ot: invalid duplicate message: %w", err)
+	}
+
+	RegisterErrorMessage(re, ReasonDuplicateName)
+	return nil
+}
+
+// reasonOf returns reason of the description, the codes take precedence over the messages.
+func reasonOf(desc string, codes []string) Reason {
+	reasonMu.RLock()
+	defer reasonMu.RUnlock()
+
+	for _, code := range codes {
+		if reason, ok := reasonCodes[code]; ok {
+			return reason
+		}
+	}
+
+	for _, m := range reasonMessages {
+		if m.re.MatchString(desc) {
+			return m.reason
+		}
+	}
+	return ReasonUnknown
+}
+
+// ErrorReason returns reason of the error returned by the calls, ReasonUnknown when the error is not mapped.
+func ErrorReason(err error) Reason {
+	var (
+		dup   *DuplicateNameError
+		nre   *NodeRetrievalError
+		snf   *ServiceNotFoundError
+		serve *ServerError
+	)
+
+	switch {
+	case errors.Is(err, ErrTokenExpire):
+		return ReasonTokenExpired
+	case errors.As(err, &dup):
+		return ReasonDuplicateName
+	case errors.As(err, &nre):
+		if nre.NotFound() {
+			return ReasonNotFound
+		}
+	case errors.As(err, &snf):
+		return ReasonServiceNotFound
+	case errors.As(err, &serve):
+		return serve.Reason
+	}
+	return ReasonUnknown
+}
+
 func errIn(r *client.Response, err error) error {
 	if err != nil {
 		return err
@@ -40,30 +192,27 @@ func errIn(r *client.Response, err error) error {
 		return nil
 	case -2147482642:
 		return ErrTokenExpire
-	case -2147482645, -2147482644, -2147482643, 903102:
-		return errors.New("ot: " + r.StatusMessage)
+	case -2147482645, -2147482644, -2147482643:
+		return &ServerError{Status: r.Status, Message: r.StatusMessage, Desc: r.StatusMessage, Reason: ReasonLoginFailed}
+	case 903102:
+		return &ServiceNotFoundError{OpError: &client.OpError{Service: r.Service, Err: errors.New(r.StatusMessage)}}
 	default:
+		desc, codes := r.ErrCodes()
+		reason := reasonOf(desc, codes)
+
 		switch r.StatusMessage {
 		case "DocMan.NodeRetrievalError":
-			nfound := false
-
-			desc, ecode := r.ErrMessage()
-			switch ecode {
-			case "662241287":
-				nfound = true
-			}
-
-			return &NodeRetrievalError{OpError: &client.OpError{Service: r.Service, Err: errors.New(desc)}, isNotFound: nfound}
+			return &NodeRetrievalError{OpError: &client.OpError{Service: r.Service, Err: errors.New(desc)}, isNotFound: reason == ReasonNotFound}
 
 		case "DocMan.DuplicateName":
 			return &DuplicateNameError{OpError: &client.OpError{Service: r.Service, Err: errors.New(r.Desc)}}
+		}
 
-		case "DocMan.NodeCreationError": // why is it not a DocMan.Duplicate:(
-			if regDuplicate.FindStringIndex(r.Desc) != nil {
-				return &DuplicateNameError{OpError: &client.OpError{Service: r.Service, Err: errors.New(r.Desc)}}
-			}
+		// creating, renaming, moving and copying fail with own status messages instead of DocMan.DuplicateName
+		if reason == ReasonDuplicateName {
+			return &DuplicateNameError{OpError: &client.OpError{Service: r.Service, Err: errors.New(r.Desc)}}
 		}
 
-		return errors.New("ot: " + r.Desc)
+		return &ServerError{Status: r.Status, Message: r.StatusMessage, Desc: r.Desc, Codes: codes, Reason: reason}
 	}
 }
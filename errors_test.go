@@ -3,6 +3,7 @@ package ot
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/itcomusic/ot/internal/client"
@@ -28,7 +29,77 @@ func Test_Errors(t *testing.T) {
 			in:  &client.Response{Status: 903101, StatusMessage: "DocMan.NodeCreationError", Desc: "An item with the name 'name1' already exists.", Service: "service.method"},
 			err: &DuplicateNameError{OpError: &client.OpError{Service: "service.method", Err: errors.New("An item with the name 'name1' already exists.")}},
 		},
+		{
+			in:  &client.Response{Status: 903101, StatusMessage: "DocMan.NodeCreationError", Desc: "failed [E1] [E2]", Service: "service.method"},
+			err: &ServerError{Status: 903101, Message: "DocMan.NodeCreationError", Desc: "failed [E1] [E2]", Codes: []string{"1", "2"}},
+		},
+		{
+			in:  &client.Response{Status: -2147482645, StatusMessage: "Anmeldung fehlgeschlagen", Service: "service.method"},
+			err: &ServerError{Status: -2147482645, Message: "Anmeldung fehlgeschlagen", Desc: "Anmeldung fehlgeschlagen", Reason: ReasonLoginFailed},
+		},
+		{
+			in:  &client.Response{Status: 903102, StatusMessage: "not found service", Service: "service.method"},
+			err: &ServiceNotFoundError{OpError: &client.OpError{Service: "service.method", Err: errors.New("not found service")}},
+		},
 	} {
 		assert.Equal(t, tt.err, errIn(tt.in, nil), fmt.Sprintf("%d", i))
 	}
 }
+
+// restoreReasons restores the registered reasons of the errors when the test finishes.
+func restoreReasons(t *testing.T) {
+	reasonMu.RLock()
+	codes := make(map[string]Reason, len(reasonCodes))
+	for code, reason := range reasonCodes {
+		codes[code] = reason
+	}
+	messages := append([]reasonMessage(nil), reasonMessages...)
+	reasonMu.RUnlock()
+
+	t.Cleanup(func() {
+		reasonMu.Lock()
+		reasonCodes, reasonMessages = codes, messages
+		reasonMu.Unlock()
+	})
+}
+
+func TestErrorReason(t *testing.T) {
+	restoreReasons(t)
+	RegisterErrorMessage(regexp.MustCompile(`^Ya existe un elemento con el nombre '.*'\.$`), ReasonDuplicateName)
+	RegisterErrorCode("1001", ReasonDuplicateName)
+
+	for _, tt := range []struct {
+		in     *client.Response
+		reason Reason
+	}{
+		{in: &client.Response{Status: -2147482642}, reason: ReasonTokenExpired},
+		{in: &client.Response{Status: -2147482643}, reason: ReasonLoginFailed},
+		{in: &client.Response{Status: 903102}, reason: ReasonServiceNotFound},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.NodeRetrievalError", Desc: "Objet introuvable [E662241287]"}, reason: ReasonNotFound},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.NodeRetrievalError", Desc: "Objet introuvable"}, reason: ReasonUnknown},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.DuplicateName"}, reason: ReasonDuplicateName},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.NodeCreationError", Desc: "Un élément nommé 'a' existe déjà."}, reason: ReasonUnknown},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.NodeCreationError", Desc: "Un élément nommé 'a' existe déjà. [E1001]"}, reason: ReasonDuplicateName},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.NodeCreationError", Desc: "Ya existe un elemento con el nombre 'a'."}, reason: ReasonDuplicateName},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.Other", Desc: "Fehler [E662241287]"}, reason: ReasonNotFound},
+		{in: &client.Response{Status: 903101, StatusMessage: "DocMan.Other", Desc: "Fehler"}, reason: ReasonUnknown},
+	} {
+		err := errIn(tt.in, nil)
+		assert.Equal(t, tt.reason, ErrorReason(fmt.Errorf("wrapped: %w", err)), tt.in)
+	}
+	assert.Equal(t, ReasonUnknown, ErrorReason(errors.New("other")))
+}
+
+func TestRegisterDuplicateMessage(t *testing.T) {
+	restoreReasons(t)
+	assert.NotNil(t, RegisterDuplicateMessage("("))
+	assert.Nil(t, RegisterDuplicateMessage(`^Nome '.*' già in uso$`))
+
+	for _, status := range []string{"DocMan.NodeCreationError", "DocMan.NodeUpdateError", "DocMan.NodeMoveError"} {
+		err := errIn(&client.Response{Status: 903101, StatusMessage: status, Desc: "Nome 'a' già in uso [E903101]", Service: "service.method"}, nil)
+		assert.IsType(t, &DuplicateNameError{}, err, status)
+	}
+
+	err := errIn(&client.Response{Status: 903101, StatusMessage: "DocMan.NodeUpdateError", Desc: "An item with this name already exists", Service: "service.method"}, nil)
+	assert.IsType(t, &DuplicateNameError{}, err)
+}
@@ -0,0 +1,98 @@
+package ot
+
+import "sync"
+
+// EventType is a type of the change made through the session.
+type EventType int
+
+const (
+	NodeCreated EventType = iota + 1
+	NodeUpdated
+	NodeDeleted
+	VersionAdded
+	RightsChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case NodeCreated:
+		return "NodeCreated"
+	case NodeUpdated:
+		return "NodeUpdated"
+	case NodeDeleted:
+		return "NodeDeleted"
+	case VersionAdded:
+		return "VersionAdded"
+	case RightsChanged:
+		return "RightsChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a change of the node made through the session.
+type Event struct {
+	Type    EventType
+	NodeID  int64
+	Parent  int64        // set for NodeCreated when it is known
+	Version int64        // number of the added version for VersionAdded
+	Changes []AttrChange // changes of the tracked categories for NodeUpdated by UpdateNode
+}
+
+// EventHandler handles the event, it is called synchronously after the successful call
+// and must not block.
+type EventHandler func(e Event)
+
+// EventBus publishes the events of the sessions to the subscribers.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[int]EventHandler
+	next     int
+}
+
+// NewEventBus creates event bus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[int]EventHandler)}
+}
+
+// Subscribe adds the handler of the events and returns function removing it.
+func (b *EventBus) Subscribe(h EventHandler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.handlers[id] = h
+
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}
+}
+
+// publish calls the handlers, the nil bus publishes nothing.
+func (b *EventBus) publish(e Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := make([]EventHandler, 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+// WithEvents returns session which publishes the changes made through it to the bus, the bus may be shared
+// by many sessions. The changes made by other clients are not published.
+func (s *Session) WithEvents(b *EventBus) *Session {
+	c := s.clone()
+	c.events = b
+	return c
+}
@@ -0,0 +1,55 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_WithEvents(t *testing.T) {
+	t.Parallel()
+
+	content := "content"
+	bus := NewEventBus()
+	var events []Event
+	unsubscribe := bus.Subscribe(func(e Event) { events = append(events, e) })
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "CreateFolder":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=2,'ParentID'=1>>")
+		case "AddVersion":
+			io.ReadFull(r, make([]byte, len(content)))
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'NodeID'=3,'Number'=4>>")
+		case "RenameNode", "DeleteNode", "AddNodeRight":
+			w.WriteString("A<1,?,'_Status'=0>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).WithEvents(bus)
+
+	ctx := context.Background()
+	_, err := s.CreateFolder(ctx, 1, "folder", "", Metadata{})
+	require.Nil(t, err)
+	require.Nil(t, s.AddVersionFile(ctx, &FileAttr{NodeID: 3, Size: int64(len(content))}, strings.NewReader(content)))
+	require.Nil(t, s.RenameNode(ctx, 2, "new"))
+	require.Nil(t, s.AddNodeRight(ctx, 2, NodeRight{}))
+	require.Nil(t, s.DeleteNode(ctx, 2))
+
+	unsubscribe()
+	require.Nil(t, s.DeleteNode(ctx, 3))
+
+	assert.Equal(t, []Event{
+		{Type: NodeCreated, NodeID: 2, Parent: 1},
+		{Type: VersionAdded, NodeID: 3, Version: 4},
+		{Type: NodeUpdated, NodeID: 2},
+		{Type: RightsChanged, NodeID: 2},
+		{Type: NodeDeleted, NodeID: 2},
+	}, events)
+}
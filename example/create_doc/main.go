@@ -14,7 +14,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if err := ot.NewEndpoint("127.0.0.1").
+	node, err := ot.NewEndpoint("127.0.0.1").
 		User("test", "test").
 		CreateDocument(context.Background(), ot.Document{
 			Parent:         math.MaxInt64,
@@ -23,8 +23,9 @@ func main() {
 			File:           attr,
 			VersionControl: false,
 			Reader:         r,
-		}); err != nil {
+		})
+	if err != nil {
 		log.Fatal(err)
 	}
-	log.Printf("created new document %d", attr.NodeID)
+	log.Printf("created new document %d version %d", node.ID, node.VersionInfo.VersionNum)
 }
@@ -0,0 +1,82 @@
+package ot
+
+import (
+	"context"
+)
+
+// NodeExport is a description of the node with its rights and children. It is used to move structure
+// of the folders between servers, it is encoded by oscript.Marshal and decoded by oscript.Unmarshal.
+// Content of the documents is not exported, versions are kept only as descriptors in Node.VersionInfo.
+type NodeExport struct {
+	Node     Node         `oscript:"Node"`
+	Rights   NodeRights   `oscript:"Rights"`
+	Children []NodeExport `oscript:"Children"`
+}
+
+// ExportNode exports the node and all descendants of the node.
+func (s *Session) ExportNode(ctx context.Context, id int64) (*NodeExport, error) {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rights, err := s.GetNodeRights(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := &NodeExport{Node: *node, Rights: *rights}
+	if !node.IsContainer {
+		return exp, nil
+	}
+
+	children, err := s.ListNodes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ch := range children {
+		e, err := s.ExportNode(ctx, ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		exp.Children = append(exp.Children, *e)
+	}
+	return exp, nil
+}
+
+// ImportNode re-creates the exported structure in the parent and returns the created root node.
+// Folders are created by CreateFolder, other nodes by CreateNode. Only the structure is restored: the documents
+// are created without content, since it is not exported. The rights are set as exported, the owner, owner group
+// and public rights are updated, the ACL rights replace the inherited ones, that is why the members
+// and the categories must have the same ids on both servers.
+func (s *Session) ImportNode(ctx context.Context, parentID int64, exp *NodeExport) (*Node, error) {
+	var node *Node
+	if exp.Node.IsFolder() {
+		n, err := s.CreateFolder(ctx, parentID, exp.Node.Name, exp.Node.Comment, exp.Node.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		node = n
+	} else {
+		n := exp.Node
+		n.ID = 0
+		n.Parent = parentID
+		n.VolumeID = 0
+		if err := s.CreateNode(ctx, &n); err != nil {
+			return nil, err
+		}
+		node = &n
+	}
+
+	if err := s.setNodeRights(ctx, node.ID, exp.Rights); err != nil {
+		return nil, err
+	}
+
+	for i := range exp.Children {
+		if _, err := s.ImportNode(ctx, node.ID, &exp.Children[i]); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
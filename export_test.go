@@ -0,0 +1,99 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_ExportImportNode(t *testing.T) {
+	t.Parallel()
+
+	const rights = "A<1,?,'_Status'=0,'Results'=A<1,?,'ACLRights'={A<1,?,'RightID'=1000,'Type'='ACL','Permissions'=A<1,?,'SeePermission'=true>>}," +
+		"'OwnerRight'=A<1,?,'RightID'=7,'Type'='Owner','Permissions'=A<1,?,'SeePermission'=true,'DeletePermission'=true>>>>"
+	exp, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetNode":
+			if args["ID"] == int64(1) {
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='root','Type'='Folder','IsContainer'=true>>")
+			} else {
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=2,'Name'='url','Type'='URL','Comment'='link'>>")
+			}
+		case "GetNodeRights":
+			w.WriteString(rights)
+		case "ListNodes":
+			assert.Equal(t, int64(1), args["parentID"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=2,'Name'='url','Type'='URL'>}>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).ExportNode(context.Background(), 1)
+	require.Nil(t, err)
+
+	require.Len(t, exp.Children, 1)
+	assert.Equal(t, "root", exp.Node.Name)
+	assert.Equal(t, "url", exp.Children[0].Node.Name)
+	assert.Equal(t, []NodeRight{{ID: 1000, Type: "ACL", Perm: Permissions{See: true}}}, exp.Rights.ACLRights)
+	assert.Equal(t, NodeRight{ID: 7, Type: "Owner", Perm: Permissions{See: true, Delete: true}}, exp.Rights.OwnerRight)
+
+	// round-trip serialization
+	b, err := oscript.Marshal(exp)
+	require.Nil(t, err)
+	var got NodeExport
+	require.Nil(t, oscript.Unmarshal(b, &got))
+	assert.Equal(t, exp, &got)
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	node, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		mu.Lock()
+		calls = append(calls, req["ServiceMethod"].(string))
+		mu.Unlock()
+
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "CreateFolder":
+			assert.Equal(t, int64(10), args["parentID"])
+			assert.Equal(t, "root", args["name"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=11,'Name'='root','Type'='Folder'>>")
+		case "CreateNode":
+			n := args["node"].(map[string]interface{})
+			assert.Equal(t, int64(11), n["ParentID"])
+			assert.Equal(t, "url", n["Name"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=12,'Name'='url','Type'='URL'>>")
+		case "GetNodeRights":
+			// the inherited right is replaced by the exported ones
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ACLRights'={A<1,?,'RightID'=2000,'Type'='ACL'>}>>")
+		case "UpdateNodeRight":
+			right := args["nodeRight"].(map[string]interface{})
+			assert.Equal(t, int64(7), right["RightID"])
+			assert.Equal(t, "Owner", right["Type"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		case "AddNodeRight":
+			assert.Equal(t, int64(1000), args["nodeRight"].(map[string]interface{})["RightID"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		case "RemoveNodeRight":
+			assert.Equal(t, int64(2000), args["nodeRight"].(map[string]interface{})["RightID"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).ImportNode(context.Background(), 10, &got)
+	require.Nil(t, err)
+	assert.Equal(t, int64(11), node.ID)
+	assert.Equal(t, []string{
+		"CreateFolder", "GetNodeRights", "UpdateNodeRight", "AddNodeRight", "RemoveNodeRight",
+		"CreateNode", "GetNodeRights", "UpdateNodeRight", "AddNodeRight", "RemoveNodeRight",
+	}, calls)
+}
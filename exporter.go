@@ -0,0 +1,213 @@
+package ot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// ExportManifest is the name of the manifest in the directory of Exporter.
+const ExportManifest = "manifest.jsonl"
+
+// ExportRecord is the record of the manifest of Exporter. The embedded ImportRecord allows to import
+// the manifest by Importer with Dir of the export: the folders are written as the names, Metadata and Rights
+// keep all categories and rights of the node in oscript encoding. The category is the first category of the node
+// with the values of the scalar attributes, the set attributes are kept only in Metadata.
+type ExportRecord struct {
+	ImportRecord
+	ID int64 `json:"id"`
+}
+
+// ExportSummary is the number of the nodes visited by Exporter.
+type ExportSummary struct {
+	Exported int
+	Resumed  int // exported by the previous run
+	Skipped  int // neither containers nor documents, e.g. URL or shortcut
+}
+
+// Exporter exports the subtree into the directory: the manifest ExportManifest of ExportRecord
+// in JSON lines and the contents of the latest versions of the documents in the directory files.
+// The containers are exported as the records without Path, Importer creates them as folders.
+//
+// The record is appended to the manifest after the content is written, so the export stopped by the error
+// is resumed by the next run in the same directory, the nodes of the manifest are skipped.
+type Exporter struct {
+	Session *Session
+	Dir     string
+}
+
+// Export exports the node and all descendants of the node.
+func (ex *Exporter) Export(ctx context.Context, root int64) (ExportSummary, error) {
+	var sum ExportSummary
+	if err := os.MkdirAll(filepath.Join(ex.Dir, "files"), 0o755); err != nil {
+		return sum, err
+	}
+
+	manifest := filepath.Join(ex.Dir, ExportManifest)
+	done, err := exportedNodes(manifest)
+	if err != nil {
+		return sum, fmt.Errorf("ot: export: %w", err)
+	}
+
+	f, err := os.OpenFile(manifest, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	names := make(map[int64][]string) // names of the folders of the container by id
+	err = ex.Session.Walk(ctx, root, func(_ string, node *Node) error {
+		folders := names[node.Parent] // the root is not found
+		if node.IsContainer {
+			names[node.ID] = append(folders[:len(folders):len(folders)], node.Name)
+		}
+
+		if done[node.ID] {
+			sum.Resumed++
+			return nil
+		}
+
+		if !node.IsContainer && !node.IsVersional {
+			sum.Skipped++
+			return nil
+		}
+
+		rec, err := ex.record(ctx, folders, node)
+		if err != nil {
+			return err
+		}
+
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+		sum.Exported++
+		return nil
+	})
+	if err != nil {
+		return sum, err
+	}
+	return sum, f.Close()
+}
+
+// record returns the record of the node, the content of the document is written in the directory files.
+func (ex *Exporter) record(ctx context.Context, folders []string, node *Node) (*ExportRecord, error) {
+	rec := &ExportRecord{ImportRecord: ImportRecord{Folders: folders, Name: node.Name}, ID: node.ID}
+	for _, c := range node.Metadata.OnlyCategories() {
+		rec.Category, _ = c.IDVersion()
+		rec.Values = exportValues(c)
+		break
+	}
+
+	b, err := oscript.Marshal(node.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	rec.Metadata = string(b)
+
+	rights, err := ex.Session.GetNodeRights(ctx, node.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if b, err = oscript.Marshal(rights); err != nil {
+		return nil, err
+	}
+	rec.Rights = string(b)
+
+	if node.IsContainer {
+		return rec, nil
+	}
+
+	rec.Path = filepath.ToSlash(filepath.Join("files", strconv.FormatInt(node.ID, 10)+filepath.Ext(node.Name)))
+	if err := ex.download(ctx, node.ID, filepath.Join(ex.Dir, filepath.FromSlash(rec.Path))); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// download writes the content into the temporary file renamed to name when the content is read completely.
+func (ex *Exporter) download(ctx context.Context, id int64, name string) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	fa, err := ex.Session.ReadFile(ctx, id, 0, f) // 0 is the latest version
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := fa.ApplyTimes(f); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// exportedNodes returns the nodes of the manifest. The incomplete record at the end of the manifest
+// is truncated, it is written again.
+func exportedNodes(name string) (map[int64]bool, error) {
+	done := make(map[int64]bool)
+	b, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var off int
+	for off < len(b) {
+		i := bytes.IndexByte(b[off:], '\n')
+		if i < 0 {
+			break
+		}
+
+		var rec struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(b[off:off+i], &rec); err != nil {
+			break
+		}
+
+		done[rec.ID] = true
+		off += i + 1
+	}
+
+	if off < len(b) {
+		return done, os.Truncate(name, int64(off))
+	}
+	return done, nil
+}
+
+// exportValues returns the values of the category formatted for Importer.SetValues,
+// the set attributes are skipped, Importer.SetValues does not set them.
+func exportValues(c Category) ImportValues {
+	values := make(ImportValues)
+	for _, v := range c.Data {
+		for _, x := range v.Value {
+			switch x := x.(type) {
+			case time.Time:
+				values[v.Description] = append(values[v.Description], x.Format(time.RFC3339))
+			case string, bool, int, int64, float64:
+				values[v.Description] = append(values[v.Description], fmt.Sprint(x))
+			}
+		}
+	}
+	return values
+}
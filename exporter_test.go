@@ -0,0 +1,94 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter(t *testing.T) {
+	t.Parallel()
+
+	const content = "content"
+	var downloads int32
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetNode":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='a/b','IsContainer'=true>>")
+		case "ListNodes":
+			w.WriteString("A<1,?,'_Status'=0,'Results'={" +
+				"A<1,?,'ID'=2,'ParentID'=1,'Name'='a.txt','IsVersionable'=true,'Metadata'=A<1,?,'AttributeGroups'={" +
+				"A<1,?,'Key'='ExternalAtt','Type'='ExternalAtt','Values'={}>," +
+				"A<1,?,'Key'='1234.5','Type'='Category','Values'={" +
+				"A<1,?,'Description'='Integer','Key'='1234.5.4','Values'={5},'_SDOName'='Core.IntegerValue'>," +
+				"A<1,?,'Description'='Set','Key'='1234.5.6','Values'={A<1,?,'Row'=1>},'_SDOName'='Core.StringValue'>}>}>>," +
+				"A<1,?,'ID'=3,'ParentID'=1,'Name'='url'>}>")
+		case "GetNodeRights":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ACLRights'={}>>")
+		case "GetVersionContents":
+			atomic.AddInt32(&downloads, 1)
+			assert.Equal(t, int64(2), args["ID"])
+			fmt.Fprintf(w, "A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=%d>,'_Status'=0>", len(content))
+			w.WriteString(content)
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	dir := t.TempDir()
+	ex := &Exporter{Session: s, Dir: dir}
+	sum, err := ex.Export(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, ExportSummary{Exported: 2, Skipped: 1}, sum)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, ExportManifest))
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var folder, doc ExportRecord
+	require.Nil(t, json.Unmarshal([]byte(lines[0]), &folder))
+	require.Nil(t, json.Unmarshal([]byte(lines[1]), &doc))
+	assert.Equal(t, "a/b", folder.Name)
+	assert.Empty(t, folder.Folders)
+	assert.Equal(t, "files/2.txt", doc.Path)
+	assert.Equal(t, []string{"a/b"}, doc.Folders, "the name with slash is not split")
+	assert.Equal(t, "a.txt", doc.Name)
+	assert.Equal(t, int64(1234), doc.Category)
+	assert.Equal(t, ImportValues{"Integer": {"5"}}, doc.Values, "the set attribute is skipped")
+	assert.Equal(t, int64(2), doc.ID)
+	assert.Contains(t, doc.Metadata, "ExternalAtt")
+	assert.Contains(t, doc.Rights, "DocMan.NodeRights")
+
+	file, err := ioutil.ReadFile(filepath.Join(dir, "files", "2.txt"))
+	require.Nil(t, err)
+	assert.Equal(t, content, string(file))
+
+	// the incomplete record is written again
+	f, err := os.OpenFile(filepath.Join(dir, ExportManifest), os.O_WRONLY, 0)
+	require.Nil(t, err)
+	require.Nil(t, f.Truncate(int64(len(lines[0])+1+10)))
+	require.Nil(t, f.Close())
+
+	sum, err = ex.Export(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, ExportSummary{Exported: 1, Resumed: 1, Skipped: 1}, sum)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&downloads))
+
+	b2, err := ioutil.ReadFile(filepath.Join(dir, ExportManifest))
+	require.Nil(t, err)
+	assert.Equal(t, string(b), string(b2))
+}
@@ -0,0 +1,22 @@
+package ot
+
+import "context"
+
+const (
+	headerAppID       = "_AppID"
+	headerCorrelation = "X-Correlation"
+)
+
+type correlationKey struct{}
+
+// ContextWithCorrelationID returns context with correlation id which is sent in every request made with the context,
+// it allows to attribute changes in the server-side audit.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// correlationID returns correlation id of the context.
+func correlationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
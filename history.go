@@ -0,0 +1,146 @@
+package ot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// AuditAttrChanged is the event of the audit record of the changed attributes of the node.
+const AuditAttrChanged = "Attribute Value Changed"
+
+// AuditRecord is a record of the audit of the node.
+type AuditRecord struct {
+	ID     int64     `oscript:"AuditID"`
+	NodeID int64     `oscript:"ID"`
+	Event  string    `oscript:"AuditStr"`
+	Date   time.Time `oscript:"AuditDate"`
+	User   int64     `oscript:"UserID"`
+}
+
+// GetNodeAuditRecords returns the audit records of the node.
+func (s *Session) GetNodeAuditRecords(ctx context.Context, id int64) ([]AuditRecord, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var records []AuditRecord
+	if err := errIn(c.Exec(docmanService, "GetNodeAuditRecords", s.auth, oscript.M{"ID": id}, &records)); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// AttrKey identifies the attribute of the category regardless of the version of the category.
+type AttrKey struct {
+	Category int64
+	Attr     int
+}
+
+func (k AttrKey) String() string {
+	return fmt.Sprintf("%d.%d", k.Category, k.Attr)
+}
+
+// AttrKey returns key of the attribute.
+func (c *Category) AttrKey(name string) (AttrKey, error) {
+	region, err := c.Region(name)
+	if err != nil {
+		return AttrKey{}, err
+	}
+
+	catID, attrID, _ := ParseAttrRegion(region)
+	return AttrKey{Category: catID, Attr: attrID}, nil
+}
+
+// attrValues returns values of the attribute of the metadata.
+func (m Metadata) attrValues(key AttrKey) ([]interface{}, bool) {
+	for i := range m.Categories {
+		if id, _ := m.Categories[i].IDVersion(); id != key.Category {
+			continue
+		}
+
+		for j := range m.Categories[i].Data {
+			if id, ok := m.Categories[i].Data[j].attrID(); ok && id == key.Attr {
+				return m.Categories[i].Data[j].Value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// AttrHistoryEntry is a value of the attribute since the date.
+type AttrHistoryEntry struct {
+	Date    time.Time
+	User    int64
+	Version int64         // number of the version with the value, zero when the metadata of the node was changed
+	Values  []interface{} // nil when the value is unknown, see AttributeHistory
+}
+
+// AttributeHistory returns the timeline of the values of the attribute from the oldest one: the values of the versions
+// with the creators of the versions and the changes of the metadata of the node after the latest version.
+// The versions keeping the value are skipped. The audit records AuditAttrChanged after the latest version
+// are added in date order, the audit does not keep the values, so only the last record gets the current value
+// of the node and the values of the others are unknown. The current value is dated by the node
+// when there are no such records.
+func (s *Session) AttributeHistory(ctx context.Context, nodeID int64, key AttrKey) ([]AttrHistoryEntry, error) {
+	versions, err := s.ListVersions(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Number < versions[j].Number })
+
+	var history []AttrHistoryEntry
+	add := func(e AttrHistoryEntry) {
+		if n := len(history); n > 0 && reflect.DeepEqual(history[n-1].Values, e.Values) {
+			return
+		}
+		history = append(history, e)
+	}
+
+	var since time.Time // date of the latest version
+	for _, v := range versions {
+		since = v.CreateDate
+		if values, ok := v.Metadata.attrValues(key); ok {
+			user := v.CreatedBy
+			if user == 0 {
+				user = v.Owner
+			}
+			add(AttrHistoryEntry{Date: v.CreateDate, User: user, Version: v.Number, Values: values})
+		}
+	}
+
+	node, err := s.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := node.Metadata.attrValues(key)
+	if !ok || len(history) > 0 && reflect.DeepEqual(history[len(history)-1].Values, values) {
+		return history, nil
+	}
+
+	records, err := s.GetNodeAuditRecords(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []AttrHistoryEntry
+	for _, r := range records {
+		if r.Event == AuditAttrChanged && r.Date.After(since) {
+			changes = append(changes, AttrHistoryEntry{Date: r.Date, User: r.User})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Date.Before(changes[j].Date) })
+
+	if len(changes) == 0 {
+		changes = []AttrHistoryEntry{{Date: node.ModifyDate}}
+	}
+	changes[len(changes)-1].Values = values
+	return append(history, changes...), nil
+}
@@ -0,0 +1,56 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusMetadata(catVersion int, status string) string {
+	return fmt.Sprintf("A<1,?,'AttributeGroups'={A<1,?,'DisplayName'='Contract','Key'='100.%d','Values'={"+
+		"A<1,?,'Description'='Status','Key'='100.%d.2','Values'={'%s'},'_SDOName'='Core.StringValue'>}>}>", catVersion, catVersion, status)
+}
+
+func TestSession_AttributeHistory(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "ListVersions":
+			fmt.Fprintf(w, "A<1,?,'_Status'=0,'Results'={"+
+				"A<1,?,'Number'=3,'Owner'=10,'CreatedBy'=12,'CreateDate'=D/2020/3/1:0:0:0,'Metadata'=%s>,"+
+				"A<1,?,'Number'=1,'Owner'=10,'CreateDate'=D/2020/1/1:0:0:0,'Metadata'=%s>,"+
+				"A<1,?,'Number'=2,'Owner'=11,'CreateDate'=D/2020/2/1:0:0:0,'Metadata'=%s>}>",
+				statusMetadata(2, "signed"), statusMetadata(1, "open"), statusMetadata(1, "open"))
+		case "GetNode":
+			fmt.Fprintf(w, "A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'ModifyDate'=D/2020/4/2:0:0:0,'Metadata'=%s>>", statusMetadata(2, "closed"))
+		case "GetNodeAuditRecords":
+			w.WriteString("A<1,?,'_Status'=0,'Results'={" +
+				"A<1,?,'AuditStr'='Attribute Value Changed','AuditDate'=D/2020/4/1:0:0:0,'UserID'=13>," +
+				"A<1,?,'AuditStr'='Attribute Value Changed','AuditDate'=D/2020/2/15:0:0:0,'UserID'=15>," +
+				"A<1,?,'AuditStr'='Attribute Value Changed','AuditDate'=D/2020/3/15:0:0:0,'UserID'=16>," +
+				"A<1,?,'AuditStr'='Fetch','AuditDate'=D/2020/4/3:0:0:0,'UserID'=14>}>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	cat := Category{DisplayName: "Contract", Key: "100.2", Data: []Value{{Description: "Status", Key: "100.2.2"}}}
+	key, err := cat.AttrKey("Status")
+	require.Nil(t, err)
+	assert.Equal(t, "100.2", key.String())
+
+	history, err := s.AttributeHistory(context.Background(), 1, key)
+	require.Nil(t, err)
+	assert.Equal(t, []AttrHistoryEntry{
+		{Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), User: 10, Version: 1, Values: []interface{}{"open"}},
+		{Date: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), User: 12, Version: 3, Values: []interface{}{"signed"}},
+		{Date: time.Date(2020, 3, 15, 0, 0, 0, 0, time.UTC), User: 16},
+		{Date: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), User: 13, Values: []interface{}{"closed"}},
+	}, history)
+}
@@ -0,0 +1,384 @@
+package ot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// ImportRecord is the record of the manifest of Importer.
+type ImportRecord struct {
+	Line     int          `json:"-"`                  // line of the record in the manifest
+	Path     string       `json:"path"`               // path of the file, relative to Importer.Dir; empty for the folder
+	Folder   string       `json:"folder,omitempty"`   // path of the folder relative to Importer.Root, see CreateFolderPath
+	Folders  []string     `json:"folders,omitempty"`  // names of the folders relative to Importer.Root, used instead of Folder
+	Name     string       `json:"name,omitempty"`     // name of the document, the base of Path when it is empty
+	Category int64        `json:"category,omitempty"` // id of the category of the document
+	Values   ImportValues `json:"values,omitempty"`   // values of the attributes of the category by names
+	Metadata string       `json:"metadata,omitempty"` // metadata in oscript encoding, the category of the record replaces its copy
+	Rights   string       `json:"rights,omitempty"`   // NodeRights in oscript encoding set on the created node
+}
+
+// folderNames returns the names of the folders of the record, the names of Folders may contain slash.
+func (rec *ImportRecord) folderNames() []string {
+	if len(rec.Folders) > 0 {
+		return rec.Folders
+	}
+	return splitPath(rec.Folder)
+}
+
+// ImportValues are the values of the attributes by names of the attributes. The values are converted
+// to the types of the attributes, the dates are parsed by Importer.Layouts.
+type ImportValues map[string][]string
+
+// UnmarshalJSON implements json.Unmarshaler, the value of the attribute is a scalar or an array of the scalars.
+func (v *ImportValues) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+
+	values := make(ImportValues, len(m))
+	for name, x := range m {
+		items, ok := x.([]interface{})
+		if !ok {
+			items = []interface{}{x}
+		}
+
+		for _, item := range items {
+			switch item := item.(type) {
+			case nil:
+			case string, json.Number, bool:
+				values[name] = append(values[name], fmt.Sprint(item))
+			default:
+				return fmt.Errorf("attribute \"%s\": invalid value %T", name, item)
+			}
+		}
+	}
+	*v = values
+	return nil
+}
+
+// ImportResult is the record of the results manifest of Importer, it is written as JSON line.
+type ImportResult struct {
+	Line   int    `json:"line"`
+	Path   string `json:"path"`
+	NodeID int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportSummary is the number of the imported records of the manifest.
+type ImportSummary struct {
+	Total  int
+	Failed int
+}
+
+// Importer creates the documents of the local files described by the manifest. The folders of the records
+// are created by CreateFolderPath, the category of the record is applied to the document with the values of the record.
+// The record without Path creates the folder Name in Folder with the category applied to the missing folders.
+// The metadata and the rights of the record, e.g. written by Exporter, are applied to the created node.
+// The failed record does not stop the import, its error is written in the results manifest.
+type Importer struct {
+	Session *Session
+	Root    int64  // root of the folders of the records
+	Dir     string // base directory of the relative paths of the files
+
+	// Layouts are the layouts of the dates of the values, the ISO layouts are used when it is empty, see DateLayouts.
+	Layouts []string
+
+	// Map sets the values of the record in the copy of the category template, SetValues is used when it is nil.
+	// The typed values may be set by MapStruct.
+	Map func(rec *ImportRecord, cat *Category) error
+}
+
+// ImportCSV imports the manifest in the CSV format with the header. The columns path, folder, name and category
+// are the fields of ImportRecord, other columns are the attributes, the repeated column is multi-valued attribute
+// and the empty cell is skipped. The results are written in results as JSON lines.
+func (im *Importer) ImportCSV(ctx context.Context, manifest io.Reader, results io.Writer) (ImportSummary, error) {
+	r := csv.NewReader(manifest)
+	header, err := r.Read()
+	if err != nil {
+		return ImportSummary{}, fmt.Errorf("ot: import: invalid header: %w", err)
+	}
+
+	return im.run(ctx, func() (*ImportRecord, error) {
+		row, err := r.Read()
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return &ImportRecord{Line: perr.Line}, err
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		rec := &ImportRecord{Line: line, Values: ImportValues{}}
+		for i, col := range header {
+			switch v := row[i]; col {
+			case "path":
+				rec.Path = v
+			case "folder":
+				rec.Folder = v
+			case "name":
+				rec.Name = v
+			case "category":
+				if v == "" {
+					continue
+				}
+
+				if rec.Category, err = strconv.ParseInt(v, 10, 64); err != nil {
+					return rec, fmt.Errorf("invalid category %q", v)
+				}
+			default:
+				if v != "" {
+					rec.Values[col] = append(rec.Values[col], v)
+				}
+			}
+		}
+		return rec, nil
+	}, results)
+}
+
+// ImportJSONL imports the manifest of ImportRecord in JSON lines, the empty lines are skipped.
+// The length of the line is not limited, e.g. by the exported metadata. The results are written in results as JSON lines.
+func (im *Importer) ImportJSONL(ctx context.Context, manifest io.Reader, results io.Writer) (ImportSummary, error) {
+	r := bufio.NewReader(manifest)
+
+	var line int
+	return im.run(ctx, func() (*ImportRecord, error) {
+		for {
+			b, err := r.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+
+			if len(b) == 0 && err == io.EOF {
+				return nil, io.EOF
+			}
+
+			line++
+			if len(bytes.TrimSpace(b)) == 0 {
+				continue
+			}
+
+			rec := &ImportRecord{}
+			err = json.Unmarshal(b, rec)
+			rec.Line = line
+			return rec, err
+		}
+	}, results)
+}
+
+// run imports the records returned by next until io.EOF. The record with the error is the invalid record of the manifest,
+// the error without the record stops the import.
+func (im *Importer) run(ctx context.Context, next func() (*ImportRecord, error), results io.Writer) (ImportSummary, error) {
+	var (
+		sum     ImportSummary
+		enc     = json.NewEncoder(results)
+		folders = make(map[string]int64)
+		cats    = make(map[int64]*Category)
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return sum, err
+		}
+
+		rec, err := next()
+		if rec == nil {
+			if err == io.EOF {
+				return sum, nil
+			}
+			return sum, fmt.Errorf("ot: import: %w", err)
+		}
+
+		res := ImportResult{Line: rec.Line, Path: rec.Path}
+		if err == nil {
+			res.NodeID, err = im.importRecord(ctx, rec, folders, cats)
+		}
+
+		sum.Total++
+		if err != nil {
+			sum.Failed++
+			res.Error = err.Error()
+		}
+
+		if err := enc.Encode(res); err != nil {
+			return sum, err
+		}
+	}
+}
+
+func (im *Importer) importRecord(ctx context.Context, rec *ImportRecord, folders map[string]int64, cats map[int64]*Category) (int64, error) {
+	var metadata Metadata // the empty metadata inherits the categories of the parent, otherwise they are not applied
+	if rec.Metadata != "" {
+		if err := oscript.Unmarshal([]byte(rec.Metadata), &metadata); err != nil {
+			return 0, fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+
+	if rec.Category != 0 {
+		tmpl, ok := cats[rec.Category]
+		if !ok {
+			var err error
+			if tmpl, err = im.Session.GetCategory(ctx, rec.Category); err != nil {
+				return 0, err
+			}
+			cats[rec.Category] = tmpl
+		}
+
+		cat := tmpl.Copy()
+		mapValues := im.Map
+		if mapValues == nil {
+			mapValues = im.SetValues
+		}
+
+		if err := mapValues(rec, cat); err != nil {
+			return 0, err
+		}
+		metadata.setCategory(*cat)
+	}
+
+	names := rec.folderNames()
+	if rec.Path == "" { // folder
+		names = append(names[:len(names):len(names)], rec.Name)
+		folder, err := im.Session.createFolderNames(ctx, im.Root, names, metadata)
+		if err != nil {
+			return 0, err
+		}
+		folders[folderKey(names)] = folder.ID
+		return folder.ID, im.setRights(ctx, rec, folder.ID)
+	}
+
+	parent, ok := folders[folderKey(names)]
+	if !ok {
+		folder, err := im.Session.createFolderNames(ctx, im.Root, names, Metadata{})
+		if err != nil {
+			return 0, err
+		}
+		parent = folder.ID
+		folders[folderKey(names)] = parent
+	}
+
+	name := rec.Path
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(im.Dir, name)
+	}
+
+	f, file, err := OpenFile(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	name = rec.Name
+	if name == "" {
+		name = filepath.Base(rec.Path)
+	}
+
+	node, err := im.Session.CreateDocument(ctx, Document{Parent: parent, Name: name, Metadata: metadata, File: file, Reader: f})
+	if err != nil {
+		return 0, err
+	}
+	return node.ID, im.setRights(ctx, rec, node.ID)
+}
+
+// folderKey returns the key of the folder names, the names may contain slash.
+func folderKey(names []string) string {
+	return fmt.Sprintf("%q", names)
+}
+
+// setCategory replaces the category with the same id or appends it.
+func (m *Metadata) setCategory(cat Category) {
+	id, _ := cat.IDVersion()
+	for i, c := range m.Categories {
+		if c.IsCategory() {
+			if cid, _ := c.IDVersion(); cid == id {
+				m.Categories[i] = cat
+				return
+			}
+		}
+	}
+	m.Categories = append(m.Categories, cat)
+}
+
+// setRights sets the rights of the record on the node: the owner, owner group and public rights are updated
+// and the access control list of the node is replaced.
+func (im *Importer) setRights(ctx context.Context, rec *ImportRecord, id int64) error {
+	if rec.Rights == "" {
+		return nil
+	}
+
+	var rights NodeRights
+	if err := oscript.Unmarshal([]byte(rec.Rights), &rights); err != nil {
+		return fmt.Errorf("invalid rights: %w", err)
+	}
+
+	return im.Session.setNodeRights(ctx, id, rights)
+}
+
+// SetValues sets the values of the record in the category, the values are converted to the types of the attributes.
+func (im *Importer) SetValues(rec *ImportRecord, cat *Category) error {
+	layouts := im.Layouts
+	if len(layouts) == 0 {
+		layouts = isoLayouts
+	}
+
+	for name, values := range rec.Values {
+		var attr *Value
+		for i := range cat.Data {
+			if cat.Data[i].Description == name {
+				attr = &cat.Data[i]
+				break
+			}
+		}
+
+		if attr == nil {
+			return fmt.Errorf("not found attribute \"%s\"", name)
+		}
+
+		if len(values) > 1 && attr.MaxValues == 1 {
+			return fmt.Errorf("attribute \"%s\" is not multi-valued", name)
+		}
+
+		v := Value{Description: name, Value: make([]interface{}, len(values)), Type: StringType}
+		for i, s := range values {
+			v.Value[i] = s
+		}
+
+		var (
+			cv  Value
+			err error
+		)
+		switch attr.Type {
+		case TimeType:
+			cv, err = v.ToTime(time.UTC, layouts...)
+		case BoolType:
+			cv, err = v.convert(BoolType, func(x interface{}) (interface{}, error) {
+				return strconv.ParseBool(strings.TrimSpace(x.(string)))
+			})
+		default:
+			cv, err = v.Convert(attr.Type)
+		}
+
+		if err != nil {
+			return err
+		}
+		attr.Value = cv.Value
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportValues_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var v ImportValues
+	require.Nil(t, json.Unmarshal([]byte(`{"String":"a","Integer":5,"Boolean":true,"Tags":["x",null,"y"]}`), &v))
+	assert.Equal(t, ImportValues{"String": {"a"}, "Integer": {"5"}, "Boolean": {"true"}, "Tags": {"x", "y"}}, v)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"String":{"a":1}}`), &v))
+}
+
+func TestImporter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("content"), 0o600))
+
+	var ids int64
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetNodeByName":
+			if args["name"] == "sub" {
+				assert.Equal(t, int64(7), args["parentID"])
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=8,'Name'='sub'>>")
+				break
+			}
+			assert.Equal(t, "inbox", args["name"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=7,'Name'='inbox'>>")
+
+		case "GetCategoryTemplate":
+			b, err := ioutil.ReadFile("testdata/get-category")
+			require.Nil(t, err)
+			w.Write(b)
+
+		case "CreateDocument":
+			assert.Equal(t, int64(7), args["parentID"])
+			file := make([]byte, len("content"))
+			_, err := io.ReadFull(r, file)
+			require.Nil(t, err)
+
+			groups := args["metadata"].(map[string]interface{})["AttributeGroups"].([]interface{})
+			require.Len(t, groups, 1)
+			values := map[string]interface{}{}
+			for _, v := range groups[0].(map[string]interface{})["Values"].([]interface{}) {
+				v := v.(map[string]interface{})
+				values[v["Description"].(string)] = v["Values"].([]interface{})[0]
+			}
+			assert.Equal(t, "report", values["String"])
+			assert.Equal(t, int64(5), values["Integer"])
+			assert.Equal(t, false, values["Boolean"])
+			assert.True(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Equal(values["Date"].(time.Time)))
+
+			fmt.Fprintf(w, "A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=%d,'VersionInfo'=A<1,?,'VersionNum'=1>>>", 10+atomic.AddInt64(&ids, 1))
+
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+	im := &Importer{Session: s, Root: 1, Dir: dir}
+
+	var results strings.Builder
+	sum, err := im.ImportCSV(context.Background(), strings.NewReader(
+		"path,folder,category,String,Integer,Boolean,Date\n"+
+			"a.txt,inbox,1,report,5,false,2020-01-02\n"+
+			"b.txt,inbox,1,report,5,false,2020-01-02\n"+
+			"a.txt,inbox,x,report,5,false,2020-01-02\n"), &results)
+	require.Nil(t, err)
+	assert.Equal(t, ImportSummary{Total: 3, Failed: 2}, sum)
+
+	lines := strings.Split(strings.TrimSpace(results.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, `{"line":2,"path":"a.txt","id":11}`, lines[0])
+	assert.Contains(t, lines[1], `"line":3,"path":"b.txt","error":`)
+	assert.Equal(t, `{"line":4,"path":"a.txt","error":"invalid category \"x\""}`, lines[2])
+
+	results.Reset()
+	sum, err = im.ImportJSONL(context.Background(), strings.NewReader(
+		`{"path":"a.txt","folder":"inbox","category":1,"values":{"String":"report","Integer":5,"Boolean":false,"Date":"2020-01-02"}}`+"\n\n"+
+			`{"path":"a.txt","folder":"inbox","category":1,"values":{"Missing":"x"}}`+"\n"+
+			`{"folder":"inbox","name":"sub"}`+"\n"), &results)
+	require.Nil(t, err)
+	assert.Equal(t, ImportSummary{Total: 3, Failed: 1}, sum)
+	assert.Equal(t, `{"line":1,"path":"a.txt","id":12}`+"\n"+
+		`{"line":3,"path":"a.txt","error":"not found attribute \"Missing\""}`+"\n"+
+		`{"line":4,"path":"","id":8}`+"\n", results.String())
+}
+
+func TestImporter_ExportRecord(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("content"), 0o600))
+
+	var calls []string
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		calls = append(calls, req["ServiceMethod"].(string))
+		switch req["ServiceMethod"] {
+		case "GetNodeByName":
+			assert.Equal(t, "a/b", args["name"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+
+		case "CreateFolder":
+			assert.Equal(t, "a/b", args["name"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=7,'Name'='a/b'>>")
+
+		case "CreateDocument":
+			assert.Equal(t, int64(7), args["parentID"])
+			file := make([]byte, len("content"))
+			_, err := io.ReadFull(r, file)
+			require.Nil(t, err)
+
+			groups := args["metadata"].(map[string]interface{})["AttributeGroups"].([]interface{})
+			require.Len(t, groups, 1)
+			assert.Equal(t, GroupExternal, groups[0].(map[string]interface{})["Type"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=11,'VersionInfo'=A<1,?,'VersionNum'=1>>>")
+
+		case "GetNodeRights":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ACLRights'={A<1,?,'RightID'=100,'Type'='ACL'>,A<1,?,'RightID'=200,'Type'='ACL'>}>>")
+
+		case "UpdateNodeRight", "AddNodeRight", "RemoveNodeRight":
+			right := args["nodeRight"].(map[string]interface{})
+			calls[len(calls)-1] += fmt.Sprint(" ", right["RightID"])
+			w.WriteString("A<1,?,'_Status'=0>")
+
+		default:
+			t.Errorf("unexpected method %v", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	rec := ImportRecord{
+		Path:     "a.txt",
+		Folders:  []string{"a/b"},
+		Metadata: "A<1,?,'AttributeGroups'={A<1,?,'Key'='ExternalAtt','Type'='ExternalAtt','Values'={}>},'_SDOName'='DocMan.Metadata'>",
+		Rights: "A<1,?,'ACLRights'={A<1,?,'RightID'=200,'Type'='ACL'>,A<1,?,'RightID'=300,'Type'='ACL'>}," +
+			"'OwnerRight'=A<1,?,'RightID'=1000,'Type'='Owner'>,'_SDOName'='DocMan.NodeRights'>",
+	}
+	b, err := json.Marshal(rec)
+	require.Nil(t, err)
+
+	var results strings.Builder
+	sum, err := (&Importer{Session: s, Root: 1, Dir: dir}).ImportJSONL(context.Background(), strings.NewReader(string(b)), &results)
+	require.Nil(t, err)
+	assert.Equal(t, ImportSummary{Total: 1}, sum)
+	assert.Equal(t, `{"line":1,"path":"a.txt","id":11}`+"\n", results.String())
+	assert.Equal(t, []string{
+		"GetNodeByName", "CreateFolder", "CreateDocument", "GetNodeRights",
+		"UpdateNodeRight 1000", "UpdateNodeRight 200", "AddNodeRight 300", "RemoveNodeRight 100",
+	}, calls)
+}
+
+func TestImporter_ImportJSONLLongLine(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "GetNode", req["ServiceMethod"])
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='root'>>")
+		assert.Nil(t, w.Flush())
+	})
+
+	var results strings.Builder
+	long := `{"path":"missing.txt","values":{"String":"` + strings.Repeat("x", 2<<20) + `"}}`
+	sum, err := (&Importer{Session: s, Root: 1, Dir: t.TempDir()}).ImportJSONL(context.Background(),
+		strings.NewReader(long+"\n"+`{"path":"missing.txt"`), &results)
+	require.Nil(t, err)
+	assert.Equal(t, ImportSummary{Total: 2, Failed: 2}, sum)
+
+	lines := strings.Split(strings.TrimSpace(results.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"line":1,"path":"missing.txt","error":`)
+	assert.Contains(t, lines[1], `"line":2,"path":"","error":"unexpected end of JSON input"`)
+}
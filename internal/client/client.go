@@ -2,24 +2,28 @@ package client
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"reflect"
+	"runtime/debug"
+	"time"
 
+	"github.com/itcomusic/ot/internal/frame"
 	"github.com/itcomusic/ot/pkg/oscript"
+	"golang.org/x/text/encoding"
 )
 
 var (
 	// errUnexpectedEOF returned by unexpected closed request.
 	errUnexpectedEOF = errors.New("to check trace file in opentext")
-	// errOpenRequest returned by not accepting open request.
-	errOpenRequest = errors.New("protocol error")
+	// ErrHandshakeTimeout returned when the server has not answered the open request in time.
+	ErrHandshakeTimeout = errors.New("handshake timeout")
 )
 
-// OpenRequest is a expectation bytes on first place in every request service.
-var OpenRequest = []byte{1, 8, 1, 3, 0, 0, 2, 4}
-
 // An OpError is the error type usually returned by functions in the ot package.
 // It describes the service method, and text of an error.
 type OpError struct {
@@ -33,92 +37,404 @@ func (e *OpError) Error() string {
 	return fmt.Sprintf("ot: %s %s", e.Service, e.Err)
 }
 
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// state is a stage of the call, the client serves one request: Write, optionally WriteFrom,
+// then one of the reads, ReadFile is followed by ReadTo. The decoder buffers the connection,
+// so the client is not reused for the next request.
+type state int
+
+const (
+	stateIdle    state = iota
+	stateWritten       // the request is written, the response is not read
+	stateFile          // the response with the file attributes is read, the content is not read
+	stateRead          // the response is read
+	stateClosed
+)
+
+var stateNames = [...]string{"idle", "written", "file", "read", "closed"}
+
+func (s state) String() string {
+	return stateNames[s]
+}
+
+// ProtocolStateError returned when the method of the client is called out of the order of the call,
+// e.g. Read before Write or second Write before the response is read.
+type ProtocolStateError struct {
+	Op    string
+	State string
+}
+
+func (e *ProtocolStateError) Error() string {
+	return fmt.Sprintf("ot: client: %s in state %s", e.Op, e.State)
+}
+
+// Capture is the messages of the call, the content of the files is not included.
+type Capture struct {
+	Service  string
+	Start    time.Time
+	Request  []byte // the request as written to the connection
+	Response []byte // the response as read from the connection in UTF-8, nil when it has not been read
+}
+
+// CaptureFunc is called on close of the client with the messages of the call.
+type CaptureFunc func(c *Capture)
+
+// teeWriter writes to w and copies the written bytes to buf when it is set.
+type teeWriter struct {
+	w   io.Writer
+	buf *bytes.Buffer
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	if t.buf != nil {
+		t.buf.Write(p)
+	}
+	return t.w.Write(p)
+}
+
+// ObserveFunc is called on close of the client with the called service, duration and error of the call.
+type ObserveFunc func(service string, d time.Duration, err error)
+
 type Client struct {
 	conn    io.ReadWriteCloser
 	dec     *oscript.Decoder
 	enc     *oscript.Encoder
 	encBuf  *bufio.Writer
+	tee     *teeWriter
 	opened  bool
 	service string
+	framing frame.Framing
+
+	handshakeTimeout time.Duration
+	accepted         bool // the status of the open request is already read
+
+	start   time.Time
+	err     error
+	observe ObserveFunc
+	guard   func(service, method string) error
+	header  map[string]string
+	state   state
+	capture CaptureFunc
+	unknown func(oscript.UnknownField)
+	resp    []byte // captured response
+
+	ctx  context.Context
+	done chan struct{}
 }
 
 func New(conn io.ReadWriteCloser) *Client {
 	encBuf := bufio.NewWriter(conn)
+	tee := &teeWriter{w: encBuf}
+	enc := oscript.NewEncoder(tee)
+	enc.RecoverPanics()
 
 	return &Client{
-		conn:   conn,
-		dec:    oscript.NewDecoder(conn),
-		enc:    oscript.NewEncoder(encBuf),
-		encBuf: encBuf,
+		conn:    conn,
+		dec:     oscript.NewDecoder(conn),
+		enc:     enc,
+		encBuf:  encBuf,
+		tee:     tee,
+		framing: frame.Default,
 	}
 }
 
-func (c *Client) Write(service, method string, auth fmt.Stringer, args oscript.M) error {
+// SetFraming sets variant of the framing used instead of the default one.
+func (c *Client) SetFraming(f frame.Framing) {
+	c.framing = f
+}
+
+// SetHandshakeTimeout makes the open request a separate exchange which fails with ErrHandshakeTimeout
+// when the server does not answer within d, the request is sent only after the open request is accepted.
+// By default the open request is sent together with the request and the status is awaited with the response.
+func (c *Client) SetHandshakeTimeout(d time.Duration) {
+	c.handshakeTimeout = d
+}
+
+// handshake sends the open request and reads the status within the handshake timeout.
+func (c *Client) handshake() error {
+	if _, err := c.conn.Write(c.framing.OpenRequest()); err != nil {
+		return err
+	}
+
+	t := time.AfterFunc(c.handshakeTimeout, func() { c.conn.Close() })
+	status := make([]byte, c.framing.StatusLen())
+	_, err := io.ReadFull(c.conn, status)
+	if !t.Stop() {
+		return ErrHandshakeTimeout
+	}
+
+	if err != nil {
+		return err
+	}
+	return c.framing.DecodeStatus(status)
+}
+
+// Observe sets f which is called on close of the client.
+func (c *Client) Observe(f ObserveFunc) {
+	c.observe = f
+}
+
+// Capture sets f which is called on close of the client with the messages of the call.
+// The messages are captured byte-exact besides the open request and the status, the content of the files is not captured.
+func (c *Client) Capture(f CaptureFunc) {
+	c.capture = f
+	c.tee.buf = new(bytes.Buffer)
+}
+
+// OnUnknownField sets f which is called for the keys of the results which do not match any field of the struct
+// they are decoded to. The keys of the response itself are not reported, they include the named outputs.
+func (c *Client) OnUnknownField(f func(oscript.UnknownField)) {
+	c.unknown = func(uf oscript.UnknownField) {
+		if uf.Type != responseType {
+			f(uf)
+		}
+	}
+	c.dec.OnUnknownField(c.unknown)
+}
+
+var responseType = reflect.TypeOf(Response{})
+
+// SetCharset sets the charset of the server, the strings of the messages are transcoded from and to UTF-8.
+func (c *Client) SetCharset(cs encoding.Encoding) {
+	c.dec.SetCharset(cs)
+	c.enc.SetCharset(cs)
+}
+
+// Guard sets f which is checked before writing of every request, the request is not sent if f returns error.
+func (c *Client) Guard(f func(service, method string) error) {
+	c.guard = f
+}
+
+// SetHeader sets value of the key which is sent in every request besides arguments.
+func (c *Client) SetHeader(key, value string) {
+	if c.header == nil {
+		c.header = make(map[string]string)
+	}
+	c.header[key] = value
+}
+
+// Watch closes the connection when the context is done before Close, it aborts the transfer in progress.
+// The errors of the aborted call are replaced by the error of the context.
+func (c *Client) Watch(ctx context.Context) {
+	if ctx.Done() == nil {
+		return
+	}
+
+	c.ctx = ctx
+	c.done = make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.conn.Close()
+		case <-c.done:
+		}
+	}()
+}
+
+// transit changes the state of the call to next if the current state is one of from.
+func (c *Client) transit(op string, next state, from ...state) error {
+	for _, s := range from {
+		if c.state == s {
+			c.state = next
+			return nil
+		}
+	}
+	return &ProtocolStateError{Op: op, State: c.state.String()}
+}
+
+// fail remembers the error of the call for observer.
+func (c *Client) fail(err error) error {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		err = c.ctx.Err()
+	}
+
+	if c.err == nil {
+		c.err = err
+	}
+	return err
+}
+
+func (c *Client) Write(service, method string, auth fmt.Stringer, args interface{}) error {
+	if c.guard != nil {
+		if err := c.guard(service, method); err != nil {
+			return err
+		}
+	}
+
+	if err := c.transit("Write", stateWritten, stateIdle); err != nil {
+		return err
+	}
+
 	c.service = service + "." + method
+	c.start = time.Now()
 
-	if _, err := c.encBuf.Write(OpenRequest); err != nil {
-		return &OpError{Service: c.service, Err: err}
+	if c.handshakeTimeout > 0 {
+		if err := c.handshake(); err != nil {
+			return c.fail(&OpError{Service: c.service, Err: err})
+		}
+		c.accepted = true
+	} else if _, err := c.encBuf.Write(c.framing.OpenRequest()); err != nil {
+		return c.fail(&OpError{Service: c.service, Err: err})
 	}
 
-	if err := c.enc.Encode(&request{
+	if err := c.encode(&request{
 		Service: service,
 		Method:  method,
 		Auth:    auth,
+		Header:  c.header,
 		Args:    args,
 	}); err != nil {
-		return &OpError{Service: c.service, Err: err}
+		return c.fail(&OpError{Service: c.service, Err: err})
 	}
 
 	if err := c.encBuf.Flush(); err != nil {
-		return &OpError{Service: c.service, Err: err}
+		return c.fail(&OpError{Service: c.service, Err: err})
 	}
 
 	return nil
 }
 
-func (c *Client) WriteFrom(r io.Reader) error {
-	if _, err := io.Copy(c.conn, r); err != nil {
-		return &OpError{Service: c.service, Err: err}
+// encode encodes the request. The panic of the marshaler is returned as oscript.MarshalerError of the type
+// of the marshaler, other panics of the encoding as oscript.PanicError. The partially encoded request is discarded
+// and the connection is closed, so it is never sent.
+func (c *Client) encode(req *request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &oscript.PanicError{Value: r, Stack: debug.Stack()}
+		}
+
+		var perr *oscript.PanicError
+		if errors.As(err, &perr) {
+			c.encBuf.Reset(c.conn)
+			if c.tee.buf != nil {
+				c.tee.buf.Reset()
+			}
+			c.conn.Close()
+		}
+	}()
+	return c.enc.Encode(req)
+}
+
+// ShortTransferError returned when the content is shorter than the declared size.
+type ShortTransferError struct {
+	Service  string
+	Expected int64
+	Actual   int64
+}
+
+func (e *ShortTransferError) Error() string {
+	return fmt.Sprintf("ot: %s short transfer: expected %d bytes, got %d", e.Service, e.Expected, e.Actual)
+}
+
+// WriteFrom writes content from r, size is the declared size of the content, negative size is unknown.
+func (c *Client) WriteFrom(r io.Reader, size int64) error {
+	if c.state != stateWritten {
+		return &ProtocolStateError{Op: "WriteFrom", State: c.state.String()}
 	}
 
+	n, err := io.Copy(c.conn, r)
+	if err != nil {
+		return c.fail(&OpError{Service: c.service, Err: err})
+	}
+
+	if n < size {
+		return c.fail(&ShortTransferError{Service: c.service, Expected: size, Actual: n})
+	}
 	return nil
 }
 
-func (c *Client) readMessage(resp *Response) (*Response, error) {
-	status := make([]byte, 9)
-	if _, err := c.conn.Read(status); err != nil {
-		return nil, &OpError{Service: c.service, Err: err}
+func (c *Client) readMessage(op string, next state, resp *Response) (*Response, error) {
+	if err := c.transit(op, next, stateWritten); err != nil {
+		return nil, err
 	}
 
-	// expecting bytes
-	if int(status[1]) != 9 || int(status[7]) != 1 {
-		return nil, &OpError{Service: c.service, Err: errOpenRequest}
+	if !c.accepted {
+		status := make([]byte, c.framing.StatusLen())
+		if _, err := io.ReadFull(c.conn, status); err != nil {
+			return nil, c.fail(&OpError{Service: c.service, Err: err})
+		}
+
+		if err := c.framing.DecodeStatus(status); err != nil {
+			return nil, c.fail(&OpError{Service: c.service, Err: err})
+		}
 	}
+	c.accepted = false
 
 	// open-request was sent and got success
 	c.opened = true
-	if err := c.dec.Decode(resp); err != nil {
+	err := c.decode(resp)
+	resp.Size = c.dec.ValueBytes()
+	if err != nil {
 		if _, ok := err.(*net.OpError); ok {
-			return nil, &OpError{Service: c.service, Err: errUnexpectedEOF}
+			return nil, c.fail(&OpError{Service: c.service, Err: errUnexpectedEOF})
 		}
-		return nil, &OpError{Service: c.service, Err: err}
+		return nil, c.fail(&OpError{Service: c.service, Err: err})
 	}
 
+	if resp.Status != 0 {
+		c.fail(&OpError{Service: c.service, Err: errors.New(resp.Desc)})
+	}
 	return resp, nil
 }
 
+// decode decodes response, the named outputs are decoded from the same value when they are requested.
+// The response is decoded from the raw message when it is captured.
+func (c *Client) decode(resp *Response) error {
+	if resp.Outputs == nil && c.capture == nil {
+		return c.dec.Decode(resp)
+	}
+
+	var raw oscript.RawMessage
+	if err := c.dec.Decode(&raw); err != nil {
+		return err
+	}
+	c.resp = raw
+
+	if err := c.unmarshal(raw, resp); err != nil {
+		return err
+	}
+
+	if resp.Outputs == nil {
+		return nil
+	}
+	return oscript.Unmarshal(raw, resp.Outputs)
+}
+
+// unmarshal unmarshals the raw message reporting the unknown fields.
+func (c *Client) unmarshal(raw oscript.RawMessage, v interface{}) error {
+	if c.unknown == nil {
+		return oscript.Unmarshal(raw, v)
+	}
+
+	dec := oscript.NewDecoder(bytes.NewReader(raw))
+	dec.OnUnknownField(c.unknown)
+	return dec.Decode(v)
+}
+
 func (c *Client) Read(r interface{}) (*Response, error) {
 	if r == nil {
 		r = nilResponse
 	}
-	return c.readMessage(&Response{Results: r, Service: c.service})
+	return c.readMessage("Read", stateRead, &Response{Results: r, Service: c.service})
+}
+
+// ReadOutputs reads response, Results is decoded to r and the whole response to outputs,
+// it allows to get the named outputs of the service besides Results.
+func (c *Client) ReadOutputs(r, outputs interface{}) (*Response, error) {
+	if r == nil {
+		r = nilResponse
+	}
+	return c.readMessage("ReadOutputs", stateRead, &Response{Results: r, Outputs: outputs, Service: c.service})
 }
 
 func (c *Client) ReadFile(fa interface{}) (*Response, error) {
-	return c.readMessage(&Response{FileAttr: fa, Service: c.service})
+	return c.readMessage("ReadFile", stateFile, &Response{FileAttr: fa, Service: c.service})
 }
 
-func (c *Client) Exec(service, method string, auth fmt.Stringer, args oscript.M, result interface{}) (*Response, error) {
+func (c *Client) Exec(service, method string, auth fmt.Stringer, args interface{}, result interface{}) (*Response, error) {
 	if err := c.Write(service, method, auth, args); err != nil {
 		return nil, err
 	}
@@ -126,19 +442,45 @@ func (c *Client) Exec(service, method string, auth fmt.Stringer, args oscript.M,
 	return c.Read(result)
 }
 
-func (c *Client) ReadTo(w io.Writer) error {
+// ReadTo reads content to w, size is the declared size of the content, negative size is unknown.
+func (c *Client) ReadTo(w io.Writer, size int64) error {
+	if err := c.transit("ReadTo", stateRead, stateFile); err != nil {
+		return err
+	}
+
 	// notice: io.EOF not returned by empty buffer because io.Copy checks it
-	if _, err := io.Copy(w, c.dec.Buffered()); err != nil {
-		return &OpError{Service: c.service, Err: err}
+	n1, err := io.Copy(w, c.dec.Buffered())
+	if err != nil {
+		return c.fail(&OpError{Service: c.service, Err: err})
 	}
 
-	if _, err := io.Copy(w, c.conn); err != nil {
-		return &OpError{Service: c.service, Err: err}
+	n2, err := io.Copy(w, c.conn)
+	if err != nil {
+		return c.fail(&OpError{Service: c.service, Err: err})
 	}
 
+	if n := n1 + n2; n < size {
+		return c.fail(&ShortTransferError{Service: c.service, Expected: size, Actual: n})
+	}
 	return nil
 }
 
 func (c *Client) Close() error {
+	if c.state == stateClosed {
+		return &ProtocolStateError{Op: "Close", State: c.state.String()}
+	}
+	c.state = stateClosed
+
+	if c.done != nil {
+		close(c.done)
+	}
+
+	if c.observe != nil && !c.start.IsZero() {
+		c.observe(c.service, time.Since(c.start), c.err)
+	}
+
+	if c.capture != nil && c.tee.buf.Len() != 0 {
+		c.capture(&Capture{Service: c.service, Start: c.start, Request: c.tee.buf.Bytes(), Response: c.resp})
+	}
 	return c.conn.Close()
 }
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/itcomusic/ot/internal/frame"
+	"github.com/itcomusic/ot/pkg/oscript"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn discards the written requests and reads the prepared responses.
+type fakeConn struct {
+	io.Reader
+}
+
+func (fakeConn) Write(p []byte) (int, error) { return len(p), nil }
+
+func (fakeConn) Close() error { return nil }
+
+func newFakeClient(responses ...string) *Client {
+	var b bytes.Buffer
+	for _, r := range responses {
+		b.Write(frame.EncodeStatus(true))
+		b.WriteString(r)
+	}
+	return New(fakeConn{&b})
+}
+
+func TestClient_State(t *testing.T) {
+	t.Parallel()
+
+	var serr *ProtocolStateError
+
+	c := newFakeClient("A<1,?,'_Status'=0,'Results'=1>")
+	_, err := c.Read(nil)
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, "ot: client: Read in state idle", err.Error())
+
+	require.Nil(t, c.Write("S", "M", auth("'Username'='u'"), oscript.M{}))
+	assert.Equal(t, &ProtocolStateError{Op: "Write", State: "written"}, c.Write("S", "M", auth("'Username'='u'"), oscript.M{}))
+
+	var res int
+	resp, err := c.Read(&res)
+	require.Nil(t, err)
+	assert.Equal(t, 1, res)
+	assert.Equal(t, int64(30), resp.Size)
+
+	assert.Equal(t, &ProtocolStateError{Op: "ReadTo", State: "read"}, c.ReadTo(io.Discard, 0))
+	assert.Equal(t, &ProtocolStateError{Op: "WriteFrom", State: "read"}, c.WriteFrom(bytes.NewReader(nil), 0))
+
+	_, err = c.Exec("S", "M", auth("'Username'='u'"), oscript.M{}, &res)
+	assert.Equal(t, &ProtocolStateError{Op: "Write", State: "read"}, err)
+
+	require.Nil(t, c.Close())
+	assert.Equal(t, &ProtocolStateError{Op: "Close", State: "closed"}, c.Close())
+	assert.Equal(t, &ProtocolStateError{Op: "Write", State: "closed"}, c.Write("S", "M", auth("'Username'='u'"), oscript.M{}))
+}
+
+func TestClient_StateFile(t *testing.T) {
+	t.Parallel()
+
+	c := newFakeClient("A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=5>,'_Status'=0>hello")
+	require.Nil(t, c.Write("S", "M", auth("'Username'='u'"), oscript.M{}))
+
+	var fa struct {
+		Size int64 `oscript:"DataForkSize"`
+	}
+	_, err := c.ReadFile(&fa)
+	require.Nil(t, err)
+	assert.Equal(t, &ProtocolStateError{Op: "Write", State: "file"}, c.Write("S", "M", auth("'Username'='u'"), oscript.M{}))
+
+	var b bytes.Buffer
+	require.Nil(t, c.ReadTo(&b, fa.Size))
+	assert.Equal(t, "hello", b.String())
+}
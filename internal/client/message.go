@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/itcomusic/ot/pkg/oscript"
@@ -14,7 +15,8 @@ type request struct {
 	Service string
 	Method  string
 	Auth    fmt.Stringer
-	Args    oscript.M
+	Header  map[string]string
+	Args    interface{} // oscript.M, oscript.Marshaler or oscript.MarshalerBuf
 }
 
 func (r *request) MarshalOscriptBuf(buf oscript.Buffer) error {
@@ -24,6 +26,20 @@ func (r *request) MarshalOscriptBuf(buf oscript.Buffer) error {
 	buf.WriteStringValue(r.Method)
 	buf.WriteByte(',')
 	buf.WriteString(r.Auth.String())
+	if len(r.Header) != 0 {
+		keys := make([]string, 0, len(r.Header))
+		for k := range r.Header {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			buf.WriteByte(',')
+			buf.WriteStringValue(k)
+			buf.WriteByte('=')
+			buf.WriteStringValue(r.Header[k])
+		}
+	}
 	buf.WriteString(",'Arguments'=")
 	buf.WriteEncode(r.Args)
 	buf.WriteByte('>')
@@ -39,26 +55,53 @@ type Response struct {
 	Desc          string      `oscript:"_errMsg"`
 	Results       interface{} `oscript:"Results"`
 	FileAttr      interface{} `oscript:"FileAttributes"`
+	Outputs       interface{} `oscript:"-"` // decoded from the whole response when it is set
 	Service       string      `oscript:"-"`
+	Size          int64       `oscript:"-"` // bytes of the message without the status and the content of the file
 }
 
+// ErrMessage returns the description without the error codes and the last code, see ErrCodes.
 func (r *Response) ErrMessage() (desc string, ecode string) {
-	st := strings.LastIndex(r.Desc, "[E")
-	if st == -1 {
-		return r.Desc, ""
+	desc, codes := r.ErrCodes()
+	if len(codes) == 0 {
+		return desc, ""
 	}
+	return desc, codes[len(codes)-1]
+}
 
-	st += 2
-	en := strings.IndexByte(r.Desc[st:], ']')
-	if en == -1 {
-		return r.Desc, ""
-	}
+// ErrCodes returns the description without the error codes and all codes in the order of appearance.
+// The code is written as [E<digits>] anywhere in the description, also inside other brackets,
+// e.g. "Fehler beim Erstellen [Name 'a' [E662044673]] [E903101]". The punctuation after the last code is dropped.
+func (r *Response) ErrCodes() (desc string, codes []string) {
+	var b strings.Builder
+	s := r.Desc
+	for {
+		i := strings.Index(s, "[E")
+		if i == -1 {
+			b.WriteString(s)
+			break
+		}
 
-	en += st
-	ecode = r.Desc[st:en]
-	if st-2 != 0 && r.Desc[st-3] == ' ' {
-		return r.Desc[:st-3], ecode
-	}
+		j := i + 2
+		for j < len(s) && '0' <= s[j] && s[j] <= '9' {
+			j++
+		}
+
+		if j == len(s) || s[j] != ']' { // not a code, e.g. "[Error"
+			b.WriteString(s[:i+2])
+			s = s[i+2:]
+			continue
+		}
 
-	return r.Desc[:st-2], ecode
+		b.WriteString(strings.TrimRight(s[:i], " "))
+		if j > i+2 {
+			codes = append(codes, s[i+2:j])
+		}
+
+		s = s[j+1:]
+		if strings.Trim(s, " .") == "" {
+			break
+		}
+	}
+	return b.String(), codes
 }
@@ -3,6 +3,8 @@ package client
 import (
 	"testing"
 
+	"github.com/itcomusic/ot/pkg/oscript"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,3 +47,72 @@ func TestResponseMessage_ErrMessage(t *testing.T) {
 		assert.Equal(t, tt.ecode, ecode)
 	}
 }
+
+func TestResponseMessage_ErrCodes(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name  string
+		desc  string
+		want  string
+		codes []string
+	}{
+		{
+			name:  "en",
+			desc:  "Could not access the node. [E662241287]",
+			want:  "Could not access the node.",
+			codes: []string{"662241287"},
+		},
+		{
+			name:  "de",
+			desc:  "Fehler: Auf das Objekt kann nicht zugegriffen werden. [E662241287] [E903101]",
+			want:  "Fehler: Auf das Objekt kann nicht zugegriffen werden.",
+			codes: []string{"662241287", "903101"},
+		},
+		{
+			name:  "fr",
+			desc:  "Erreur : impossible de créer l'élément [Nom 'rapport' [E662044673]] [E903101].",
+			want:  "Erreur : impossible de créer l'élément [Nom 'rapport']",
+			codes: []string{"662044673", "903101"},
+		},
+		{
+			name:  "ja",
+			desc:  "エラー [E662241287]: ノードにアクセスできません",
+			want:  "エラー: ノードにアクセスできません",
+			codes: []string{"662241287"},
+		},
+		{
+			name:  "es",
+			desc:  "Error [Detalles [E1]] en el servidor [E2] y [Error sin código]",
+			want:  "Error [Detalles] en el servidor y [Error sin código]",
+			codes: []string{"1", "2"},
+		},
+		{
+			name: "not code",
+			desc: "[Error [E12a] [E",
+			want: "[Error [E12a] [E",
+		},
+	} {
+		desc, codes := (&Response{Desc: tt.desc}).ErrCodes()
+		assert.Equal(t, tt.want, desc, tt.name)
+		assert.Equal(t, tt.codes, codes, tt.name)
+	}
+}
+
+type auth string
+
+func (a auth) String() string { return string(a) }
+
+func TestRequest_MarshalOscriptBuf(t *testing.T) {
+	t.Parallel()
+
+	b, err := oscript.Marshal(&request{
+		Service: "service",
+		Method:  "method",
+		Auth:    auth("'_Cookie'='token'"),
+		Header:  map[string]string{"X-Correlation": "1", "_AppID": "app"},
+		Args:    oscript.M{"ID": 1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "A<1,N,'_ApiName'='InvokeService','ServiceName'='service','ServiceMethod'='method','_Cookie'='token','X-Correlation'='1','_AppID'='app','Arguments'=A<1,?,'ID'=1>>", string(b))
+}
@@ -2,6 +2,7 @@ package conn
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
 	"net"
 	"time"
@@ -12,12 +13,25 @@ type Dialer interface {
 	DialContext(ctx context.Context) (io.ReadWriteCloser, error)
 }
 
+// A Dial represents tcp connection to the server, TLS is used when config is set.
 type Dial struct {
-	Addr string
+	Addr    string
+	TLS     *tls.Config
+	Timeout time.Duration
+	// IdleTimeout fails the read or write when no progress is made for the duration, the deadline
+	// is extended on every read and every chunk of the write but never beyond the deadline of the context. Zero is no limit.
+	IdleTimeout time.Duration
 }
 
 func (d *Dial) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
-	dialer := &net.Dialer{}
+	var dialer interface {
+		DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+	} = &net.Dialer{Timeout: d.Timeout}
+
+	if d.TLS != nil {
+		dialer = &tls.Dialer{NetDialer: &net.Dialer{Timeout: d.Timeout}, Config: d.TLS}
+	}
+
 	c, err := dialer.DialContext(ctx, "tcp", d.Addr)
 	if err != nil {
 		return nil, err
@@ -26,6 +40,7 @@ func (d *Dial) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
 	return &conn{
 		ctx:  ctx,
 		conn: c,
+		idle: d.IdleTimeout,
 	}, nil
 }
 
@@ -33,25 +48,50 @@ func (d *Dial) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
 type conn struct {
 	ctx  context.Context
 	conn net.Conn
+	idle time.Duration
 }
 
-func (c *conn) Write(p []byte) (int, error) {
-	select {
-	case <-c.ctx.Done():
-		return 0, c.ctx.Err()
-	default:
+// deadline returns deadline of the next read or write.
+func (c *conn) deadline() time.Time {
+	dl, _ := c.ctx.Deadline()
+	if c.idle > 0 {
+		if idle := time.Now().Add(c.idle); dl.IsZero() || idle.Before(dl) {
+			dl = idle
+		}
 	}
+	return dl
+}
 
-	deadline := time.Time{}
-	if dl, ok := c.ctx.Deadline(); ok {
-		deadline = dl
-	}
+// writeChunk is the maximum size of the single write to the connection, the idle deadline is extended
+// for every chunk, so the large write making progress over the slow link is not failed.
+const writeChunk = 64 << 10
 
-	if err := c.conn.SetWriteDeadline(deadline); err != nil {
-		return 0, err
-	}
+func (c *conn) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		select {
+		case <-c.ctx.Done():
+			return n, c.ctx.Err()
+		default:
+		}
+
+		if err := c.conn.SetWriteDeadline(c.deadline()); err != nil {
+			return n, err
+		}
 
-	return c.conn.Write(p)
+		chunk := p
+		if len(chunk) > writeChunk {
+			chunk = chunk[:writeChunk]
+		}
+
+		m, err := c.conn.Write(chunk)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
 }
 
 func (c *conn) Read(p []byte) (int, error) {
@@ -61,12 +101,7 @@ func (c *conn) Read(p []byte) (int, error) {
 	default:
 	}
 
-	deadline := time.Time{}
-	if dl, ok := c.ctx.Deadline(); ok {
-		deadline = dl
-	}
-
-	if err := c.conn.SetReadDeadline(deadline); err != nil {
+	if err := c.conn.SetReadDeadline(c.deadline()); err != nil {
 		return 0, err
 	}
 
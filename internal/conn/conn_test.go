@@ -0,0 +1,142 @@
+package conn
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDial_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		for i := 0; i < 5; i++ { // slow but steady transfer outlives the idle timeout
+			time.Sleep(30 * time.Millisecond)
+			c.Write([]byte{byte(i)})
+		}
+		time.Sleep(500 * time.Millisecond) // stalled
+	}()
+
+	c, err := (&Dial{Addr: l.Addr().String(), IdleTimeout: 100 * time.Millisecond}).DialContext(context.Background())
+	require.Nil(t, err)
+	defer c.Close()
+
+	b := make([]byte, 5)
+	_, err = io.ReadFull(c, b)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 1, 2, 3, 4}, b)
+
+	start := time.Now()
+	_, err = c.Read(b)
+	ne, ok := err.(net.Error)
+	require.True(t, ok, "%v", err)
+	assert.True(t, ne.Timeout())
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+}
+
+func TestConn_DeadlineOfContext(t *testing.T) {
+	t.Parallel()
+
+	dl := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), dl)
+	defer cancel()
+
+	assert.Equal(t, dl, (&conn{ctx: ctx, idle: time.Hour}).deadline())
+	assert.True(t, (&conn{ctx: ctx, idle: time.Second}).deadline().Before(dl))
+	assert.True(t, (&conn{ctx: context.Background()}).deadline().IsZero())
+}
+
+// chunkConn records the writes and the write deadlines.
+type chunkConn struct {
+	net.Conn
+	writes    []int
+	deadlines int
+}
+
+func (c *chunkConn) Write(p []byte) (int, error) {
+	c.writes = append(c.writes, len(p))
+	return len(p), nil
+}
+
+func (c *chunkConn) SetWriteDeadline(time.Time) error {
+	c.deadlines++
+	return nil
+}
+
+func TestConn_WriteChunks(t *testing.T) {
+	t.Parallel()
+
+	nc := &chunkConn{}
+	n, err := (&conn{ctx: context.Background(), conn: nc, idle: time.Second}).Write(make([]byte, 2*writeChunk+1))
+	require.Nil(t, err)
+	assert.Equal(t, 2*writeChunk+1, n)
+	assert.Equal(t, []int{writeChunk, writeChunk, 1}, nc.writes)
+	assert.Equal(t, 3, nc.deadlines, "the deadline is extended for every chunk")
+}
+
+type blockDialer struct {
+	dialing chan struct{}
+	unblock chan struct{}
+	closed  chan struct{}
+}
+
+func (d *blockDialer) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
+	close(d.dialing)
+	<-d.unblock
+	return closeConn{closed: d.closed}, nil
+}
+
+type closeConn struct {
+	io.ReadWriter
+	closed chan struct{}
+}
+
+func (c closeConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestGroup_ShutdownWhileDialing(t *testing.T) {
+	t.Parallel()
+
+	var g Group
+	d := &blockDialer{dialing: make(chan struct{}), unblock: make(chan struct{}), closed: make(chan struct{})}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := g.DialContext(context.Background(), d)
+		errc <- err
+	}()
+	<-d.dialing
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- g.Shutdown(context.Background()) }()
+	for {
+		g.mu.Lock()
+		closed := g.closed
+		g.mu.Unlock()
+		if closed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(d.unblock)
+	assert.Equal(t, ErrClosed, <-errc)
+	<-d.closed // the connection is released
+	require.Nil(t, <-shutdown)
+}
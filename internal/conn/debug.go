@@ -2,13 +2,18 @@ package conn
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/itcomusic/ot/internal/frame"
 )
 
 var (
-	lenRead  = 9 // status request
-	lenWrite = 8 // open request
+	lenRead  = frame.StatusLen
+	lenWrite = frame.OpenRequestLen
 )
 
 // A DialDebug represents an log in stdout writing and reading bytes except protocol.
@@ -16,6 +21,13 @@ var (
 type DialDebug struct {
 	Dial Dialer
 	Out  io.Writer
+
+	MaxBytes int  // maximum bytes of the message written, the middle of the longer message is cut, zero is unlimited
+	Sample   int  // writes every Nth connection only, zero or one writes every connection
+	JSON     bool // writes JSON lines instead of text
+
+	mu    sync.Mutex
+	count uint64
 }
 
 func (d *DialDebug) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
@@ -24,6 +36,10 @@ func (d *DialDebug) DialContext(ctx context.Context) (io.ReadWriteCloser, error)
 		return nil, err
 	}
 
+	if d.Sample > 1 && (atomic.AddUint64(&d.count, 1)-1)%uint64(d.Sample) != 0 {
+		return c, nil
+	}
+
 	return &connDebug{ // TODO: create uuid
 		conn:      c,
 		w:         d,
@@ -33,15 +49,48 @@ func (d *DialDebug) DialContext(ctx context.Context) (io.ReadWriteCloser, error)
 }
 
 func (d *DialDebug) Write(b []byte) (n int, err error) {
+	d.mu.Lock()
 	n, err = d.Out.Write(b)
+	d.mu.Unlock()
 	return n, err
 }
 
+// debugLine is a line of the debug in JSON format.
+type debugLine struct {
+	Op      string `json:"op"`
+	Bytes   int    `json:"bytes"`
+	Head    string `json:"head"`
+	Tail    string `json:"tail,omitempty"`
+	Skipped int    `json:"skipped,omitempty"`
+}
+
+// log writes the message p of the operation op, the middle of the message is cut when it is longer than MaxBytes.
+func (d *DialDebug) log(op string, p []byte) {
+	head, tail := p, []byte(nil)
+	if d.MaxBytes > 0 && len(p) > d.MaxBytes {
+		h := (d.MaxBytes + 1) / 2
+		head, tail = p[:h], p[len(p)-(d.MaxBytes-h):]
+	}
+	skipped := len(p) - len(head) - len(tail)
+
+	if d.JSON {
+		b, _ := json.Marshal(debugLine{Op: op, Bytes: len(p), Head: string(head), Tail: string(tail), Skipped: skipped})
+		d.Write(append(b, '\n'))
+		return
+	}
+
+	if skipped == 0 {
+		io.WriteString(d, fmt.Sprintf("debug-%s(%d-bytes): %s\n", op, len(p), p))
+		return
+	}
+	io.WriteString(d, fmt.Sprintf("debug-%s(%d-bytes): %s...(%d-bytes skipped)...%s\n", op, len(p), head, skipped, tail))
+}
+
 type connDebug struct {
 	skipRead  int
 	skipWrite int
 	conn      io.ReadWriteCloser
-	w         io.Writer
+	w         *DialDebug
 }
 
 func (cd *connDebug) Read(p []byte) (n int, err error) {
@@ -54,8 +103,7 @@ func (cd *connDebug) Read(p []byte) (n int, err error) {
 		cd.skipRead -= n
 		return n, err
 	}
-	// TODO: may be need sync
-	io.WriteString(cd.w, fmt.Sprintf("debug-read(%d-bytes): %s\n", n-cd.skipRead, p[cd.skipRead:n]))
+	cd.w.log("read", p[cd.skipRead:n])
 	if cd.skipRead != 0 {
 		cd.skipRead = 0
 	}
@@ -75,7 +123,7 @@ func (cd *connDebug) Write(p []byte) (n int, err error) {
 		return n, err
 	}
 
-	io.WriteString(cd.w, fmt.Sprintf("debug-write(%d-bytes): %s\n", l-cd.skipWrite, p[cd.skipWrite:]))
+	cd.w.log("write", p[cd.skipWrite:])
 	if cd.skipWrite != 0 {
 		cd.skipWrite = 0
 	}
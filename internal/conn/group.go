@@ -0,0 +1,114 @@
+package conn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrClosed returned by dial after shutdown of the group.
+var ErrClosed = errors.New("ot: endpoint closed")
+
+// A Group tracks open connections and allows to wait for their completion on shutdown.
+// A nil Group does not track connections.
+type Group struct {
+	mu     sync.Mutex
+	closed bool
+	conns  map[*connGroup]struct{}
+	idle   chan struct{} // closed when last connection of the closed group is released
+}
+
+// DialContext dials using d and tracks connection until it is closed.
+func (g *Group) DialContext(ctx context.Context, d Dialer) (io.ReadWriteCloser, error) {
+	if g == nil {
+		return d.DialContext(ctx)
+	}
+
+	cg := &connGroup{g: g}
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if g.conns == nil {
+		g.conns = make(map[*connGroup]struct{})
+	}
+	g.conns[cg] = struct{}{}
+	g.mu.Unlock()
+
+	c, err := d.DialContext(ctx)
+	if err != nil {
+		g.release(cg)
+		return nil, err
+	}
+
+	g.mu.Lock()
+	if g.closed { // shutdown started while dialing, e.g. waiting for the connection of the pool
+		g.mu.Unlock()
+		c.Close()
+		g.release(cg)
+		return nil, ErrClosed
+	}
+	cg.ReadWriteCloser = c
+	g.mu.Unlock()
+	return cg, nil
+}
+
+// Shutdown stops accepting new connections and waits for the open connections to be closed.
+// If the context is done before, the remaining connections are closed forcibly and the context error is returned.
+func (g *Group) Shutdown(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	g.closed = true
+	if len(g.conns) == 0 {
+		g.mu.Unlock()
+		return nil
+	}
+	if g.idle == nil {
+		g.idle = make(chan struct{})
+	}
+	idle := g.idle
+	g.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	for c := range g.conns {
+		if c.ReadWriteCloser != nil {
+			c.ReadWriteCloser.Close()
+		}
+	}
+	g.mu.Unlock()
+	return ctx.Err()
+}
+
+func (g *Group) release(c *connGroup) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.conns, c)
+	if g.closed && len(g.conns) == 0 && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+}
+
+type connGroup struct {
+	io.ReadWriteCloser
+	g    *Group
+	once sync.Once
+}
+
+func (c *connGroup) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.once.Do(func() { c.g.release(c) })
+	return err
+}
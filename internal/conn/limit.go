@@ -0,0 +1,77 @@
+package conn
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// A Limiter limits the rate of the dials, every call uses own connection so it limits the rate of the calls.
+// DialContext blocks until the rate allows the dial or the context is done.
+type Limiter struct {
+	Dial Dialer
+	// Wait is called with time spent waiting for the permission, it may be nil.
+	Wait func(d time.Duration)
+
+	interval time.Duration
+	burst    int
+
+	mu  sync.Mutex
+	tat time.Time // theoretical arrival time of the next dial
+}
+
+// NewLimiter returns limiter with perSecond dials per second and maximum burst of the dials.
+func NewLimiter(d Dialer, perSecond float64, burst int) *Limiter {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &Limiter{Dial: d, interval: time.Duration(float64(time.Second) / perSecond), burst: burst}
+}
+
+// Acquire waits for the permission of the dial, it returns error of the context when the context is done
+// or its deadline comes earlier than the permission.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	tat := l.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	wait := tat.Add(-time.Duration(l.burst-1) * l.interval).Sub(now)
+	if deadline, ok := ctx.Deadline(); ok && wait > 0 && deadline.Before(now.Add(wait)) {
+		l.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+	l.tat = tat.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		if l.Wait != nil {
+			l.Wait(wait)
+		}
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tat = l.tat.Add(-l.interval) // gives back the reservation
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
+	if err := l.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	return l.Dial.DialContext(ctx)
+}
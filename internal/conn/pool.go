@@ -0,0 +1,52 @@
+package conn
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// A Pool limits the number of simultaneously open connections of the dialer.
+// DialContext blocks until a connection is released or the context is done.
+type Pool struct {
+	Dial Dialer
+	sem  chan struct{}
+}
+
+// NewPool returns pool with size simultaneously open connections.
+func NewPool(d Dialer, size int) *Pool {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &Pool{Dial: d, sem: make(chan struct{}, size)}
+}
+
+func (p *Pool) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c, err := p.Dial.DialContext(ctx)
+	if err != nil {
+		<-p.sem
+		return nil, err
+	}
+
+	return &connPool{ReadWriteCloser: c, p: p}, nil
+}
+
+// connPool releases place in the pool on close.
+type connPool struct {
+	io.ReadWriteCloser
+	p    *Pool
+	once sync.Once
+}
+
+func (c *connPool) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.once.Do(func() { <-c.p.sem })
+	return err
+}
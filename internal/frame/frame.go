@@ -0,0 +1,85 @@
+// Package frame implements framing of the LLAPI protocol around the oscript messages.
+//
+// Every request starts with the open request which carries the version of the protocol,
+// the server answers with the status before the response message.
+package frame
+
+import (
+	"errors"
+)
+
+const (
+	// OpenRequestLen is a length of the open request.
+	OpenRequestLen = 8
+	// StatusLen is a length of the status.
+	StatusLen = 9
+)
+
+// ErrRejected returned when the server has not accepted the open request.
+var ErrRejected = errors.New("protocol error")
+
+// Version is a version of the protocol sent in the open request.
+type Version [OpenRequestLen - 2]byte
+
+// DefaultVersion is a version of the protocol supported by the servers.
+var DefaultVersion = Version{1, 3, 0, 0, 2, 4}
+
+// Framing is a variant of the framing, other implementations allow to support newer variants of the protocol.
+type Framing interface {
+	// OpenRequest returns bytes written before every request.
+	OpenRequest() []byte
+	// StatusLen returns length of the status read before every response.
+	StatusLen() int
+	// DecodeStatus checks the status, it returns error when the open request is not accepted.
+	DecodeStatus(status []byte) error
+}
+
+// LLAPI is a framing of the protocol with the version.
+type LLAPI struct {
+	Version Version
+}
+
+// Default is a framing used by default.
+var Default Framing = LLAPI{Version: DefaultVersion}
+
+// OpenRequest implements Framing.
+func (f LLAPI) OpenRequest() []byte {
+	return EncodeOpenRequest(f.Version)
+}
+
+// StatusLen implements Framing.
+func (LLAPI) StatusLen() int {
+	return StatusLen
+}
+
+// DecodeStatus implements Framing.
+func (LLAPI) DecodeStatus(status []byte) error {
+	return DecodeStatus(status)
+}
+
+// EncodeOpenRequest returns the open request of the version.
+func EncodeOpenRequest(v Version) []byte {
+	b := make([]byte, OpenRequestLen)
+	b[0] = 1
+	b[1] = OpenRequestLen
+	copy(b[2:], v[:])
+	return b
+}
+
+// EncodeStatus returns the status, accepted reports whether the open request is accepted.
+func EncodeStatus(accepted bool) []byte {
+	b := make([]byte, StatusLen)
+	b[1] = StatusLen
+	if accepted {
+		b[7] = 1
+	}
+	return b
+}
+
+// DecodeStatus checks the status, it returns ErrRejected when the open request is not accepted.
+func DecodeStatus(status []byte) error {
+	if len(status) != StatusLen || status[1] != StatusLen || status[7] != 1 {
+		return ErrRejected
+	}
+	return nil
+}
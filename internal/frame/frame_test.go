@@ -0,0 +1,29 @@
+package frame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeOpenRequest(t *testing.T) {
+	assert.Equal(t, []byte{1, 8, 1, 3, 0, 0, 2, 4}, EncodeOpenRequest(DefaultVersion))
+	assert.Equal(t, []byte{1, 8, 1, 4, 0, 0, 0, 0}, LLAPI{Version: Version{1, 4}}.OpenRequest())
+}
+
+func TestDecodeStatus(t *testing.T) {
+	testCases := []struct {
+		in  []byte
+		err error
+	}{
+		{in: []byte{0, 9, 0, 0, 0, 0, 0, 1, 0}},
+		{in: EncodeStatus(true)},
+		{in: EncodeStatus(false), err: ErrRejected},
+		{in: []byte{0, 8, 0, 0, 0, 0, 0, 1, 0}, err: ErrRejected},
+		{in: []byte{0, 9, 0}, err: ErrRejected},
+	}
+
+	for _, tt := range testCases {
+		assert.Equal(t, tt.err, Default.DecodeStatus(tt.in), "%v", tt.in)
+	}
+}
@@ -0,0 +1,85 @@
+package ot
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Links builds URLs of the nodes in the web interface of the server, e.g. for emails and pages linking the documents.
+type Links struct {
+	base *url.URL
+}
+
+// NewLinks creates builder of the URLs, base is the external URL of the CGI, e.g. "https://cs.example.com/otcs/cs.exe".
+func NewLinks(base string) (*Links, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("ot: invalid base url: %w", err)
+	}
+
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("ot: invalid base url: %q is not absolute", base)
+	}
+
+	u.Path, u.RawPath = strings.TrimRight(u.Path, "/"), strings.TrimRight(u.RawPath, "/")
+	u.RawQuery, u.Fragment = "", ""
+	return &Links{base: u}, nil
+}
+
+// Open returns URL of the node in the smart UI.
+func (l *Links) Open(id int64) string {
+	return l.path("app", "nodes", strconv.FormatInt(id, 10))
+}
+
+// Overview returns URL of the overview page of the node.
+func (l *Links) Overview(id int64) string {
+	return l.action(id, "overview", nil)
+}
+
+// Download returns URL of the content of the current version of the document.
+func (l *Links) Download(id int64) string {
+	return l.action(id, "download", nil)
+}
+
+// Version returns URL of the content of the version of the document.
+func (l *Links) Version(id, version int64) string {
+	return l.action(id, "download", url.Values{"vernum": {strconv.FormatInt(version, 10)}})
+}
+
+// Nickname returns URL of the node by nickname, the URL is kept when the node is moved.
+func (l *Links) Nickname(nickname string) string {
+	return l.path("open", nickname)
+}
+
+// Node returns URL of the node by nickname when it is set, otherwise URL of the node in the smart UI.
+func (l *Links) Node(node *Node) string {
+	if node.Nickname != "" {
+		return l.Nickname(node.Nickname)
+	}
+	return l.Open(node.ID)
+}
+
+func (l *Links) path(elem ...string) string {
+	u := *l.base
+	raw := u.EscapedPath()
+	for _, e := range elem {
+		raw += "/" + url.PathEscape(e) // the segment is kept whole, e.g. nickname with slash
+	}
+
+	u.Path, _ = url.PathUnescape(raw)
+	u.RawPath = raw
+	return u.String()
+}
+
+func (l *Links) action(id int64, action string, params url.Values) string {
+	q := url.Values{"func": {"ll"}, "objId": {strconv.FormatInt(id, 10)}, "objAction": {action}}
+	for k, v := range params {
+		q[k] = v
+	}
+
+	u := *l.base
+	u.RawQuery = q.Encode()
+	return u.String()
+}
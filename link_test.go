@@ -0,0 +1,27 @@
+package ot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewLinks("https://cs.example.com/otcs/cs.exe/?func=ll")
+	require.Nil(t, err)
+
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe/app/nodes/12", l.Open(12))
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe?func=ll&objAction=overview&objId=12", l.Overview(12))
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe?func=ll&objAction=download&objId=12", l.Download(12))
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe?func=ll&objAction=download&objId=12&vernum=3", l.Version(12, 3))
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe/open/annual%20report%2F2020", l.Nickname("annual report/2020"))
+
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe/open/report", l.Node(&Node{ID: 12, Nickname: "report"}))
+	assert.Equal(t, "https://cs.example.com/otcs/cs.exe/app/nodes/12", l.Node(&Node{ID: 12}))
+
+	_, err = NewLinks("/otcs/cs.exe")
+	assert.NotNil(t, err)
+}
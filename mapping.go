@@ -0,0 +1,241 @@
+package ot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var errStructPointer = errors.New("value must be non-nil pointer to struct")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// attrField is the field of the struct mapped to the attribute.
+type attrField struct {
+	name  string
+	index int
+}
+
+// attrFields returns the fields of the struct with otattr tag, the fields with tag "-" are skipped.
+func attrFields(t reflect.Type) []attrField {
+	var fields []attrField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("otattr")
+		if !ok || tag == "-" || f.PkgPath != "" {
+			continue
+		}
+
+		if name := strings.TrimSpace(tag); name != "" {
+			fields = append(fields, attrField{name: name, index: i})
+		}
+	}
+	return fields
+}
+
+// typeOf returns type of the attribute which values are stored in the field of the type.
+// The pointers are nullable values, the slices are multi-valued attributes.
+func typeOf(t reflect.Type) TypeValue {
+	if t.Kind() == reflect.Ptr || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return TimeType
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return StringType
+	case reflect.Bool:
+		return BoolType
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return IntType
+	default:
+		return NilType
+	}
+}
+
+// value finds the attribute and checks that the field may keep its values.
+func (c *Category) value(name string, t reflect.Type) (*Value, error) {
+	for i := range c.Data {
+		v := &c.Data[i]
+		if v.Description != name {
+			continue
+		}
+
+		if ft := typeOf(t); ft != v.Type {
+			return nil, fmt.Errorf("invalid type attribute \"%s\" \"%s\", field type %s", name, v.Type, t)
+		}
+
+		if t.Kind() == reflect.Slice && v.MaxValues == 1 {
+			return nil, fmt.Errorf("attribute \"%s\" is not multi-valued", name)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("not found attribute \"%s\"", name)
+}
+
+// MapStruct sets values of the attributes from the fields of the struct tagged by otattr with the names
+// of the attributes. The fields of string, bool, integer types and time.Time are supported, pointers
+// to them set nil value when nil and slices of them set values of multi-valued attributes.
+// No values are set when any field does not match the attribute.
+func MapStruct(cat *Category, v interface{}) error {
+	if cat == nil {
+		return errCategory
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return errStructPointer
+	}
+
+	fields := attrFields(rv.Type())
+	values := make([][]interface{}, len(fields))
+	attrs := make([]*Value, len(fields))
+	for i, f := range fields {
+		fv := rv.Field(f.index)
+		attr, err := cat.value(f.name, fv.Type())
+		if err != nil {
+			return err
+		}
+
+		attrs[i] = attr
+		if fv.Kind() == reflect.Slice {
+			values[i] = make([]interface{}, fv.Len())
+			for j := range values[i] {
+				values[i][j] = toAttr(fv.Index(j))
+			}
+			continue
+		}
+		values[i] = []interface{}{toAttr(fv)}
+	}
+
+	for i, attr := range attrs {
+		attr.Value = values[i]
+	}
+	return nil
+}
+
+// toAttr returns value of the attribute of the field.
+func toAttr(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint())
+	default: // time.Time
+		return v.Interface()
+	}
+}
+
+// FillStruct sets the fields of the struct tagged by otattr from values of the attributes, see MapStruct.
+// The nil value sets zero value of the field, it is checked that the integer fits the field.
+func FillStruct(cat *Category, v interface{}) error {
+	if cat == nil {
+		return errCategory
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errStructPointer
+	}
+	rv = rv.Elem()
+
+	for _, f := range attrFields(rv.Type()) {
+		fv := rv.Field(f.index)
+		attr, err := cat.value(f.name, fv.Type())
+		if err != nil {
+			return err
+		}
+
+		if fv.Kind() == reflect.Slice {
+			s := reflect.MakeSlice(fv.Type(), 0, len(attr.Value))
+			for _, av := range attr.Value {
+				if av == nil {
+					continue
+				}
+
+				e := reflect.New(fv.Type().Elem()).Elem()
+				if err := fromAttr(e, av); err != nil {
+					return fmt.Errorf("attribute \"%s\": %w", f.name, err)
+				}
+				s = reflect.Append(s, e)
+			}
+			fv.Set(s)
+			continue
+		}
+
+		var av interface{}
+		if len(attr.Value) != 0 {
+			av = attr.Value[0]
+		}
+
+		if av == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(reflect.New(fv.Type().Elem()))
+			fv = fv.Elem()
+		}
+
+		if err := fromAttr(fv, av); err != nil {
+			return fmt.Errorf("attribute \"%s\": %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// fromAttr sets the field from the value of the attribute.
+func fromAttr(v reflect.Value, av interface{}) error {
+	a := reflect.ValueOf(av)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := intOf(a)
+		if !ok || v.OverflowInt(n) {
+			return fmt.Errorf("value %v overflows %s", av, v.Type())
+		}
+		v.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := intOf(a)
+		if !ok || n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("value %v overflows %s", av, v.Type())
+		}
+		v.SetUint(uint64(n))
+		return nil
+	}
+
+	if !a.Type().ConvertibleTo(v.Type()) || a.Kind() != v.Kind() {
+		return fmt.Errorf("failed cast %T to %s", av, v.Type())
+	}
+	v.Set(a.Convert(v.Type()))
+	return nil
+}
+
+// intOf returns integer of the value of the attribute.
+func intOf(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	default:
+		return 0, false
+	}
+}
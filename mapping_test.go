@@ -0,0 +1,101 @@
+package ot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type invoiceStatus string
+
+type invoice struct {
+	Number  string        `otattr:"Invoice Number"`
+	Amount  int64         `otattr:"Amount"`
+	Paid    *bool         `otattr:"Paid"`
+	Due     time.Time     `otattr:"Due Date"`
+	Tags    []string      `otattr:"Tags"`
+	Status  invoiceStatus `otattr:"Status"`
+	Ignored string        `otattr:"-"`
+	Other   string
+}
+
+func invoiceCategory() *Category {
+	return &Category{Data: []Value{
+		{Description: "Invoice Number", Type: StringType},
+		{Description: "Amount", Type: IntType},
+		{Description: "Paid", Type: BoolType},
+		{Description: "Due Date", Type: TimeType},
+		{Description: "Tags", Type: StringType, MaxValues: 5},
+		{Description: "Status", Type: StringType},
+	}}
+}
+
+func TestMapStruct(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	cat := invoiceCategory()
+	require.Nil(t, MapStruct(cat, invoice{Number: "A-1", Amount: 100, Due: due, Tags: []string{"a", "b"}, Status: "new"}))
+
+	assert.Equal(t, []Value{
+		{Description: "Invoice Number", Type: StringType, Value: []interface{}{"A-1"}},
+		{Description: "Amount", Type: IntType, Value: []interface{}{100}},
+		{Description: "Paid", Type: BoolType, Value: []interface{}{nil}},
+		{Description: "Due Date", Type: TimeType, Value: []interface{}{due}},
+		{Description: "Tags", Type: StringType, MaxValues: 5, Value: []interface{}{"a", "b"}},
+		{Description: "Status", Type: StringType, Value: []interface{}{"new"}},
+	}, cat.Data)
+
+	var got invoice
+	require.Nil(t, FillStruct(cat, &got))
+	assert.Equal(t, invoice{Number: "A-1", Amount: 100, Due: due, Tags: []string{"a", "b"}, Status: "new"}, got)
+}
+
+func TestMapStruct_Invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		v   interface{}
+		err string
+	}{
+		{v: 1, err: "value must be non-nil pointer to struct"},
+		{v: struct {
+			A int `otattr:"Invoice Number"`
+		}{}, err: `invalid type attribute "Invoice Number" "Core.StringValue", field type int`},
+		{v: struct {
+			A []int `otattr:"Amount"`
+		}{}, err: ""},
+		{v: struct {
+			A string `otattr:"Unknown"`
+		}{}, err: `not found attribute "Unknown"`},
+	}
+
+	for i, tt := range testCases {
+		cat := invoiceCategory()
+		err := MapStruct(cat, tt.v)
+		if tt.err == "" {
+			assert.Nil(t, err, i)
+			continue
+		}
+		assert.EqualError(t, err, tt.err, i)
+		assert.Equal(t, invoiceCategory(), cat, i)
+	}
+}
+
+func TestFillStruct_Overflow(t *testing.T) {
+	t.Parallel()
+
+	cat := &Category{Data: []Value{{Description: "Amount", Type: IntType, MaxValues: 1, Value: []interface{}{300}}}}
+	var v struct {
+		Small int8 `otattr:"Amount"`
+	}
+	assert.EqualError(t, FillStruct(cat, &v), `attribute "Amount": value 300 overflows int8`)
+	assert.Equal(t, errStructPointer, FillStruct(cat, v))
+
+	var multi struct {
+		Amount []int `otattr:"Amount"`
+	}
+	assert.EqualError(t, FillStruct(cat, &multi), `attribute "Amount" is not multi-valued`)
+}
@@ -8,6 +8,32 @@ import (
 
 const memberService = "MemberService"
 
+// User is a user of the server.
+type User struct {
+	ID        int64  `oscript:"ID"`
+	Name      string `oscript:"Name"`
+	FirstName string `oscript:"FirstName"`
+	LastName  string `oscript:"LastName"`
+	Email     string `oscript:"Email"`
+
+	sdoName oscript.SDOName `oscript:"MemberService.User,public"`
+}
+
+// GetAuthenticatedUser gets user of the session.
+func (s *Session) GetAuthenticatedUser(ctx context.Context) (*User, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var u User
+	if err := errIn(c.Exec(memberService, "GetAuthenticatedUser", s.auth, oscript.M{}, &u)); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 // CreateGroup creates group.
 func (s *Session) CreateGroup(ctx context.Context, name string, leaderID *string) (int64, error) {
 	c, err := s.connect(ctx)
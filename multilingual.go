@@ -0,0 +1,77 @@
+package ot
+
+import (
+	"strings"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// MultilingualString is a text in several languages keyed by language code, e.g. "en", "de".
+// The server sends the multilingual fields as assoc when multilingual metadata is enabled.
+type MultilingualString struct {
+	Values  map[string]string
+	Default string // language of the value returned when requested language is absent
+}
+
+// Get returns value in the language or in the default language.
+func (m MultilingualString) Get(lang string) string {
+	if v, ok := m.Values[lang]; ok {
+		return v
+	}
+	return m.Values[m.Default]
+}
+
+// Set sets value in the language.
+func (m *MultilingualString) Set(lang, v string) {
+	if m.Values == nil {
+		m.Values = make(map[string]string)
+	}
+	m.Values[lang] = v
+}
+
+// SetDefault sets default language.
+func (m *MultilingualString) SetDefault(lang string) {
+	m.Default = lang
+}
+
+// String returns value in the default language.
+func (m MultilingualString) String() string {
+	return m.Get(m.Default)
+}
+
+// MarshalOscript implements the oscript.Marshaler interface.
+func (m MultilingualString) MarshalOscript() ([]byte, error) {
+	if m.Values == nil {
+		return oscript.Marshal(map[string]string{})
+	}
+	return oscript.Marshal(m.Values)
+}
+
+// UnmarshalOscript implements the oscript.Unmarshaler interface.
+// The plain string is stored as value of the default language.
+func (m *MultilingualString) UnmarshalOscript(data []byte) error {
+	if string(data) == "?" {
+		return nil
+	}
+
+	if data[0] == '\'' {
+		var s string
+		if err := oscript.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		m.Set(m.Default, s)
+		return nil
+	}
+
+	var values map[string]interface{}
+	if err := oscript.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	for lang, v := range values {
+		if s, ok := v.(string); ok && !strings.HasPrefix(lang, "_") {
+			m.Set(lang, s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package ot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultilingualString_UnmarshalOscript(t *testing.T) {
+	t.Parallel()
+
+	for i, tt := range []struct {
+		in  string
+		exp *Node
+	}{
+		{
+			in:  "A<1,?,'Name'='name','NameMultilingual'=A<1,?,'de'='Name','en'='name'>>",
+			exp: &Node{Name: "name", NameML: &MultilingualString{Values: map[string]string{"de": "Name", "en": "name"}}},
+		},
+		{
+			in:  "A<1,?,'Name'='name','CommentMultilingual'='comment'>",
+			exp: &Node{Name: "name", CommentML: &MultilingualString{Values: map[string]string{"": "comment"}}},
+		},
+		{
+			in:  "A<1,?,'Name'='name','NameMultilingual'=?>",
+			exp: &Node{Name: "name"},
+		},
+	} {
+		var got Node
+		require.Nil(t, oscript.Unmarshal([]byte(tt.in), &got), fmt.Sprintf("#%d", i))
+		assert.Equal(t, tt.exp, &got, fmt.Sprintf("#%d", i))
+	}
+}
+
+func TestMultilingualString_MarshalOscript(t *testing.T) {
+	t.Parallel()
+
+	var m MultilingualString
+	m.Set("en", "name")
+	m.Set("de", "Name")
+
+	b, err := oscript.Marshal(m)
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'de'='Name','en'='name'>", string(b))
+}
+
+func TestMultilingualString_Get(t *testing.T) {
+	t.Parallel()
+
+	m := MultilingualString{Values: map[string]string{"de": "Name", "en": "name"}}
+	m.SetDefault("en")
+	assert.Equal(t, "Name", m.Get("de"))
+	assert.Equal(t, "name", m.Get("fr"))
+	assert.Equal(t, "name", m.String())
+
+	n := &Node{Name: "plain", NameML: &m}
+	assert.Equal(t, "Name", n.LocalName("de"))
+	assert.Equal(t, "plain", (&Node{Name: "plain"}).LocalName("de"))
+}
@@ -2,6 +2,8 @@ package ot
 
 import (
 	"context"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/itcomusic/ot/pkg/oscript"
@@ -29,15 +31,35 @@ type Feature struct {
 	Name string `oscript:"Name"`
 	Type string `oscript:"Type"`
 
-	BooleanValue *bool      `oscript:"BooleanValue,omitempty"`
-	DateValue    *time.Time `oscript:"DateValue,omitempty"`
-	IntegerValue *int       `oscript:"IntegerValue,omitempty"`
-	LongValue    *float64   `oscript:"LongValue,omitempty"`
-	StringValue  *string    `oscript:"StringValue,omitempty"`
+	BooleanValue Nullable[bool]      `oscript:"BooleanValue,omitundef"`
+	DateValue    Nullable[time.Time] `oscript:"DateValue,omitundef"`
+	IntegerValue Nullable[int]       `oscript:"IntegerValue,omitundef"`
+	LongValue    Nullable[float64]   `oscript:"LongValue,omitundef"`
+	StringValue  Nullable[string]    `oscript:"StringValue,omitundef"`
 
 	sdoName oscript.SDOName `oscript:"DocMan.NodeFeature,public"`
 }
 
+// FeatureBool returns boolean feature.
+func FeatureBool(name string, v bool) Feature {
+	return Feature{Name: name, Type: "Boolean", BooleanValue: NewNullable(v)}
+}
+
+// FeatureDate returns date feature.
+func FeatureDate(name string, v time.Time) Feature {
+	return Feature{Name: name, Type: "Date", DateValue: NewNullable(v)}
+}
+
+// FeatureInt returns integer feature.
+func FeatureInt(name string, v int) Feature {
+	return Feature{Name: name, Type: "Integer", IntegerValue: NewNullable(v)}
+}
+
+// FeatureString returns string feature.
+func FeatureString(name string, v string) Feature {
+	return Feature{Name: name, Type: "String", StringValue: NewNullable(v)}
+}
+
 type NodeReservationInfo struct {
 	Reserved     bool       `oscript:"Reserved"`
 	ReservedBy   int64      `oscript:"ReservedBy"`
@@ -61,32 +83,34 @@ type NodeVersionInfo struct {
 }
 
 type Version struct {
-	Comment        string    `oscript:"Comment"`
-	CreateDate     time.Time `oscript:"CreateDate"`
-	FileCreateDate time.Time `oscript:"FileCreateDate"`
-	FileCreator    string    `oscript:"FileCreator"`
-	FileDataSize   int64     `oscript:"FileDataSize"`
-	FileModifyDate time.Time `oscript:"FileModifyDate"`
-	FileName       string    `oscript:"Filename"`
-	FilePlatform   int       `oscript:"FilePlatform"`
-	FileResSize    int64     `oscript:"FileResSize"`
-	FileType       string    `oscript:"FileType"`
-	ID             int64     `oscript:"ID"`
-	Locked         int       `oscript:"Locked"`
-	LockedBy       int64     `oscript:"LockedBy,omitempty"`
-	LockedDate     time.Time `oscript:"LockedDate"`
-	Metadata       Metadata  `oscript:"Metadata"`
-	MimeType       string    `oscript:"MimeType"`
-	ModifyDate     time.Time `oscript:"ModifyDate"`
-	Name           string    `oscript:"Name"`
-	NodeID         int64     `oscript:"NodeID"`
-	Number         int64     `oscript:"Number"`
-	Owner          int64     `oscript:"Owner"`
-	ProviderID     int64     `oscript:"ProviderID"`
-	ProviderName   string    `oscript:"ProviderName"`
-	Type           string    `oscript:"Type"`
-	VerMajor       int64     `oscript:"VerMajor"`
-	VerMinor       int64     `oscript:"VerMinor"`
+	Comment        string              `oscript:"Comment"`
+	CreateDate     time.Time           `oscript:"CreateDate"`
+	CreatedBy      int64               `oscript:"CreatedBy,omitempty"` // creator of the version, Owner may be changed later
+	FileCreateDate time.Time           `oscript:"FileCreateDate"`
+	FileCreator    string              `oscript:"FileCreator"`
+	FileDataSize   int64               `oscript:"FileDataSize"`
+	FileModifyDate time.Time           `oscript:"FileModifyDate"`
+	FileName       string              `oscript:"Filename"`
+	FilePlatform   int                 `oscript:"FilePlatform"`
+	FileResSize    int64               `oscript:"FileResSize"`
+	FileType       string              `oscript:"FileType"`
+	ID             int64               `oscript:"ID"`
+	Locked         int                 `oscript:"Locked"`
+	LockedBy       int64               `oscript:"LockedBy,omitempty"`
+	LockedDate     Nullable[time.Time] `oscript:"LockedDate"`
+	Metadata       Metadata            `oscript:"Metadata"`
+	MimeType       string              `oscript:"MimeType"`
+	ModifyDate     time.Time           `oscript:"ModifyDate"`
+	Name           string              `oscript:"Name"`
+	NodeID         int64               `oscript:"NodeID"`
+	Number         int64               `oscript:"Number"`
+	Owner          int64               `oscript:"Owner"`
+	ProviderID     int64               `oscript:"ProviderID"`
+	ProviderName   string              `oscript:"ProviderName"`
+	Type           string              `oscript:"Type"`
+	VerMajor       int64               `oscript:"VerMajor"`
+	VerMinor       int64               `oscript:"VerMinor"`
+	Checksum       *Checksum           `oscript:"Checksum,omitempty"` // set when the storage provider keeps checksums
 
 	sdoName oscript.SDOName `oscript:"DocMan.Version,public"`
 }
@@ -109,6 +133,7 @@ type NodeContainerInfo struct {
 type Node struct {
 	Catalog         int32               `oscript:"Catalog,omitempty"`
 	Comment         string              `oscript:"Comment"`
+	CommentML       *MultilingualString `oscript:"CommentMultilingual,omitempty"`
 	ContainerInfo   NodeContainerInfo   `oscript:"ContainerInfo"`
 	CreateDate      time.Time           `oscript:"CreateDate,omitempty"`
 	CreatedBy       int32               `oscript:"CreatedBy,omitempty"`
@@ -122,6 +147,7 @@ type Node struct {
 	Metadata        Metadata            `oscript:"Metadata"`
 	ModifyDate      time.Time           `oscript:"ModifyDate,omitempty"`
 	Name            string              `oscript:"Name"`
+	NameML          *MultilingualString `oscript:"NameMultilingual,omitempty"`
 	Nickname        string              `oscript:"Nickname,omitempty"`
 	Parent          int64               `oscript:"ParentID"`
 	PartialData     bool                `oscript:"PartialData"`
@@ -137,6 +163,26 @@ type Node struct {
 	sdoName oscript.SDOName `oscript:"DocMan.Node,public"`
 }
 
+// LocalName returns name in the language when the multilingual metadata is enabled, otherwise name.
+func (n *Node) LocalName(lang string) string {
+	if n.NameML != nil {
+		if v := n.NameML.Get(lang); v != "" {
+			return v
+		}
+	}
+	return n.Name
+}
+
+// LocalComment returns comment in the language when the multilingual metadata is enabled, otherwise comment.
+func (n *Node) LocalComment(lang string) string {
+	if n.CommentML != nil {
+		if v := n.CommentML.Get(lang); v != "" {
+			return v
+		}
+	}
+	return n.Comment
+}
+
 // CreateNode creates node.
 func (s *Session) CreateNode(ctx context.Context, node *Node) error {
 	c, err := s.connect(ctx)
@@ -148,24 +194,57 @@ func (s *Session) CreateNode(ctx context.Context, node *Node) error {
 	if err := errIn(c.Exec(docmanService, "CreateNode", s.auth, oscript.M{"node": node}, node)); err != nil {
 		return err
 	}
+
+	s.events.publish(Event{Type: NodeCreated, NodeID: node.ID, Parent: node.Parent})
 	return nil
 }
 
-// GetNode gets node
+// GetNode gets node, the node is got from the cache when it is enabled by Session.WithNodeCache.
+// The node with partial data is returned as well, use GetFullNode when the metadata of the node is needed.
 func (s *Session) GetNode(ctx context.Context, id int64) (*Node, error) {
-	c, err := s.connect(ctx)
-	if err != nil {
-		return nil, err
+	if node, ok := s.nodes.get(id); ok {
+		return node, nil
 	}
-	defer c.Close()
 
 	var node Node
-	if err := errIn(c.Exec(docmanService, "GetNode", s.auth, oscript.M{"ID": id}, &node)); err != nil {
+	if err := s.exec(ctx, docmanService, "GetNode", oscript.M{"ID": id}, &node); err != nil {
 		return nil, err
 	}
+
+	if !node.PartialData { // the partial node is not cached, the next call may return full node
+		s.nodes.add(&node)
+	}
 	return &node, nil
 }
 
+// GetFullNode gets node as GetNode, the node with partial data is returned with PartialDataError.
+func (s *Session) GetFullNode(ctx context.Context, id int64) (*Node, error) {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.PartialData {
+		return node, &PartialDataError{Node: node}
+	}
+	return node, nil
+}
+
+// GetNodeIfModifiedSince gets node when it has been modified after t, otherwise ErrNotModified is returned.
+// ModifyDate is checked by GetNodeSummaries, the node is got only when it has been modified, so the jobs
+// refreshing many unchanged nodes do not load the server by GetNode.
+func (s *Session) GetNodeIfModifiedSince(ctx context.Context, id int64, t time.Time) (*Node, error) {
+	summaries, err := s.GetNodeSummaries(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(summaries) == 1 && !summaries[0].ModifyDate.After(t) {
+		return nil, ErrNotModified
+	}
+	return s.GetNode(ctx, id) // the error of the node which is not found or not accessible is returned by GetNode
+}
+
 // GetNodeByNickname gets node by nickname.
 func (s *Session) GetNodeByNickname(ctx context.Context, nickname string) (*Node, error) {
 	c, err := s.connect(ctx)
@@ -181,22 +260,80 @@ func (s *Session) GetNodeByNickname(ctx context.Context, nickname string) (*Node
 	return &node, nil
 }
 
-// GetCategory gets category.
-func (s *Session) GetCategory(ctx context.Context, id int64) (*Category, error) {
+// GetNodeByName gets node by name in the parent, returns nil when the node is not found.
+func (s *Session) GetNodeByName(ctx context.Context, parentID int64, name string) (*Node, error) {
 	c, err := s.connect(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer c.Close()
 
+	var node *Node
+	if err := errIn(c.Exec(docmanService, "GetNodeByName", s.auth, oscript.M{"parentID": parentID, "name": name}, &node)); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// GetNodeByPath gets node by path of the names separated by slash relative to the root node,
+// returns nil when the node is not found. The resolved ids are kept in the path cache of the endpoint.
+func (s *Session) GetNodeByPath(ctx context.Context, root int64, p string) (*Node, error) {
+	names := splitPath(p)
+
+	// the longest cached path
+	id, i := root, 0
+	for j := len(names); j > 0; j-- {
+		if v, ok := s.ep.paths.get(pathKey(root, names[:j]...)); ok {
+			id, i = v, j
+			break
+		}
+	}
+
+	if i == len(names) {
+		node, err := s.GetNode(ctx, id)
+		if re, ok := err.(*NodeRetrievalError); !ok || !re.NotFound() || i == 0 {
+			return node, err
+		}
+
+		// stale cache
+		s.ep.paths.removeID(id)
+		id, i = root, 0
+	}
+
+	var node *Node
+	for ; i < len(names); i++ {
+		n, err := s.GetNodeByName(ctx, id, names[i])
+		if err != nil || n == nil {
+			return nil, err
+		}
+
+		node, id = n, n.ID
+		s.ep.paths.add(pathKey(root, names[:i+1]...), id)
+	}
+	return node, nil
+}
+
+// splitPath splits path into names.
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// GetCategory gets category.
+func (s *Session) GetCategory(ctx context.Context, id int64) (*Category, error) {
 	var cat Category
-	if err := errIn(c.Exec(docmanService, "GetCategoryTemplate", s.auth, oscript.M{"categoryID": id}, &cat)); err != nil {
+	if err := s.exec(ctx, docmanService, "GetCategoryTemplate", oscript.M{"categoryID": id}, &cat); err != nil {
 		return nil, err
 	}
 	return &cat, nil
 }
 
 // UpdateNode updates node. Checks on update Catalog, Comment, Name, Position. Always updates the fields Metadata, Nickname.
+// Only the modified categories of the tracked ones are sent, the untracked categories are always sent.
+// The changes of the tracked categories are published in the event NodeUpdated, see Category.Track.
 func (s *Session) UpdateNode(ctx context.Context, node *Node) error {
 	c, err := s.connect(ctx)
 	if err != nil {
@@ -204,9 +341,61 @@ func (s *Session) UpdateNode(ctx context.Context, node *Node) error {
 	}
 	defer c.Close()
 
+	update := *node
+	update.Metadata = node.Metadata.modified()
+	if err := errIn(c.Exec(docmanService, "UpdateNode", s.auth, oscript.M{"node": &update}, nil)); err != nil {
+		return err
+	}
+
+	changes := node.Metadata.Changes()
+	for i := range node.Metadata.Categories { // the next changes are tracked from the saved values
+		if node.Metadata.Categories[i].Tracked() {
+			node.Metadata.Categories[i].Track()
+		}
+	}
+
+	s.nodes.remove(node.ID)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: node.ID, Changes: changes})
+	return nil
+}
+
+// NodeFields are the fields of the node updated by UpdateNodeFields, undefined field is left unchanged
+// and the empty value blanks the field, e.g. Nickname: NewNullable("").
+type NodeFields struct {
+	Name     Nullable[string]
+	Comment  Nullable[string]
+	Nickname Nullable[string]
+}
+
+// nodeFields is the node with only the defined fields, the server keeps the omitted fields as is.
+type nodeFields struct {
+	ID       int64            `oscript:"ID"`
+	Name     Nullable[string] `oscript:"Name,omitundef"`
+	Comment  Nullable[string] `oscript:"Comment,omitundef"`
+	Nickname Nullable[string] `oscript:"Nickname,omitundef"`
+
+	sdoName oscript.SDOName `oscript:"DocMan.Node,public"`
+}
+
+// UpdateNodeFields updates only the defined fields of the node, other fields are kept as is on the server,
+// so the concurrent updates of the other fields are not lost.
+func (s *Session) UpdateNodeFields(ctx context.Context, id int64, fields NodeFields) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	node := nodeFields{ID: id, Name: fields.Name, Comment: fields.Comment, Nickname: fields.Nickname}
 	if err := errIn(c.Exec(docmanService, "UpdateNode", s.auth, oscript.M{"node": node}, nil)); err != nil {
 		return err
 	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: id})
+	if fields.Name.Valid {
+		s.ep.paths.removeID(id)
+	}
 	return nil
 }
 
@@ -221,6 +410,10 @@ func (s *Session) DeleteNode(ctx context.Context, id int64) error {
 	if err := errIn(c.Exec(docmanService, "DeleteNode", s.auth, oscript.M{"ID": id}, nil)); err != nil {
 		return err
 	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeDeleted, NodeID: id})
+	s.ep.paths.removeID(id)
 	return nil
 }
 
@@ -235,6 +428,10 @@ func (s *Session) RenameNode(ctx context.Context, id int64, name string) error {
 	if err := errIn(c.Exec(docmanService, "RenameNode", s.auth, oscript.M{"ID": id, "newName": name}, nil)); err != nil {
 		return err
 	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: id})
+	s.ep.paths.removeID(id)
 	return nil
 }
 
@@ -263,6 +460,9 @@ func (s *Session) ReserveNode(ctx context.Context, id int64, user int64) error {
 	if err := errIn(c.Exec(docmanService, "ReserveNode", s.auth, oscript.M{"ID": id, "userID": user}, nil)); err != nil {
 		return err
 	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: id})
 	return nil
 }
 
@@ -277,6 +477,9 @@ func (s *Session) UnreserveNode(ctx context.Context, id int64) error {
 	if err := errIn(c.Exec(docmanService, "UnreserveNode", s.auth, oscript.M{"ID": id}, nil)); err != nil {
 		return err
 	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: id})
 	return nil
 }
 
@@ -292,5 +495,249 @@ func (s *Session) CreateFolder(ctx context.Context, parentID int64, name, commen
 	if err := errIn(c.Exec(docmanService, "CreateFolder", s.auth, oscript.M{"parentID": parentID, "name": name, "comment": comment, "metadata": metadata}, &node)); err != nil {
 		return nil, err
 	}
+
+	s.events.publish(Event{Type: NodeCreated, NodeID: node.ID, Parent: parentID})
 	return &node, nil
 }
+
+// CreateFolderPath creates the missing folders of the path of the names separated by slash relative to the root node
+// and returns the last one, the existing nodes are kept as is. The created folders get the metadata.
+// The folder created concurrently by other caller is used instead of DuplicateNameError.
+func (s *Session) CreateFolderPath(ctx context.Context, root int64, p string, metadata Metadata) (*Node, error) {
+	return s.createFolderNames(ctx, root, splitPath(p), metadata)
+}
+
+// createFolderNames creates the missing folders of the names like CreateFolderPath, the names may contain slash,
+// such paths are not cached.
+func (s *Session) createFolderNames(ctx context.Context, root int64, names []string, metadata Metadata) (*Node, error) {
+	if len(names) == 0 {
+		return s.GetNode(ctx, root)
+	}
+
+	var (
+		node  *Node
+		slash bool
+	)
+	id := root
+	for i, name := range names {
+		n, err := s.GetNodeByName(ctx, id, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if n == nil {
+			if n, err = s.CreateFolder(ctx, id, name, "", metadata); err != nil {
+				if _, ok := err.(*DuplicateNameError); !ok {
+					return nil, err
+				}
+
+				// created concurrently
+				dup := err
+				if n, err = s.GetNodeByName(ctx, id, name); err != nil {
+					return nil, err
+				}
+
+				if n == nil {
+					return nil, dup
+				}
+			}
+		}
+
+		node, id = n, n.ID
+		if slash = slash || strings.Contains(name, "/"); !slash {
+			s.ep.paths.add(pathKey(root, names[:i+1]...), id)
+		}
+	}
+	return node, nil
+}
+
+// ListNodes returns children of the node.
+func (s *Session) ListNodes(ctx context.Context, parentID int64) ([]Node, error) {
+	return s.ListContainer(ctx, parentID, ListOptions{})
+}
+
+// SortField is a field of the node to sort listing of the container.
+type SortField string
+
+const (
+	SortName       SortField = "Name"
+	SortModifyDate SortField = "ModifyDate"
+	SortSize       SortField = "DataSize"
+)
+
+// ListOptions configures listing of the container.
+type ListOptions struct {
+	Sort    SortField // sorts on the server side
+	Desc    bool      // sorts in descending order
+	Types   []string  // returns only nodes of the types
+	Partial bool      // returns only ID, Name, Type and few other fields, it cuts payload for large containers
+}
+
+// ListContainer returns children of the node according to the options.
+func (s *Session) ListContainer(ctx context.Context, parentID int64, opts ListOptions) ([]Node, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	args := oscript.M{"parentID": parentID, "partialData": opts.Partial}
+	if opts.Sort != "" {
+		args["sortBy"] = string(opts.Sort)
+		args["sortDescending"] = opts.Desc
+	}
+
+	if len(opts.Types) != 0 {
+		args["types"] = opts.Types
+	}
+
+	var nodes []Node
+	if err := errIn(c.Exec(docmanService, "ListNodes", s.auth, args, &nodes)); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// ResolvePartial replaces the nodes with partial data, e.g. listed with ListOptions.Partial, by the full nodes
+// got by GetFullNode. PartialDataError is returned when the server returns partial data again, the nodes before
+// the failed one are replaced.
+func (s *Session) ResolvePartial(ctx context.Context, nodes []Node) error {
+	for i := range nodes {
+		if !nodes[i].PartialData {
+			continue
+		}
+
+		node, err := s.GetFullNode(ctx, nodes[i].ID)
+		if err != nil {
+			return err
+		}
+		nodes[i] = *node
+	}
+	return nil
+}
+
+// ColumnDescriptor describes a column of the container listing as it is configured on the server.
+type ColumnDescriptor struct {
+	Key       string `oscript:"Key"`      // key of the column, e.g. Name, ModifyDate or attribute key of the category
+	Name      string `oscript:"Name"`     // display name
+	DataType  string `oscript:"DataType"` // type of the values, e.g. String, Integer, Date
+	Sortable  bool   `oscript:"Sortable"`
+	Facet     bool   `oscript:"Facet"` // values of the column are used as filter of the listing
+	Width     int    `oscript:"Width"`
+	Alignment string `oscript:"Alignment"`
+}
+
+// GetNodeColumns returns the columns of the container in the order they are displayed.
+func (s *Session) GetNodeColumns(ctx context.Context, id int64) ([]ColumnDescriptor, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var columns []ColumnDescriptor
+	if err := errIn(c.Exec(docmanService, "GetNodeColumns", s.auth, oscript.M{"ID": id}, &columns)); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// NodeSummary is a short information about the node for listings.
+type NodeSummary struct {
+	ID         int64     `oscript:"ID"`
+	Name       string    `oscript:"Name"`
+	Type       string    `oscript:"Type"`
+	Size       int64     `oscript:"DataSize"` // size of the latest version of the document or number of the children
+	ModifyDate time.Time `oscript:"ModifyDate"`
+	Reserved   bool      `oscript:"Reserved"`
+	ReservedBy int64     `oscript:"ReservedBy"`
+}
+
+// GetNodeSummaries returns summaries of the nodes in one call, it is much cheaper than GetNode per node.
+// The nodes which are not found or not accessible are omitted.
+func (s *Session) GetNodeSummaries(ctx context.Context, ids []int64) ([]NodeSummary, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var summaries []NodeSummary
+	if err := errIn(c.Exec(docmanService, "GetNodeSummaries", s.auth, oscript.M{"IDs": ids}, &summaries)); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// WalkFunc is the type of the function called for each node visited by Walk.
+// The path is the names of the nodes from the root joined by slash.
+type WalkFunc func(path string, node *Node) error
+
+// Walk walks the tree of the nodes rooted at id, calling fn for each node including root.
+func (s *Session) Walk(ctx context.Context, id int64, fn WalkFunc) error {
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.walk(ctx, node.Name, node, fn)
+}
+
+func (s *Session) walk(ctx context.Context, p string, node *Node, fn WalkFunc) error {
+	if err := fn(p, node); err != nil {
+		return err
+	}
+
+	if !node.IsContainer {
+		return nil
+	}
+
+	children, err := s.ListNodes(ctx, node.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range children {
+		if err := s.walk(ctx, path.Join(p, children[i].Name), &children[i], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetNodeFeature adds or replaces feature of the node.
+func (s *Session) SetNodeFeature(ctx context.Context, id int64, feature Feature) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := errIn(c.Exec(docmanService, "SetNodeFeature", s.auth, oscript.M{"ID": id, "feature": feature}, nil)); err != nil {
+		return err
+	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: id})
+	return nil
+}
+
+// RemoveNodeFeature removes feature of the node by name.
+func (s *Session) RemoveNodeFeature(ctx context.Context, id int64, name string) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := errIn(c.Exec(docmanService, "RemoveNodeFeature", s.auth, oscript.M{"ID": id, "name": name}, nil)); err != nil {
+		return err
+	}
+
+	s.nodes.remove(id)
+	s.events.publish(Event{Type: NodeUpdated, NodeID: id})
+	return nil
+}
@@ -3,12 +3,16 @@ package ot
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/itcomusic/ot/pkg/oscript"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,27 +28,27 @@ var testNode = &Node{
 	Feature: []Feature{
 		{
 			Name:         "Name",
-			BooleanValue: func() *bool { b := true; return &b }(),
+			BooleanValue: NewNullable(true),
 			Type:         "Boolean",
 		},
 		{
 			Name:      "Name",
-			DateValue: func() *time.Time { d := time.Date(2019, 04, 22, 17, 00, 01, 0, time.UTC); return &d }(),
+			DateValue: NewNullable(time.Date(2019, 04, 22, 17, 00, 01, 0, time.UTC)),
 			Type:      "Date",
 		},
 		{
 			Name:         "Name",
-			IntegerValue: func() *int { i := 1; return &i }(),
+			IntegerValue: NewNullable(1),
 			Type:         "Integer",
 		},
 		{
 			Name:      "Name",
-			LongValue: func() *float64 { f := 1.1; return &f }(),
+			LongValue: NewNullable(1.1),
 			Type:      "Long",
 		},
 		{
 			Name:        "Name",
-			StringValue: func() *string { s := "string"; return &s }(),
+			StringValue: NewNullable("string"),
 			Type:        "String",
 		},
 	},
@@ -194,6 +198,34 @@ func Test_GetNodeByNickname(t *testing.T) {
 	assert.Equal(t, testNode, node)
 }
 
+func Test_GetNodeIfModifiedSince(t *testing.T) {
+	t.Parallel()
+
+	var methods []string
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		methods = append(methods, req["ServiceMethod"].(string))
+		switch req["ServiceMethod"] {
+		case "GetNodeSummaries":
+			assert.Equal(t, []interface{}{int64(1)}, req["Arguments"].(map[string]interface{})["IDs"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=1,'ModifyDate'=D/2019/4/9:12:32:8>}>")
+		default:
+			bytes, err := ioutil.ReadFile("testdata/get-node")
+			require.Nil(t, err)
+			w.Write(bytes)
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	node, err := s.GetNodeIfModifiedSince(context.Background(), 1, testNode.ModifyDate.Add(-time.Second))
+	require.Nil(t, err)
+	assert.Equal(t, testNode, node)
+
+	node, err = s.GetNodeIfModifiedSince(context.Background(), 1, testNode.ModifyDate)
+	assert.Equal(t, ErrNotModified, err)
+	assert.Nil(t, node)
+	assert.Equal(t, []string{"GetNodeSummaries", "GetNode", "GetNodeSummaries"}, methods, "the unchanged node is not got")
+}
+
 func Test_GetCategory(t *testing.T) {
 	t.Parallel()
 
@@ -254,6 +286,23 @@ func Test_UpdateNode(t *testing.T) {
 
 }
 
+func Test_UpdateNodeFields(t *testing.T) {
+	t.Parallel()
+
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "UpdateNode", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{
+			"_SDOName": "DocMan.Node",
+			"ID":       int64(1),
+			"Comment":  "new",
+			"Nickname": "",
+		}, req["Arguments"].(map[string]interface{})["node"])
+		w.WriteString("A<1,?,'_Status'=0>")
+		assert.Nil(t, w.Flush())
+	}).UpdateNodeFields(context.Background(), 1, NodeFields{Comment: NewNullable("new"), Nickname: NewNullable("")})
+	require.Nil(t, err)
+}
+
 func Test_DeleteNode(t *testing.T) {
 
 }
@@ -261,3 +310,225 @@ func Test_DeleteNode(t *testing.T) {
 func Test_RenameNode(t *testing.T) {
 
 }
+
+func Test_SetNodeFeature(t *testing.T) {
+	t.Parallel()
+
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, fmt.Sprint(map[string]interface{}{
+			"_ApiName":      "InvokeService",
+			"_UserName":     "u",
+			"_UserPassword": "p",
+			"ServiceName":   "DocumentManagement",
+			"ServiceMethod": "SetNodeFeature",
+			"Arguments": map[string]interface{}{
+				"ID": int64(1),
+				"feature": map[string]interface{}{
+					"_SDOName":     "DocMan.NodeFeature",
+					"Name":         "DoNotConvert",
+					"Type":         "Boolean",
+					"BooleanValue": true,
+				},
+			},
+		}), fmt.Sprint(req))
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		assert.Nil(t, w.Flush())
+	}).SetNodeFeature(context.Background(), 1, FeatureBool("DoNotConvert", true))
+	require.Nil(t, err)
+}
+
+func Test_RemoveNodeFeature(t *testing.T) {
+	t.Parallel()
+
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "RemoveNodeFeature", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{"ID": int64(1), "name": "DoNotConvert"}, req["Arguments"])
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		assert.Nil(t, w.Flush())
+	}).RemoveNodeFeature(context.Background(), 1, "DoNotConvert")
+	require.Nil(t, err)
+}
+
+func Test_ListContainer(t *testing.T) {
+	t.Parallel()
+
+	nodes, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "ListNodes", req["ServiceMethod"])
+		assert.Equal(t, fmt.Sprint(map[string]interface{}{
+			"parentID":       int64(1),
+			"partialData":    true,
+			"sortBy":         "ModifyDate",
+			"sortDescending": true,
+			"types":          []interface{}{"Document"},
+		}), fmt.Sprint(req["Arguments"]))
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=2,'Name'='doc','Type'='Document','PartialData'=true>}>")
+		assert.Nil(t, w.Flush())
+	}).ListContainer(context.Background(), 1, ListOptions{Sort: SortModifyDate, Desc: true, Types: []string{"Document"}, Partial: true})
+	require.Nil(t, err)
+	assert.Equal(t, []Node{{ID: 2, Name: "doc", Type: "Document", PartialData: true}}, nodes)
+}
+
+func TestSession_ResolvePartial(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "GetNode", req["ServiceMethod"])
+		switch req["Arguments"].(map[string]interface{})["ID"] {
+		case int64(2):
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=2,'Name'='doc','Comment'='full','PartialData'=false>>")
+		default:
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=3,'Name'='secret','PartialData'=true>>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	nodes := []Node{{ID: 1, Name: "full"}, {ID: 2, Name: "doc", PartialData: true}, {ID: 3, Name: "secret", PartialData: true}}
+	err := s.ResolvePartial(context.Background(), nodes)
+
+	var perr *PartialDataError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "ot: node 3 has partial data", err.Error())
+	assert.Equal(t, "secret", perr.Node.Name)
+	assert.Equal(t, []Node{{ID: 1, Name: "full"}, {ID: 2, Name: "doc", Comment: "full"}, {ID: 3, Name: "secret", PartialData: true}}, nodes)
+}
+
+func Test_GetNodeColumns(t *testing.T) {
+	t.Parallel()
+
+	columns, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "GetNodeColumns", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{"ID": int64(1)}, req["Arguments"])
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'={" +
+			"A<1,?,'Key'='Name','Name'='Name','DataType'='String','Sortable'=true,'Width'=200>," +
+			"A<1,?,'Key'='2000_2','Name'='Status','DataType'='String','Facet'=true,'Alignment'='left'>}>")
+		assert.Nil(t, w.Flush())
+	}).GetNodeColumns(context.Background(), 1)
+
+	require.Nil(t, err)
+	assert.Equal(t, []ColumnDescriptor{
+		{Key: "Name", Name: "Name", DataType: "String", Sortable: true, Width: 200},
+		{Key: "2000_2", Name: "Status", DataType: "String", Facet: true, Alignment: "left"},
+	}, columns)
+}
+
+func Test_GetNodeSummaries(t *testing.T) {
+	t.Parallel()
+
+	summaries, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "GetNodeSummaries", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{"IDs": []interface{}{int64(1), int64(2)}}, req["Arguments"])
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'={" +
+			"A<1,?,'ID'=1,'Name'='a.txt','Type'='Document','DataSize'=10,'ModifyDate'=D/2020/1/2:10:0:0,'Reserved'=true,'ReservedBy'=1000>," +
+			"A<1,?,'ID'=2,'Name'='b','Type'='Folder','DataSize'=3>}>")
+		assert.Nil(t, w.Flush())
+	}).GetNodeSummaries(context.Background(), []int64{1, 2})
+
+	require.Nil(t, err)
+	assert.Equal(t, []NodeSummary{
+		{ID: 1, Name: "a.txt", Type: "Document", Size: 10, ModifyDate: time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC), Reserved: true, ReservedBy: 1000},
+		{ID: 2, Name: "b", Type: "Folder", Size: 3},
+	}, summaries)
+}
+
+func Test_CreateFolderPath(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var created []string
+	node, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetNodeByName":
+			switch args["name"] {
+			case "a":
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=2,'Name'='a'>>")
+			case "b":
+				mu.Lock()
+				raced := len(created) != 0
+				mu.Unlock()
+
+				if raced {
+					w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=3,'Name'='b'>>")
+					break
+				}
+				w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+			default:
+				w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+			}
+
+		case "CreateFolder":
+			mu.Lock()
+			created = append(created, args["name"].(string))
+			mu.Unlock()
+
+			switch args["name"] {
+			case "b":
+				w.WriteString("A<1,?,'_Status'=1,'_StatusMessage'='DocMan.DuplicateName','_errMsg'='duplicate'>")
+			default:
+				assert.Equal(t, int64(3), args["parentID"])
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=4,'Name'='c'>>")
+			}
+		}
+		assert.Nil(t, w.Flush())
+	}).CreateFolderPath(context.Background(), 1, "/a/b/c/", Metadata{})
+
+	require.Nil(t, err)
+	assert.Equal(t, &Node{ID: 4, Name: "c"}, node)
+	assert.Equal(t, []string{"b", "c"}, created)
+}
+
+func BenchmarkNode(b *testing.B) {
+	data, err := ioutil.ReadFile("testdata/get-node")
+	require.Nil(b, err)
+
+	var resp struct {
+		Results Node `oscript:"Results"`
+	}
+	require.Nil(b, oscript.Unmarshal(data, &resp))
+
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			var resp struct {
+				Results Node `oscript:"Results"`
+			}
+			if err := oscript.Unmarshal(data, &resp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := oscript.Marshal(&resp.Results); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSession_GetFullNode(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=3,'Name'='secret','ParentID'=1,'PartialData'=true>>")
+		assert.Nil(t, w.Flush())
+	})
+
+	node, err := s.GetNode(context.Background(), 3)
+	require.Nil(t, err, "the callers needing only the name and the parent get the partial node")
+	assert.Equal(t, int64(1), node.Parent)
+
+	node, err = s.GetFullNode(context.Background(), 3)
+	var perr *PartialDataError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "secret", node.Name)
+	assert.Equal(t, node, perr.Node)
+}
@@ -0,0 +1,102 @@
+package ot
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// nodeCache is a LRU cache of the nodes keyed by id, the entries expire after ttl. A nil cache does not store anything.
+type nodeCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[int64]*list.Element
+	now   func() time.Time
+}
+
+type nodeEntry struct {
+	node    Node
+	expires time.Time
+}
+
+func newNodeCache(size int, ttl time.Duration) *nodeCache {
+	return &nodeCache{size: size, ttl: ttl, ll: list.New(), items: make(map[int64]*list.Element), now: time.Now}
+}
+
+// get returns copy of the cached node.
+func (c *nodeCache) get(id int64) (*Node, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*nodeEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		c.ll.Remove(e)
+		delete(c.items, id)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(e)
+	node := copyNode(&entry.node)
+	return &node, true
+}
+
+func (c *nodeCache) add(node *Node) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &nodeEntry{node: copyNode(node), expires: c.now().Add(c.ttl)}
+	if e, ok := c.items[node.ID]; ok {
+		e.Value = entry
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.items[node.ID] = c.ll.PushFront(entry)
+	if c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*nodeEntry).node.ID)
+	}
+}
+
+func (c *nodeCache) remove(id int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[id]; ok {
+		c.ll.Remove(e)
+		delete(c.items, id)
+	}
+}
+
+// copyNode returns copy of the node with the copies of the categories, so the changes of the values
+// by Category.Set are not shared with the cache.
+func copyNode(node *Node) Node {
+	cp := *node
+	if node.Metadata.Categories != nil {
+		cp.Metadata.Categories = make([]Category, len(node.Metadata.Categories))
+		for i := range node.Metadata.Categories {
+			cp.Metadata.Categories[i] = *node.Metadata.Categories[i].Copy()
+		}
+	}
+	return cp
+}
@@ -0,0 +1,99 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newNodeCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.add(&Node{ID: 1, Name: "a"})
+	c.add(&Node{ID: 2, Name: "b"})
+	c.get(1)
+	c.add(&Node{ID: 3, Name: "c"}) // evicts 2
+
+	_, ok := c.get(2)
+	assert.False(t, ok)
+
+	node, ok := c.get(1)
+	require.True(t, ok)
+	assert.Equal(t, "a", node.Name)
+
+	node.Name = "changed"
+	node, _ = c.get(1)
+	assert.Equal(t, "a", node.Name)
+
+	c.remove(1)
+	_, ok = c.get(1)
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.get(3)
+	assert.False(t, ok)
+
+	var nilCache *nodeCache
+	nilCache.add(&Node{ID: 1})
+	_, ok = nilCache.get(1)
+	assert.False(t, ok)
+}
+
+func TestSession_WithNodeCache(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "GetNode":
+			atomic.AddInt32(&calls, 1)
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='gopher'>>")
+		default:
+			w.WriteString("A<1,?,'_Status'=0>")
+		}
+		assert.Nil(t, w.Flush())
+	}).WithNodeCache(10, 0)
+
+	for i := 0; i < 2; i++ {
+		node, err := s.GetNode(context.Background(), 1)
+		require.Nil(t, err)
+		assert.Equal(t, "gopher", node.Name)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	require.Nil(t, s.RenameNode(context.Background(), 1, "new"))
+	_, err := s.GetNode(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestSession_WithNodeCacheCopiesCategories(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "GetNode", req["ServiceMethod"])
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Metadata'=A<1,?,'AttributeGroups'={A<1,?,'DisplayName'='cat','Key'='1.1','Type'='Category','Values'={" +
+			"A<1,?,'Description'='Name','Key'='1.1.2','Values'={'saved'},'_SDOName'='Core.StringValue'>}>}>>>")
+		assert.Nil(t, w.Flush())
+	}).WithNodeCache(10, 0)
+
+	node, err := s.GetNode(context.Background(), 1)
+	require.Nil(t, err)
+	require.Nil(t, node.Metadata.Find("cat").Set(AttrString("Name", "unsaved")))
+	node.Metadata.Categories[0].Data[0].Description = "changed"
+
+	node, err = s.GetNode(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, "Name", node.Metadata.Categories[0].Data[0].Description)
+	assert.Equal(t, []interface{}{"saved"}, node.Metadata.Categories[0].Data[0].Value, "the unsaved value is not cached")
+}
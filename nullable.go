@@ -0,0 +1,50 @@
+package ot
+
+import (
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// Nullable is a value which may be undefined, undefined oscript value decodes to invalid Nullable
+// and invalid Nullable encodes as undefined.
+type Nullable[T any] struct {
+	Value T
+	Valid bool // Valid is true if Value is defined
+}
+
+// NewNullable returns valid Nullable with the value.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Valid: true}
+}
+
+// Get returns the value and reports whether it is defined.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.Valid
+}
+
+// IsUndefined implements the oscript.Undefiner interface.
+func (n Nullable[T]) IsUndefined() bool {
+	return !n.Valid
+}
+
+// MarshalOscript implements the oscript.Marshaler interface.
+func (n Nullable[T]) MarshalOscript() ([]byte, error) {
+	if !n.Valid {
+		return []byte("?"), nil
+	}
+	return oscript.Marshal(n.Value)
+}
+
+// UnmarshalOscript implements the oscript.Unmarshaler interface.
+func (n *Nullable[T]) UnmarshalOscript(data []byte) error {
+	if len(data) == 1 && data[0] == '?' {
+		*n = Nullable[T]{}
+		return nil
+	}
+
+	var v T
+	if err := oscript.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*n = NewNullable(v)
+	return nil
+}
@@ -0,0 +1,36 @@
+package ot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+func TestNullable(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		Int  Nullable[int]       `oscript:"Int"`
+		Date Nullable[time.Time] `oscript:"Date"`
+		Str  Nullable[string]    `oscript:"Str,omitundef"`
+	}
+
+	b, err := oscript.Marshal(value{Int: NewNullable(0)})
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'Int'=0,'Date'=?>", string(b))
+
+	var v value
+	require.Nil(t, oscript.Unmarshal([]byte("A<1,?,'Int'=?,'Date'=D/2019/4/22:17:0:1,'Str'=''>"), &v))
+	assert.Equal(t, value{
+		Date: NewNullable(time.Date(2019, 4, 22, 17, 0, 1, 0, time.UTC)),
+		Str:  NewNullable(""),
+	}, v)
+
+	s, ok := v.Str.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "", s)
+}
@@ -0,0 +1,192 @@
+package ot
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"time"
+
+	"golang.org/x/text/encoding"
+)
+
+// Dialer is the interface implemented by types that create connections to the server, e.g. through
+// an SSH tunnel or in-memory pipe in tests. The connection is used for one call and closed after it.
+type Dialer interface {
+	DialContext(ctx context.Context) (io.ReadWriteCloser, error)
+}
+
+// Metrics is the interface implemented by types that collect statistics of the calls.
+type Metrics interface {
+	// ObserveCall is called after every call with the service method, duration and error of the call.
+	ObserveCall(serviceMethod string, d time.Duration, err error)
+}
+
+// WaitMetrics is the optional interface of Metrics which collects time spent waiting for the rate limiter.
+type WaitMetrics interface {
+	ObserveWait(d time.Duration)
+}
+
+// options is a configuration of the endpoint.
+type options struct {
+	tls         *tls.Config
+	dialTimeout time.Duration
+	poolSize    int
+	debug       io.Writer
+	debugOpts   DebugOptions
+	metrics     Metrics
+	capture     CaptureSink
+	drift       func(SchemaDrift)
+	charset     encoding.Encoding
+	retry       RetryPolicy
+	rate        float64
+	burst       int
+	pathCache   int
+	appID       string
+	defaultPort int
+
+	handshakeTimeout time.Duration
+	idleTimeout      time.Duration
+	dialer           Dialer
+}
+
+// Option configures the endpoint.
+type Option func(*options)
+
+// WithTLS sets TLS configuration of the connection.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithDialer sets dialer of the connections used instead of TCP connection to the address of the endpoint,
+// the options of TCP connection (WithTLS, WithDialTimeout, WithIdleTimeout) are not applied to it.
+// The pool, rate limit and debug options wrap the dialer.
+func WithDialer(d Dialer) Option {
+	return func(o *options) {
+		o.dialer = d
+	}
+}
+
+// WithDialTimeout sets maximum amount of time a dial will wait for a connect to complete.
+func WithDialTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = d
+	}
+}
+
+// WithHandshakeTimeout sets maximum amount of time waiting for the server to accept the open request
+// which precedes every request. The request is sent only after the open request is accepted,
+// so the half-open connection fails in d rather than at the deadline of the call.
+func WithHandshakeTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.handshakeTimeout = d
+	}
+}
+
+// WithIdleTimeout fails the call when the connection makes no progress for d. Unlike the deadline of the context
+// it is extended on every read and write, so the long transfer over the slow link does not fail while data flows.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = d
+	}
+}
+
+// WithPool limits the number of simultaneously open connections to the server.
+func WithPool(size int) Option {
+	return func(o *options) {
+		o.poolSize = size
+	}
+}
+
+// WithDebugWriter writes in w every request and response except content of the files.
+func WithDebugWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.debug = w
+	}
+}
+
+// DebugOptions limits output of the debug, it is useful for large transfers.
+type DebugOptions struct {
+	MaxBytes int  // maximum bytes of the message written, the middle of the longer message is cut, zero is unlimited
+	Sample   int  // writes every Nth call only, zero or one writes every call
+	JSON     bool // writes JSON lines instead of text
+}
+
+// WithDebugOptions sets limits of the debug enabled by WithDebugWriter.
+func WithDebugOptions(opts DebugOptions) Option {
+	return func(o *options) {
+		o.debugOpts = opts
+	}
+}
+
+// WithRateLimit limits the rate of the calls to perSecond calls per second with maximum burst of the calls.
+// The call waits for the permission until the context is done, use WithPool to cap the number of calls in flight.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(o *options) {
+		o.rate = perSecond
+		o.burst = burst
+	}
+}
+
+// WithMetrics sets collector of the calls statistics.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithRetry sets policy of retrying failed connection attempts.
+func WithRetry(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithPathCache enables cache of the node ids resolved by path with maximum size entries.
+// The cache is used by GetNodeByPath and UpsertFile.
+func WithPathCache(size int) Option {
+	return func(o *options) {
+		o.pathCache = size
+	}
+}
+
+// WithAppID sets identifier of the application which is sent in every request for server-side audit.
+func WithAppID(id string) Option {
+	return func(o *options) {
+		o.appID = id
+	}
+}
+
+// WithDefaultPort sets port of the address without the port, the default is 2099.
+func WithDefaultPort(port int) Option {
+	return func(o *options) {
+		o.defaultPort = port
+	}
+}
+
+// WithCapture sends the messages of every call to the sink, e.g. to archive the requests changing data for audit.
+// The passwords and the tokens of the requests and the responses are redacted, the content of the files is not captured.
+func WithCapture(sink CaptureSink) Option {
+	return func(o *options) {
+		o.capture = sink
+	}
+}
+
+// WithSchemaDrift calls f once for every field of the results which is unknown to the struct it is decoded to,
+// e.g. to learn about the fields added by the upgrade of the server.
+func WithSchemaDrift(f func(SchemaDrift)) Option {
+	return func(o *options) {
+		o.drift = f
+	}
+}
+
+// WithCharset sets the charset of the server which is not configured for UTF-8, e.g. charmap.ISO8859_1 or
+// charmap.Windows1251 of golang.org/x/text/encoding/charmap. The strings of the requests are transcoded to it,
+// the runes unrepresentable in it are escaped, and the strings of the responses are transcoded to UTF-8.
+// The content of the files is not transcoded.
+func WithCharset(cs encoding.Encoding) Option {
+	return func(o *options) {
+		o.charset = cs
+	}
+}
@@ -0,0 +1,107 @@
+package ot
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// Cursor prepares the arguments of the next page from the decoded page, it returns false when the pages are exhausted.
+type Cursor[P any] func(page *P, args oscript.M) bool
+
+// PageNumber returns cursor which increments the page number in the argument key and stops on the page
+// with less than size elements. The arguments of the call must contain the number of the first page as any integer kind,
+// the paging stops after the first page when the number is missing or is not an integer.
+func PageNumber[E any](key string, size int) Cursor[[]E] {
+	return func(page *[]E, args oscript.M) bool {
+		if len(*page) < size {
+			return false
+		}
+
+		n, ok := nextNumber(args[key])
+		if !ok {
+			return false
+		}
+		args[key] = n
+		return true
+	}
+}
+
+// nextNumber returns the incremented integer of the same type, false is returned when v is not an integer.
+func nextNumber(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		next := reflect.New(rv.Type()).Elem()
+		next.SetInt(rv.Int() + 1)
+		return next.Interface(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next := reflect.New(rv.Type()).Elem()
+		next.SetUint(rv.Uint() + 1)
+		return next.Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+// NextMarker returns cursor which passes the marker of the next page in the argument key
+// and stops when the marker is empty.
+func NextMarker[P any](key string, marker func(page *P) string) Cursor[P] {
+	return func(page *P, args oscript.M) bool {
+		m := marker(page)
+		if m == "" {
+			return false
+		}
+
+		args[key] = m
+		return true
+	}
+}
+
+// Pager calls the service method repeatedly with the arguments of the cursor and decodes the pages.
+type Pager[P any] struct {
+	s             *Session
+	serviceMethod string
+	args          oscript.M
+	next          Cursor[P]
+
+	page P
+	done bool
+	err  error
+}
+
+// NewPager returns pager of the service method, the arguments are copied and changed by the cursor for every next page.
+func NewPager[P any](s *Session, serviceMethod string, args oscript.M, next Cursor[P]) *Pager[P] {
+	a := make(oscript.M, len(args))
+	for k, v := range args {
+		a[k] = v
+	}
+	return &Pager[P]{s: s, serviceMethod: serviceMethod, args: a, next: next}
+}
+
+// Next gets the next page, it returns false when the pages are exhausted or an error occurred.
+func (p *Pager[P]) Next(ctx context.Context) bool {
+	if p.done || p.err != nil {
+		return false
+	}
+
+	var page P
+	if p.err = p.s.Call(ctx, p.serviceMethod, p.args, &page); p.err != nil {
+		return false
+	}
+
+	p.page = page
+	p.done = !p.next(&p.page, p.args)
+	return true
+}
+
+// Page returns the current page.
+func (p *Pager[P]) Page() P {
+	return p.page
+}
+
+// Err returns the error occurred during paging.
+func (p *Pager[P]) Err() error {
+	return p.err
+}
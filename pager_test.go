@@ -0,0 +1,83 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+func TestPager_PageNumber(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["Arguments"].(map[string]interface{})["page"] {
+		case int64(1):
+			w.WriteString("A<1,?,'_Status'=0,'Results'={1,2}>")
+		case int64(2):
+			w.WriteString("A<1,?,'_Status'=0,'Results'={3}>")
+		default:
+			t.Errorf("unexpected page %v", req["Arguments"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	args := oscript.M{"page": 1}
+	var items []int
+	p := NewPager(s, "service.method", args, PageNumber[int]("page", 2))
+	for p.Next(context.Background()) {
+		items = append(items, p.Page()...)
+	}
+	require.Nil(t, p.Err())
+	assert.Equal(t, []int{1, 2, 3}, items)
+	assert.Equal(t, oscript.M{"page": 1}, args)
+}
+
+type markerPage struct {
+	Items []string `oscript:"Items"`
+	Next  string   `oscript:"Next"`
+}
+
+func TestPager_NextMarker(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		if m, ok := req["Arguments"].(map[string]interface{})["marker"]; ok {
+			w.WriteString(fmt.Sprintf("A<1,?,'_Status'=0,'Results'=A<1,?,'Items'={'%s'},'Next'=''>>", m))
+		} else {
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'Items'={'a'},'Next'='b'>>")
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	var items []string
+	p := NewPager(s, "service.method", nil, NextMarker("marker", func(p *markerPage) string { return p.Next }))
+	for p.Next(context.Background()) {
+		items = append(items, p.Page().Items...)
+	}
+	require.Nil(t, p.Err())
+	assert.Equal(t, []string{"a", "b"}, items)
+}
+
+func TestPageNumber_Kinds(t *testing.T) {
+	t.Parallel()
+
+	next := PageNumber[int]("page", 1)
+	page := []int{1}
+	for _, v := range []interface{}{1, int64(1), int32(1), uint(1)} {
+		args := oscript.M{"page": v}
+		assert.True(t, next(&page, args))
+		assert.EqualValues(t, 2, args["page"])
+		assert.IsType(t, v, args["page"])
+	}
+
+	for _, args := range []oscript.M{{}, {"page": 1.0}, {"page": "1"}} {
+		assert.False(t, next(&page, args), "paging stops on %v", args)
+	}
+}
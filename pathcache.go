@@ -0,0 +1,105 @@
+package ot
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pathCache is a LRU cache of the node ids keyed by path. A nil cache does not store anything.
+type pathCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type pathEntry struct {
+	key string
+	id  int64
+}
+
+func newPathCache(size int) *pathCache {
+	return &pathCache{size: size, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// pathKey returns key of the names relative to the root node.
+func pathKey(root int64, names ...string) string {
+	return strconv.FormatInt(root, 10) + "/" + strings.Join(names, "/")
+}
+
+func (c *pathCache) get(key string) (int64, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*pathEntry).id, true
+}
+
+func (c *pathCache) add(key string, id int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*pathEntry).id = id
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&pathEntry{key: key, id: id})
+	if c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*pathEntry).key)
+	}
+}
+
+// remove removes the path and all paths under it.
+func (c *pathCache) remove(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(key)
+}
+
+func (c *pathCache) removeLocked(key string) {
+	for k, e := range c.items {
+		if k == key || strings.HasPrefix(k, key+"/") {
+			c.ll.Remove(e)
+			delete(c.items, k)
+		}
+	}
+}
+
+// removeID removes the paths of the node and all paths under them.
+func (c *pathCache) removeID(id int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeLocked(strconv.FormatInt(id, 10))
+	for k, e := range c.items {
+		if e.Value.(*pathEntry).id == id {
+			c.removeLocked(k)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathCache(t *testing.T) {
+	t.Parallel()
+
+	c := newPathCache(2)
+	c.add(pathKey(1, "a"), 2)
+	c.add(pathKey(1, "a", "b"), 3)
+	c.get(pathKey(1, "a"))
+	c.add(pathKey(1, "c"), 4) // evicts 1/a/b
+
+	_, ok := c.get(pathKey(1, "a", "b"))
+	assert.False(t, ok)
+
+	id, ok := c.get(pathKey(1, "a"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), id)
+
+	c.add(pathKey(1, "a", "b"), 3)
+	c.removeID(2)
+	_, ok = c.get(pathKey(1, "a"))
+	assert.False(t, ok)
+	_, ok = c.get(pathKey(1, "a", "b"))
+	assert.False(t, ok)
+
+	var nilCache *pathCache
+	nilCache.add("key", 1)
+	_, ok = nilCache.get("key")
+	assert.False(t, ok)
+}
+
+func TestSession_GetNodeByPath(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	endp := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		mu.Lock()
+		calls = append(calls, req["ServiceMethod"].(string))
+		mu.Unlock()
+
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetNodeByName":
+			if args["name"] == "a" {
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=2,'Name'='a'>>")
+			} else {
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=3,'Name'='b'>>")
+			}
+		case "GetNode":
+			assert.Equal(t, int64(3), args["ID"])
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=3,'Name'='b'>>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+	endp.paths = newPathCache(10)
+	ss := endp.User("u", "p")
+
+	for i := 0; i < 2; i++ {
+		node, err := ss.GetNodeByPath(context.Background(), 1, "/a/b")
+		require.Nil(t, err)
+		assert.Equal(t, int64(3), node.ID)
+	}
+	assert.Equal(t, []string{"GetNodeByName", "GetNodeByName", "GetNode"}, calls)
+
+	endp.InvalidatePath(1, "a")
+	_, ok := endp.paths.get(pathKey(1, "a", "b"))
+	assert.False(t, ok)
+}
+
+func TestSession_UpsertFileStalePath(t *testing.T) {
+	t.Parallel()
+
+	const content = "content"
+	var calls []string
+	endp := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		calls = append(calls, req["ServiceMethod"].(string))
+		switch req["ServiceMethod"] {
+		case "GetNode":
+			w.WriteString("A<1,?,'_Status'=903101,'_StatusMessage'='DocMan.NodeRetrievalError','_errMsg'='not found [E662241287]'>")
+		case "GetNodeByName":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		case "CreateSimpleDocument":
+			file := make([]byte, len(content))
+			_, err := io.ReadFull(r, file)
+			require.Nil(t, err)
+			assert.Equal(t, content, string(file))
+			w.WriteString("A<1,?,'_Status'=0,'Results'=5>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+	endp.paths = newPathCache(10)
+	endp.paths.add(pathKey(1, "name"), 3)
+
+	fa := &FileAttr{Name: "name", Size: int64(len(content))}
+	r := struct{ io.Reader }{strings.NewReader(content)} // not seekable
+	require.Nil(t, endp.User("u", "p").UpsertFile(context.Background(), 1, "name", fa, r))
+	assert.Equal(t, int64(5), fa.NodeID)
+	assert.Equal(t, []string{"GetNode", "GetNodeByName", "CreateSimpleDocument"}, calls)
+
+	id, ok := endp.paths.get(pathKey(1, "name"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), id)
+}
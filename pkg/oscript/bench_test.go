@@ -0,0 +1,185 @@
+package oscript
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The benchmarks are named by the shape of the value, compare the runs with benchstat:
+//
+//	go test -run=NONE -bench=. -count=10 ./pkg/oscript > old.txt
+//	go test -run=NONE -bench=. -count=10 ./pkg/oscript > new.txt
+//	benchstat old.txt new.txt
+
+type benchFeature struct {
+	Name         string    `oscript:"Name"`
+	Type         string    `oscript:"Type"`
+	BooleanValue bool      `oscript:"BooleanValue"`
+	DateValue    time.Time `oscript:"DateValue"`
+	IntegerValue int64     `oscript:"IntegerValue"`
+	StringValue  string    `oscript:"StringValue"`
+}
+
+type benchNode struct {
+	Comment     string         `oscript:"Comment"`
+	ChildTypes  []string       `oscript:"ChildTypes"`
+	CreateDate  time.Time      `oscript:"CreateDate"`
+	CreatedBy   int32          `oscript:"CreatedBy"`
+	Features    []benchFeature `oscript:"Features"`
+	ID          int64          `oscript:"ID"`
+	IsContainer bool           `oscript:"IsContainer"`
+	ModifyDate  time.Time      `oscript:"ModifyDate"`
+	Name        string         `oscript:"Name"`
+	Parent      int64          `oscript:"ParentID"`
+	Type        string         `oscript:"Type"`
+	Size        float64        `oscript:"Size"`
+}
+
+func newBenchNode(id int64) benchNode {
+	tm := time.Date(2019, 4, 9, 12, 32, 8, 0, time.UTC)
+	return benchNode{
+		Comment:    "comment of the node",
+		ChildTypes: []string{"Alias", "Category", "Document", "Folder", "URL"},
+		CreateDate: tm,
+		CreatedBy:  1000,
+		Features: []benchFeature{
+			{Name: "Name", Type: "Boolean", BooleanValue: true},
+			{Name: "Date", Type: "Date", DateValue: tm},
+			{Name: "Count", Type: "Integer", IntegerValue: 10},
+		},
+		ID:          id,
+		IsContainer: true,
+		ModifyDate:  tm,
+		Name:        "node " + strconv.FormatInt(id, 10),
+		Parent:      2000,
+		Type:        "Folder",
+		Size:        1024.5,
+	}
+}
+
+// benchValues are the values used by the encoding and decoding benchmarks.
+func benchValues() []struct {
+	name string
+	v    interface{}
+	ptr  func() interface{}
+} {
+	nodes := make([]benchNode, 1000)
+	for i := range nodes {
+		nodes[i] = newBenchNode(int64(i))
+	}
+
+	ints := make([]int64, 10000)
+	for i := range ints {
+		ints[i] = int64(i * 7)
+	}
+
+	plain := strings.Repeat("plain text of the metadata export ", 4000)
+	escaped := strings.Repeat("line 'quoted' \\ back\tslash\n", 1000)
+	payload := bytes.Repeat([]byte{0, 1, 2, 3, 0xfe, 0xff}, 10000)
+
+	return []struct {
+		name string
+		v    interface{}
+		ptr  func() interface{}
+	}{
+		{name: "Node", v: newBenchNode(1), ptr: func() interface{} { return new(benchNode) }},
+		{name: "Nodes1000", v: nodes, ptr: func() interface{} { return new([]benchNode) }},
+		{name: "Ints10000", v: ints, ptr: func() interface{} { return new([]int64) }},
+		{name: "String", v: plain, ptr: func() interface{} { return new(string) }},
+		{name: "StringEscaped", v: escaped, ptr: func() interface{} { return new(string) }},
+		{name: "Base64", v: payload, ptr: func() interface{} { return new([]byte) }},
+		{name: "Interface", v: nodes[:100], ptr: func() interface{} { return new(interface{}) }},
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	for _, bv := range benchValues() {
+		bv := bv
+		data, err := Marshal(bv.v)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(bv.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := Marshal(bv.v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	for _, bv := range benchValues() {
+		bv := bv
+		data, err := Marshal(bv.v)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(bv.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := Unmarshal(data, bv.ptr()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecoderDecode(b *testing.B) {
+	for _, bv := range benchValues() {
+		bv := bv
+		data, err := Marshal(bv.v)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(bv.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := NewDecoder(bytes.NewReader(data)).Decode(bv.ptr()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValid(b *testing.B) {
+	data, err := Marshal(benchValues()[1].v)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if !Valid(data) {
+			b.Fatal("invalid")
+		}
+	}
+}
+
+// TestMarshalAllocs is a regression gate of the allocations in the hot path of the encoder.
+func TestMarshalAllocs(t *testing.T) {
+	v := newBenchNode(1)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := Marshal(v); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if max := 10.0; allocs > max {
+		t.Errorf("Marshal allocs = %v; want <= %v", allocs, max)
+	}
+}
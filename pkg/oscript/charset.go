@@ -0,0 +1,59 @@
+package oscript
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// transcoder converts the bytes between the charsets, it is implemented
+// by the decoder and the encoder of encoding.Encoding.
+type transcoder interface {
+	Bytes(b []byte) ([]byte, error)
+}
+
+// SetCharset causes the Decoder to transcode the values from the charset of the server to UTF-8, e.g.
+// charmap.ISO8859_1 or charmap.Windows1251 of golang.org/x/text/encoding/charmap. The charset must be
+// a superset of ASCII, the whole value is transcoded before it is decoded, so the values
+// passed to the Unmarshalers are UTF-8 as well.
+func (dec *Decoder) SetCharset(cs encoding.Encoding) {
+	dec.charset = cs.NewDecoder()
+}
+
+// SetCharset causes the Encoder to transcode the strings from UTF-8 to the charset of the server,
+// the runes unrepresentable in the charset are escaped as \uXXXX. The charset must be a superset of ASCII.
+// The output of Marshaler is written as it is, MarshalerBuf writing the strings by the Buffer is transcoded.
+func (enc *Encoder) SetCharset(cs encoding.Encoding) {
+	enc.charset = cs.NewEncoder()
+}
+
+// writeCharset writes the non-ASCII rune r encoded as b in UTF-8 in the charset of the encoder.
+func (e *encodeState) writeCharset(r rune, b []byte) {
+	if t, err := e.charset.Bytes(b); err == nil {
+		e.Write(t)
+		return
+	}
+
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		e.writeEscapedRune(r1)
+		r = r2
+	}
+	e.writeEscapedRune(r)
+}
+
+func (e *encodeState) writeEscapedRune(r rune) {
+	e.WriteString(`\u`)
+	for shift := 12; shift >= 0; shift -= 4 {
+		e.WriteByte(hex[r>>uint(shift)&0xF])
+	}
+}
+
+func isASCII(s []byte) bool {
+	for _, c := range s {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
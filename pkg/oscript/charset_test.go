@@ -0,0 +1,87 @@
+package oscript
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDecoderCharset(t *testing.T) {
+	for _, tt := range []struct {
+		cs   encoding.Encoding
+		text string
+	}{
+		{cs: charmap.ISO8859_1, text: "Café Müller"},
+		{cs: charmap.Windows1251, text: "Отчёт"},
+	} {
+		raw, err := tt.cs.NewEncoder().String(tt.text)
+		require.Nil(t, err)
+
+		in := []byte("A<1,?,'Name'='" + raw + "','Names'={'" + raw + "\\n'},'" + raw + "'=1>")
+		var v struct {
+			Name  string
+			Names []string
+		}
+		dec := NewDecoder(bytes.NewReader(in))
+		dec.SetCharset(tt.cs)
+		require.Nil(t, dec.Decode(&v))
+		assert.Equal(t, tt.text, v.Name)
+		assert.Equal(t, []string{tt.text + "\n"}, v.Names)
+
+		var m map[string]interface{}
+		dec = NewDecoder(bytes.NewReader(in))
+		dec.SetCharset(tt.cs)
+		require.Nil(t, dec.Decode(&m))
+		assert.Equal(t, int64(1), m[tt.text])
+
+		require.Nil(t, NewDecoder(bytes.NewReader(in)).Decode(&v))
+		assert.NotEqual(t, tt.text, v.Name, "the bytes are not UTF-8 without the charset")
+	}
+}
+
+type multiText struct {
+	Text string
+}
+
+func (m *multiText) UnmarshalOscript(data []byte) error {
+	var v struct{ Value string }
+	if err := Unmarshal(data, &v); err != nil {
+		return err
+	}
+	m.Text = v.Value
+	return nil
+}
+
+func TestDecoderCharsetUnmarshaler(t *testing.T) {
+	raw, err := charmap.ISO8859_1.NewEncoder().String("Café")
+	require.Nil(t, err)
+
+	var v struct{ Name multiText }
+	dec := NewDecoder(bytes.NewReader([]byte("A<1,?,'Name'=A<1,?,'Value'='" + raw + "'>>")))
+	dec.SetCharset(charmap.ISO8859_1)
+	require.Nil(t, dec.Decode(&v))
+	assert.Equal(t, "Café", v.Name.Text)
+}
+
+func TestEncoderCharset(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetCharset(charmap.ISO8859_1)
+	require.Nil(t, enc.Encode(M{"Name": "Café 'Ж' 😀", "Raw": []interface{}{"é\n"}}))
+
+	assert.Equal(t, "A<1,?,'Name'='Caf\xe9 \\'\\u0416\\' \\ud83d\\ude00','Raw'={'\xe9\\n'}>", buf.String())
+
+	var v M
+	dec := NewDecoder(&buf)
+	dec.SetCharset(charmap.ISO8859_1)
+	require.Nil(t, dec.Decode(&v))
+	assert.Equal(t, "Café 'Ж' 😀", v["Name"])
+
+	b, err := Marshal("é")
+	require.Nil(t, err)
+	assert.Equal(t, "'é'", string(b), "encoder option must not leak through the pool")
+}
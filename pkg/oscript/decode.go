@@ -8,9 +8,11 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 	"unicode/utf16"
@@ -33,7 +35,9 @@ import (
 //
 // To unmarshal oscript into a value implementing the Unmarshaler interface,
 // Unmarshal calls that value's UnmarshalOscript method, including
-// when the input is a oscript undefined.
+// when the input is a oscript undefined. Otherwise, to unmarshal a oscript string
+// into a value implementing encoding.TextUnmarshaler, Unmarshal calls that value's
+// UnmarshalText method with the unquoted string. A oscript string is parsed into url.URL.
 //
 // To unmarshal oscript into a struct, Unmarshal matches incoming object
 // keys to the keys used by Marshal (either the struct field name or its tag),
@@ -41,6 +45,9 @@ import (
 // default, object keys which don't have a corresponding struct field are
 // ignored (see Decoder.DisallowUnknownFields for an alternative).
 //
+// The doubled quote '' inside of a string is decoded as the escaped quote \',
+// some servers send it (see Decoder.StrictQuotes for an alternative).
+//
 // To unmarshal oscript into an interface value,
 // Unmarshal stores one of these in the interface value:
 //
@@ -126,16 +133,38 @@ type UnmarshalTypeError struct {
 	Type   reflect.Type // type of Go value it could not be assigned to
 	Offset int64        // error occurred after reading Offset bytes
 	Struct string       // name of the struct type containing the field
-	Field  string       // name of the field holding the Go value
+	Field  string       // full path from the root to the field holding the Go value, e.g. Metadata.Categories[3].Values[1]
 }
 
 func (e *UnmarshalTypeError) Error() string {
-	if e.Struct != "" || e.Field != "" {
+	if e.Struct != "" {
 		return "oscript: cannot unmarshal " + e.Value + " into Go struct field " + e.Struct + "." + e.Field + " of type " + e.Type.String()
 	}
+	if e.Field != "" {
+		return "oscript: cannot unmarshal " + e.Value + " into Go value at " + e.Field + " of type " + e.Type.String()
+	}
 	return "oscript: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
 }
 
+// DecodeErrors is a list of the errors collected by the decoder when Decoder.CollectErrors is set.
+type DecodeErrors []error
+
+func (e DecodeErrors) Error() string {
+	var b strings.Builder
+	for i, err := range e {
+		if i != 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the collected errors, errors.Is and errors.As look through them.
+func (e DecodeErrors) Unwrap() []error {
+	return e
+}
+
 // An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
 // (The argument to Unmarshal must be a non-nil pointer.)
 type InvalidUnmarshalError struct {
@@ -167,6 +196,10 @@ func (d *decodeState) unmarshal(v interface{}) error {
 	if err != nil {
 		return err
 	}
+
+	if len(d.errs) != 0 {
+		return d.errs
+	}
 	return d.savedError
 }
 
@@ -178,12 +211,25 @@ type decodeState struct {
 	scan         scanner
 	errorContext struct { // provides context for type errors
 		Struct string
-		Field  string
+		Path   []pathElem // the path is formatted only on error
 	}
 	savedError            error
+	errs                  DecodeErrors // all saved errors when collectErrors is set
 	disallowUnknownFields bool
+	collectErrors         bool
+	unknownField          func(UnknownField)
+}
+
+// UnknownField is a key of the object which does not match any field of the struct.
+type UnknownField struct {
+	Type   reflect.Type // the struct
+	Field  string       // the key
+	Sample string       // the encoded value, cut to maxSample bytes
 }
 
+// maxSample is a maximum length of UnknownField.Sample.
+const maxSample = 64
+
 // readIndex returns the position of the last byte read.
 func (d *decodeState) readIndex() int {
 	return d.off - 1
@@ -198,14 +244,45 @@ func (d *decodeState) init(data []byte) *decodeState {
 	d.data = data
 	d.off = 0
 	d.savedError = nil
+	d.errs = nil
 	d.errorContext.Struct = ""
-	d.errorContext.Field = ""
+	d.errorContext.Path = d.errorContext.Path[:0]
 	return d
 }
 
+// pathElem is an element of the path to the decoded value, the name of the field or the key, otherwise the index.
+type pathElem struct {
+	name  []byte
+	index int
+}
+
+// formatPath formats path as dotted names with the indices in brackets.
+func formatPath(path []pathElem) string {
+	var b strings.Builder
+	for _, e := range path {
+		if e.name == nil {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(e.index))
+			b.WriteByte(']')
+			continue
+		}
+
+		if b.Len() != 0 {
+			b.WriteByte('.')
+		}
+		b.Write(e.name)
+	}
+	return b.String()
+}
+
 // saveError saves the first err it is called with,
-// for reporting at the end of the unmarshal.
+// for reporting at the end of the unmarshal. All errors are saved when collectErrors is set.
 func (d *decodeState) saveError(err error) {
+	if d.collectErrors {
+		d.errs = append(d.errs, d.addErrorContext(err))
+		return
+	}
+
 	if d.savedError == nil {
 		d.savedError = d.addErrorContext(err)
 	}
@@ -213,11 +290,11 @@ func (d *decodeState) saveError(err error) {
 
 // addErrorContext returns a new error enhanced with information from d.errorContext
 func (d *decodeState) addErrorContext(err error) error {
-	if d.errorContext.Struct != "" || d.errorContext.Field != "" {
+	if d.errorContext.Struct != "" || len(d.errorContext.Path) != 0 {
 		switch err := err.(type) {
 		case *UnmarshalTypeError:
 			err.Struct = d.errorContext.Struct
-			err.Field = d.errorContext.Field
+			err.Field = formatPath(d.errorContext.Path)
 			return err
 		}
 	}
@@ -229,6 +306,10 @@ func (d *decodeState) skip() {
 	s, data, i := &d.scan, d.data, d.off
 	depth := len(s.parseState)
 	for {
+		if s.inString {
+			i += stringSpan(data[i:])
+		}
+
 		op := s.step(s, data[i])
 		i++
 		if len(s.parseState) < depth {
@@ -256,6 +337,13 @@ func (d *decodeState) scanNext() {
 func (d *decodeState) scanWhile(op int) {
 	s, data, i := &d.scan, d.data, d.off
 	for i < len(d.data) {
+		// string bytes are scanContinue
+		if s.inString && op == scanContinue {
+			if i += stringSpan(data[i:]); i == len(data) {
+				break
+			}
+		}
+
 		newOp := s.step(s, data[i])
 		i++
 		if newOp != op {
@@ -288,9 +376,10 @@ func (d *decodeState) value(v reflect.Value) error {
 		d.scanNext()
 
 	case scanBeginObject:
+		start := d.readIndex()
 		d.scanWhile(scanContinue)
 		if v.IsValid() {
-			if err := d.object(v); err != nil {
+			if err := d.object(v, start); err != nil {
 				return err
 			}
 		} else {
@@ -411,6 +500,9 @@ func (d *decodeState) array(v reflect.Value) error {
 		break
 	}
 
+	originalErrorContext := d.errorContext
+	defer func() { d.errorContext = originalErrorContext }()
+
 	i := 0
 	for {
 		// Look ahead for } - can only happen on first iteration.
@@ -418,6 +510,7 @@ func (d *decodeState) array(v reflect.Value) error {
 		if d.opcode == scanEndArray {
 			break
 		}
+		d.errorContext.Path = append(originalErrorContext.Path, pathElem{index: i})
 
 		// Get element of array, growing if necessary.
 		if v.Kind() == reflect.Slice {
@@ -487,12 +580,11 @@ var (
 )
 
 // object consumes an object from d.data[d.off-1:], decoding into the value v.
-// the first byte 'A' of the object has been read already.
-func (d *decodeState) object(v reflect.Value) error {
+// the beginning `A<1,?` of the object has been read already, start is the position of the byte 'A'.
+func (d *decodeState) object(v reflect.Value, start int) error {
 	// Check for unmarshaler.
 	u, pv := d.indirect(v, false)
 	if u != nil {
-		start := d.readIndex()
 		d.skip()
 		return u.UnmarshalOscript(d.data[start:d.off])
 	}
@@ -569,7 +661,10 @@ func (d *decodeState) object(v reflect.Value) error {
 		}
 
 		// Figure want field corresponding to key.
-		var subv reflect.Value
+		var (
+			subv    reflect.Value
+			unknown bool // the key is reported to unknownField
+		)
 
 		if v.Kind() == reflect.Map {
 			elemType := v.Type().Elem()
@@ -579,6 +674,7 @@ func (d *decodeState) object(v reflect.Value) error {
 				mapElem.Set(reflect.Zero(elemType))
 			}
 			subv = mapElem
+			d.errorContext.Path = append(originalErrorContext.Path, pathElem{name: key})
 		} else {
 			var f *field
 			fields := cachedTypeFields(v.Type())
@@ -618,10 +714,13 @@ func (d *decodeState) object(v reflect.Value) error {
 						subv = subv.Field(i)
 					}
 				}
-				d.errorContext.Field = f.name
 				d.errorContext.Struct = v.Type().Name()
-			} else if d.disallowUnknownFields {
-				d.saveError(fmt.Errorf("oscript: unknown field %q", key))
+				d.errorContext.Path = append(originalErrorContext.Path, pathElem{name: f.nameBytes})
+			} else {
+				if d.disallowUnknownFields {
+					d.saveError(fmt.Errorf("oscript: unknown field %q", key))
+				}
+				unknown = d.unknownField != nil
 			}
 		}
 
@@ -634,9 +733,19 @@ func (d *decodeState) object(v reflect.Value) error {
 		}
 		d.scanWhile(scanSkipSpace)
 
+		valueStart := d.readIndex()
 		if err := d.value(subv); err != nil {
 			return err
 		}
+		d.errorContext = originalErrorContext
+
+		if unknown {
+			sample := bytes.TrimRight(d.data[valueStart:d.readIndex()], " \t\r\n")
+			if len(sample) > maxSample {
+				sample = sample[:maxSample]
+			}
+			d.unknownField(UnknownField{Type: v.Type(), Field: string(key), Sample: string(sample)})
+		}
 
 		// Write value back to map;
 		// if using struct without, subv points into struct already.
@@ -713,46 +822,34 @@ func (d *decodeState) convertFloat(s string) (interface{}, error) {
 	return f, nil
 }
 
-var regTime = regexp.MustCompile(`D/(\d+)/(\d{1,2})/(\d{1,2}):(\d{1,2}):(\d{1,2}):(\d{1,2})`)
+var regTime = regexp.MustCompile(`^D/(-?\d+)/(\d{1,2})/(\d{1,2}):(\d{1,2}):(\d{1,2}):(\d{1,2})$`)
 
-// convertTime converts the date s to a time.Time.
+// convertTime converts the date s to a time.Time. The date D/0/0/0:0:0:0 converts to the zero time.Time.
 func convertTime(s string) (time.Time, error) {
 	d := regTime.FindStringSubmatch(s)
-	if d == nil || len(d) != 7 {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
-	}
-
-	year, err := strconv.Atoi(d[1])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
-	}
-
-	month, err := strconv.Atoi(d[2])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
+	if d == nil {
+		return time.Time{}, fmt.Errorf("oscript: invalid date %s", s)
 	}
 
-	day, err := strconv.Atoi(d[3])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
-	}
-
-	hour, err := strconv.Atoi(d[4])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
+	var v [6]int
+	for i := range v {
+		n, err := strconv.Atoi(d[i+1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("oscript: invalid date %s", s)
+		}
+		v[i] = n
 	}
 
-	minute, err := strconv.Atoi(d[5])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
+	if v == [6]int{} {
+		return time.Time{}, nil
 	}
 
-	sec, err := strconv.Atoi(d[6])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("can not parse %s", s)
+	year, month, day, hour, minute, sec := v[0], time.Month(v[1]), v[2], v[3], v[4], v[5]
+	t := time.Date(year, month, day, hour, minute, sec, 0, time.UTC)
+	if ty, tm, td := t.Date(); ty != year || tm != month || td != day || hour > 23 || minute > 59 || sec > 59 {
+		return time.Time{}, fmt.Errorf("oscript: invalid date %s", s)
 	}
-
-	return time.Date(year, time.Month(month), day, hour, minute, sec, 0, time.UTC), nil
+	return t, nil
 }
 
 // literalStore decodes a literal stored in item into v.
@@ -831,6 +928,25 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value) error {
 			return errPhase
 		}
 
+		if v.Type() == urlType {
+			u, err := url.Parse(string(s))
+			if err != nil {
+				d.saveError(err)
+				break
+			}
+			v.Set(reflect.ValueOf(*u))
+			break
+		}
+
+		if v.CanAddr() && v.Type() != timeType {
+			if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+				if err := u.UnmarshalText(s); err != nil {
+					d.saveError(err)
+				}
+				break
+			}
+		}
+
 		switch v.Kind() {
 		default:
 			d.saveError(&UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: int64(d.readIndex())})
@@ -1265,9 +1381,14 @@ func unquoteBytes(s []byte) (t []byte, ok bool) {
 				w += utf8.EncodeRune(b[w:], rr)
 			}
 
-			// quote, control characters are invalid
+			// doubled quote, the single quote is invalid
 		case c == '\'':
-			return
+			if r+1 == len(s) || s[r+1] != '\'' {
+				return
+			}
+			b[w] = c
+			r += 2
+			w++
 
 			// invalid utf8, allow
 		case c < ' ':
@@ -38,6 +38,16 @@ type VOuter struct {
 	V V
 }
 
+type VInner struct {
+	F2 int32
+}
+
+type pathOuter struct {
+	V struct {
+		F3 []VInner
+	}
+}
+
 // numAsInt64 are used to test unmarshaling.
 var numAsInt64 = map[string]interface{}{
 	"k1": int64(1),
@@ -365,6 +375,12 @@ var unmarshalTests = []unmarshalTest{
 	{in: `A<1,?,'X'= 'foo', 'Y'}`, err: &SyntaxError{"invalid character '}' after object key", 22}},
 	{in: `{1, 2, 3+}`, err: &SyntaxError{"invalid character '+' after array element", 9}},
 	{in: `{2, 3`, err: &SyntaxError{msg: "unexpected end of Oscript input", Offset: 5}},
+	{in: `D2018/3/8:22:36:2`, err: &SyntaxError{"invalid character '2' in date literal", 2}},
+	{in: `D/2018/3/8/22:36:2`, err: &SyntaxError{"invalid character '/' in date literal", 11}},
+	{in: `D/2018/3/8:22:36`, err: &SyntaxError{"invalid character ' ' in date literal", 16}},
+	{in: `D/2018/123/8:22:36:2`, err: &SyntaxError{"invalid character '3' in date literal", 10}},
+	{in: `D/2018//8:22:36:2`, err: &SyntaxError{"invalid character '/' in date literal", 8}},
+	{in: `D/2018/3/8:22:36:2x`, err: &SyntaxError{"invalid character 'x' after top-level value", 19}},
 
 	// raw value errors
 	{in: "\x01 42", err: &SyntaxError{"invalid character '\\x01' looking for beginning of value", 1}},
@@ -653,6 +669,26 @@ var unmarshalTests = []unmarshalTest{
 		ptr: new(interface{}),
 		out: map[string]interface{}{"date": time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)},
 	},
+	{
+		in:  `D/0/0/0:0:0:0`,
+		ptr: new(time.Time),
+		out: time.Time{},
+	},
+	{
+		in:  `D/-44/3/15:12:0:0`,
+		ptr: new(time.Time),
+		out: time.Date(-44, 3, 15, 12, 0, 0, 0, time.UTC),
+	},
+	{
+		in:  `D/2019/2/29:0:0:0`,
+		ptr: new(time.Time),
+		err: fmt.Errorf("oscript: invalid date D/2019/2/29:0:0:0"),
+	},
+	{
+		in:  `D/2019/1/1:24:0:0`,
+		ptr: new(interface{}),
+		err: fmt.Errorf("oscript: invalid date D/2019/1/1:24:0:0"),
+	},
 	// oscript.Error
 	{
 		in:  `E1024`,
@@ -715,7 +751,7 @@ var unmarshalTests = []unmarshalTest{
 		err: &UnmarshalTypeError{
 			Value:  "string",
 			Struct: "V",
-			Field:  "F2",
+			Field:  "V.F2",
 			Type:   reflect.TypeOf(int32(0)),
 			Offset: 30,
 		},
@@ -726,7 +762,7 @@ var unmarshalTests = []unmarshalTest{
 		err: &UnmarshalTypeError{
 			Value:  "string",
 			Struct: "V",
-			Field:  "F2",
+			Field:  "V.F2",
 			Type:   reflect.TypeOf(int32(0)),
 			Offset: 44,
 		},
@@ -813,16 +849,28 @@ var unmarshalTests = []unmarshalTest{
 		err: fmt.Errorf("oscript: no compares data object with type Objecter"),
 	}, */
 
+	// UnmarshalTypeError with full path
+	{
+		in:  `A<1,?,'V'=A<1,?,'F3'={A<1,?,'F2'=1>,A<1,?,'F2'='bad'>}>>`,
+		ptr: new(pathOuter),
+		err: &UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(int32(0)), Offset: 52, Struct: "VInner", Field: "V.F3[1].F2"},
+	},
+	{
+		in:  `{1,'x'}`,
+		ptr: new([]int),
+		err: &UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0), Offset: 6, Field: "[1]"},
+	},
+
 	// UnmarshalTypeError without field & struct values
 	{
 		in:  `A<1,?,'data'=A<1,?,'test1'= 'bob', 'test2'= 123>>`,
 		ptr: new(mapStringToStringData),
-		err: &UnmarshalTypeError{Value: "int", Type: reflect.TypeOf(""), Offset: 47, Struct: "mapStringToStringData", Field: "data"},
+		err: &UnmarshalTypeError{Value: "int", Type: reflect.TypeOf(""), Offset: 47, Struct: "mapStringToStringData", Field: "data.test2"},
 	},
 	{
 		in:  `A<1,?,'data'=A<1,?,'test1'= 123, 'test2'= 'bob'>>`,
 		ptr: new(mapStringToStringData),
-		err: &UnmarshalTypeError{Value: "int", Type: reflect.TypeOf(""), Offset: 31, Struct: "mapStringToStringData", Field: "data"},
+		err: &UnmarshalTypeError{Value: "int", Type: reflect.TypeOf(""), Offset: 31, Struct: "mapStringToStringData", Field: "data.test1"},
 	},
 }
 
@@ -1945,3 +1993,72 @@ func TestUnmarshalPanic(t *testing.T) {
 	Unmarshal([]byte("{}"), &unmarshalPanic{})
 	t.Fatalf("Unmarshal should have panicked")
 }
+
+type rawUnmarshaler []byte
+
+func (r *rawUnmarshaler) UnmarshalOscript(b []byte) error {
+	*r = append((*r)[:0], b...)
+	return nil
+}
+
+func TestUnmarshalerRawObject(t *testing.T) {
+	var got struct {
+		O rawUnmarshaler `oscript:"o"`
+		A rawUnmarshaler `oscript:"a"`
+	}
+
+	if err := Unmarshal([]byte(`A<1,?,'o'=A<1,?,'x'=1>,'a'={1,2}>`), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if string(got.O) != `A<1,?,'x'=1>` {
+		t.Errorf("object got %q, want %q", got.O, `A<1,?,'x'=1>`)
+	}
+	if string(got.A) != `{1,2}` {
+		t.Errorf("array got %q, want %q", got.A, `{1,2}`)
+	}
+}
+
+func TestUnmarshalDoubledQuotes(t *testing.T) {
+	type node struct {
+		Name    string
+		Comment string
+		Tags    []string
+	}
+
+	for _, tt := range []struct {
+		in   string
+		want node
+	}{
+		{
+			in:   `A<1,?,'Name'='O''Brien''s report','Comment'='',` + `'Tags'={'it''s','''quoted''',''''}>`,
+			want: node{Name: "O'Brien's report", Tags: []string{"it's", "'quoted'", "'"}},
+		},
+		{
+			in:   `A<1,?,'Name'='mixed \'escape\' and ''doubled''','Comment'='ends with quote '''>`,
+			want: node{Name: "mixed 'escape' and 'doubled'", Comment: "ends with quote '"},
+		},
+		{
+			in:   `A<1,?,'Na''me'='key with quote'>`,
+			want: node{},
+		},
+	} {
+		var got node
+		require.Nil(t, Unmarshal([]byte(tt.in), &got), tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+
+		var v node
+		require.Nil(t, NewDecoder(strings.NewReader(tt.in)).Decode(&v), tt.in)
+		assert.Equal(t, tt.want, v, tt.in)
+	}
+
+	var s string
+	require.Nil(t, Unmarshal([]byte(`'a''b'`), &s))
+	assert.Equal(t, "a'b", s)
+
+	dec := NewDecoder(strings.NewReader(`A<1,?,'Name'='O''Brien'>`))
+	dec.StrictQuotes()
+	var got node
+	var serr *SyntaxError
+	assert.True(t, errors.As(dec.Decode(&got), &serr))
+}
@@ -5,8 +5,11 @@ import (
 	"encoding"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"math"
+	"net/url"
 	"reflect"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
@@ -71,6 +74,18 @@ type M map[string]interface{}
 // false, 0, a nil pointer, a nil interface value, and any empty array,
 // slice, map, or string.
 //
+// The "omitundef" option specifies that the field should be omitted
+// from the encoding if the field encodes as undefined, defined as
+// a nil pointer, interface value, map, or slice, or a value implementing
+// Undefiner which reports it is undefined. Unlike "omitempty"
+// zero values and empty collections are kept.
+//
+// The "prec=N" option of a float field forces fixed-point format with
+// N digits after the point.
+//
+// The "long" option of an integer field forces the long form L123, some methods
+// of the services require it. The type Long does the same for the values outside of the structs.
+//
 // As a special case, if the field tag is "-", the field is always omitted.
 // Note that a field with name "-" can still be generated using the tag "-,".
 //
@@ -95,6 +110,9 @@ type M map[string]interface{}
 //
 // 3) Otherwise there are multiple fields, and all are ignored; no error occurs.
 //
+// Values implementing encoding.TextMarshaler encode as oscript strings, except time.Time.
+// url.URL encodes as oscript string of the URL.
+//
 // Map values encode as oscript objects: A<1,?,'key'=value>. The map's key type must either be a
 // string, an integer type, or implement encoding.TextMarshaler.
 //   - string keys are used directly
@@ -116,7 +134,39 @@ type M map[string]interface{}
 // an infinite recursion.
 //
 func Marshal(v interface{}) ([]byte, error) {
+	return MarshalSize(v, 0)
+}
+
+// MarshalCanonical is like Marshal but the equal values are always encoded into the same bytes
+// at any depth, including the maps inside []interface{}, so the result may be compared, hashed or signed:
+//   - the keys of the maps are sorted, the keys with the same string form are an error
+//   - time.Time is converted to UTC, the instants are encoded equally regardless of the location
+//   - negative zero is encoded as zero
+//
+// The output of Marshaler is written as it is returned, such types must be deterministic themselves.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	e := newEncodeState()
+	e.canonical = true
+
+	err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := append([]byte(nil), e.Bytes()...)
+
+	e.Reset()
+	encodeStatePool.Put(e)
+
+	return buf, nil
+}
+
+// MarshalSize is like Marshal but preallocates size bytes for the encoding,
+// it avoids repeated reallocation when the expected size of the large value is known.
+func MarshalSize(v interface{}, size int) ([]byte, error) {
 	e := newEncodeState()
+	if size > 0 {
+		e.Grow(size)
+	}
 
 	err := e.marshal(v)
 	if err != nil {
@@ -141,12 +191,19 @@ type MarshalerBuf interface {
 	MarshalOscriptBuf(buf Buffer) error
 }
 
+// Undefiner is the interface implemented by types that may encode as undefined,
+// the fields of such types with option "omitundef" are omitted when IsUndefined returns true.
+type Undefiner interface {
+	IsUndefined() bool
+}
+
 // Buffer is the interface implemented by internal encodeState.
 type Buffer interface {
 	WriteString(s string) (int, error)
 	WriteStringValue(s string)
 	WriteByte(b byte) error
 	WriteEncode(v interface{})
+	Grow(n int)
 }
 
 // An UnsupportedTypeError is returned by Marshal when attempting
@@ -179,12 +236,45 @@ func (e *MarshalerError) Error() string {
 	return "oscript: error calling MarshalOscript for type " + e.Type.String() + ": " + e.Err.Error()
 }
 
+func (e *MarshalerError) Unwrap() error { return e.Err }
+
+// PanicError is the error of MarshalerError when MarshalOscript has panicked, see Encoder.RecoverPanics.
+type PanicError struct {
+	Value interface{} // value passed to panic
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// marshalOscript calls the marshaler, the panic is returned as PanicError when the panics are recovered.
+func (e *encodeState) marshalOscript(m Marshaler) (b []byte, err error) {
+	if !e.recoverPanics {
+		return m.MarshalOscript()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return m.MarshalOscript()
+}
+
 var hex = "0123456789abcdef"
 
 // An encodeState encodes Oscript into a bytes.Buffer.
 type encodeState struct {
 	bytes.Buffer // accumulated output
 	scratch      [64]byte
+
+	floatFixed bool // formats floats in fixed-point with floatPrec digits
+	floatPrec  int
+	canonical  bool // see MarshalCanonical
+	charset    transcoder
+
+	recoverPanics bool // see Encoder.RecoverPanics
 }
 
 var encodeStatePool sync.Pool
@@ -193,6 +283,10 @@ func newEncodeState() *encodeState {
 	if v := encodeStatePool.Get(); v != nil {
 		e := v.(*encodeState)
 		e.Reset()
+		e.floatFixed = false
+		e.canonical = false
+		e.charset = nil
+		e.recoverPanics = false
 		return e
 	}
 	return new(encodeState)
@@ -239,6 +333,21 @@ func isEmptyValue(v reflect.Value) bool {
 	return false
 }
 
+// isUndefValue reports whether v encodes as undefined.
+func isUndefValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return true
+		}
+	}
+
+	if v.Type().Implements(undefinerType) {
+		return v.Interface().(Undefiner).IsUndefined()
+	}
+	return false
+}
+
 func (e *encodeState) reflectValue(v reflect.Value) {
 	valueEncoder(v)(e, v)
 }
@@ -287,8 +396,10 @@ func typeEncoder(t reflect.Type) encoderFunc {
 var (
 	marshalerType     = reflect.TypeOf(new(Marshaler)).Elem()
 	marshalerBufType  = reflect.TypeOf(new(MarshalerBuf)).Elem()
+	undefinerType     = reflect.TypeOf(new(Undefiner)).Elem()
 	textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
 	timeType          = reflect.TypeOf(time.Time{})
+	urlType           = reflect.TypeOf(url.URL{})
 	sdoType           = reflect.TypeOf(SDOName{})
 
 	undefined = byte('?')
@@ -315,6 +426,22 @@ func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
 		}
 	}
 
+	if t == urlType {
+		return urlEncoder
+	}
+
+	// time has own encoding
+	if t != timeType && (t.Kind() != reflect.Ptr || t.Elem() != timeType) {
+		if t.Implements(textMarshalerType) {
+			return textMarshalerEncoder
+		}
+		if t.Kind() != reflect.Ptr && allowAddr {
+			if reflect.PtrTo(t).Implements(textMarshalerType) {
+				return newCondAddrEncoder(addrTextMarshalerEncoder, newTypeEncoder(t, false))
+			}
+		}
+	}
+
 	switch t.Kind() {
 	case reflect.Bool:
 		return boolEncoder
@@ -399,7 +526,7 @@ func marshalerEncoder(e *encodeState, v reflect.Value) {
 		return
 	}
 
-	b, err := m.MarshalOscript()
+	b, err := e.marshalOscript(m)
 	if err == nil {
 		err = compact(&e.Buffer, b)
 	}
@@ -417,7 +544,7 @@ func addrMarshalerEncoder(e *encodeState, v reflect.Value) {
 	}
 
 	m := va.Interface().(Marshaler)
-	b, err := m.MarshalOscript()
+	b, err := e.marshalOscript(m)
 
 	if err == nil {
 		// copy oscript into buffer, checking validity
@@ -429,6 +556,45 @@ func addrMarshalerEncoder(e *encodeState, v reflect.Value) {
 	}
 }
 
+func textMarshalerEncoder(e *encodeState, v reflect.Value) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		e.WriteByte(undefined)
+		return
+	}
+
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok {
+		e.WriteByte(undefined)
+		return
+	}
+
+	b, err := m.MarshalText()
+	if err != nil {
+		e.error(&MarshalerError{v.Type(), err})
+	}
+	e.stringBytes(b)
+}
+
+func addrTextMarshalerEncoder(e *encodeState, v reflect.Value) {
+	va := v.Addr()
+	if va.IsNil() {
+		e.WriteByte(undefined)
+		return
+	}
+
+	m := va.Interface().(encoding.TextMarshaler)
+	b, err := m.MarshalText()
+	if err != nil {
+		e.error(&MarshalerError{v.Type(), err})
+	}
+	e.stringBytes(b)
+}
+
+func urlEncoder(e *encodeState, v reflect.Value) {
+	u := v.Interface().(url.URL)
+	e.string(u.String())
+}
+
 func boolEncoder(e *encodeState, v reflect.Value) {
 	if v.Bool() {
 		e.WriteString("true")
@@ -453,6 +619,17 @@ func (bits floatEncoder) encode(e *encodeState, v reflect.Value) {
 	if math.IsInf(f, 0) || math.IsNaN(f) {
 		e.error(&UnsupportedValueError{v, strconv.FormatFloat(f, 'g', -1, int(bits))})
 	}
+
+	if e.canonical && f == 0 {
+		f = 0 // negative zero
+	}
+
+	if e.floatFixed {
+		e.WriteByte('G')
+		e.Write(strconv.AppendFloat(e.scratch[:0], f, 'f', e.floatPrec, int(bits)))
+		return
+	}
+
 	b := e.scratch[:0]
 	abs := math.Abs(f)
 	fmt := byte('f')
@@ -475,6 +652,74 @@ func (bits floatEncoder) encode(e *encodeState, v reflect.Value) {
 	e.Write(b)
 }
 
+// fixedFloatEncoder encodes float in fixed-point format with precision from the tag option "prec".
+type fixedFloatEncoder struct {
+	bits int
+	prec int
+}
+
+func (fe fixedFloatEncoder) encode(e *encodeState, v reflect.Value) {
+	f := v.Float()
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		e.error(&UnsupportedValueError{v, strconv.FormatFloat(f, 'g', -1, fe.bits)})
+	}
+
+	if e.canonical && f == 0 {
+		f = 0 // negative zero
+	}
+	e.WriteByte('G')
+	e.Write(strconv.AppendFloat(e.scratch[:0], f, 'f', fe.prec, fe.bits))
+}
+
+// newFixedFloatEncoder returns encoder of the float or pointer to float with the precision, it returns nil
+// when the type is not float or implements marshaler.
+func newFixedFloatEncoder(t reflect.Type, prec int) encoderFunc {
+	if t.Implements(marshalerType) || t.Implements(marshalerBufType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return fixedFloatEncoder{bits: t.Bits(), prec: prec}.encode
+	case reflect.Ptr:
+		if elem := newFixedFloatEncoder(t.Elem(), prec); elem != nil {
+			enc := ptrEncoder{elem}
+			return enc.encode
+		}
+	}
+	return nil
+}
+
+// longEncoder encodes integer in the long form L123.
+func longEncoder(e *encodeState, v reflect.Value) {
+	e.WriteByte('L')
+	if v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr {
+		uintEncoder(e, v)
+		return
+	}
+	intEncoder(e, v)
+}
+
+// newLongEncoder returns encoder of the integer or pointer to integer in the long form, it returns nil
+// when the type is not integer or implements marshaler.
+func newLongEncoder(t reflect.Type) encoderFunc {
+	if t.Implements(marshalerType) || t.Implements(marshalerBufType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return longEncoder
+	case reflect.Ptr:
+		if elem := newLongEncoder(t.Elem()); elem != nil {
+			enc := ptrEncoder{elem}
+			return enc.encode
+		}
+	}
+	return nil
+}
+
 var (
 	float32Encoder = (floatEncoder(32)).encode
 	float64Encoder = (floatEncoder(64)).encode
@@ -519,7 +764,7 @@ FieldLoop:
 			fv = fv.Field(i)
 		}
 
-		if f.omitEmpty && isEmptyValue(fv) {
+		if f.omitEmpty && isEmptyValue(fv) || f.omitUndef && isUndefValue(fv) {
 			continue
 		}
 		e.WriteByte(',')
@@ -558,6 +803,14 @@ func (me *mapEncoder) encode(e *encodeState, v reflect.Value) {
 	}
 
 	sort.Slice(sv, func(i, j int) bool { return sv[i].s < sv[j].s })
+	if e.canonical {
+		for i := 1; i < len(sv); i++ {
+			if sv[i].s == sv[i-1].s { // the order of such keys depends on the iteration of the map
+				e.error(&UnsupportedValueError{v, "duplicate map key " + strconv.Quote(sv[i].s)})
+			}
+		}
+	}
+
 	for _, kv := range sv {
 		e.WriteByte(',')
 		e.string(kv.s)
@@ -692,6 +945,10 @@ func timeEncoder(e *encodeState, v reflect.Value) {
 		e.error(errors.New("can not convert to time.Time"))
 	}
 
+	if e.canonical {
+		t = t.UTC()
+	}
+
 	e.WriteByte('D')
 	e.WriteByte('/')
 	year, month, day := t.Date()
@@ -820,6 +1077,17 @@ func (e *encodeState) string(s string) {
 			start = i
 			continue
 		}
+
+		if e.charset != nil {
+			if start < i {
+				e.WriteString(s[start:i])
+			}
+
+			e.writeCharset(c, []byte(s[i:i+size]))
+			i += size
+			start = i
+			continue
+		}
 		i += size
 	}
 
@@ -874,6 +1142,17 @@ func (e *encodeState) stringBytes(s []byte) {
 			start = i
 			continue
 		}
+
+		if e.charset != nil {
+			if start < i {
+				e.Write(s[start:i])
+			}
+
+			e.writeCharset(c, s[i:i+size])
+			i += size
+			start = i
+			continue
+		}
 		i += size
 	}
 
@@ -893,6 +1172,7 @@ type field struct {
 	index     []int
 	typ       reflect.Type
 	omitEmpty bool
+	omitUndef bool
 	value     reflect.Value // value in tag
 	encoder   encoderFunc
 }
@@ -993,6 +1273,20 @@ func typeFields(t reflect.Type) []field {
 						index:     index,
 						typ:       ft,
 						omitEmpty: opts.Contains("omitempty"),
+						omitUndef: opts.Contains("omitundef"),
+					}
+
+					if v, ok := opts.Get("prec"); ok {
+						prec, err := strconv.Atoi(v)
+						if err == nil {
+							field.encoder = newFixedFloatEncoder(sf.Type, prec)
+						}
+					}
+
+					if opts.Contains("long") {
+						if enc := newLongEncoder(sf.Type); enc != nil {
+							field.encoder = enc
+						}
 					}
 
 					if sf.Type == sdoType {
@@ -2,8 +2,14 @@ package oscript
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 	"unicode"
@@ -48,6 +54,27 @@ func TestOmitEmpty(t *testing.T) {
 	assert.Equal(t, want, string(b))
 }
 
+type OptionalsUndef struct {
+	P  *string                `oscript:"p,omitundef"`
+	Pr *string                `oscript:"pr"`
+	I  interface{}            `oscript:"i,omitundef"`
+	M  map[string]interface{} `oscript:"m,omitundef"`
+	S  []int                  `oscript:"s,omitundef"`
+	Z  int                    `oscript:"z,omitundef"`
+	E  string                 `oscript:"e,omitundef"`
+}
+
+func TestOmitUndef(t *testing.T) {
+	b, err := Marshal(OptionalsUndef{})
+	require.Nil(t, err)
+	assert.Equal(t, `A<1,?,'pr'=?,'z'=0,'e'=''>`, string(b))
+
+	empty := ""
+	b, err = Marshal(OptionalsUndef{P: &empty, S: []int{}, M: map[string]interface{}{}})
+	require.Nil(t, err)
+	assert.Equal(t, `A<1,?,'p'='','pr'=?,'m'=A<1,?>,'s'={},'z'=0,'e'=''>`, string(b))
+}
+
 type Public struct {
 	a bool `oscript:"A,public"`
 	B bool `oscript:",public"`
@@ -531,6 +558,8 @@ func TestEncodeBytekind(t *testing.T) {
 		{byte(7), "7"},
 		{oscriptbyte(7), `A<1,?,'JB'=7>`},
 		{oscriptint(5), `A<1,?,'JI'=5>`},
+		{textbyte(7), `'TB:7'`},
+		{textint(5), `'TI:5'`},
 		{[]byte{0, 1}, `'AAE='`},
 		{[]oscriptbyte{0, 1}, `{A<1,?,'JB'=0>,A<1,?,'JB'=1>}`},
 		{[][]oscriptbyte{{0, 1}, {3}}, `{{A<1,?,'JB'=0>,A<1,?,'JB'=1>},{A<1,?,'JB'=3>}}`},
@@ -547,6 +576,64 @@ func TestEncodeBytekind(t *testing.T) {
 	}
 }
 
+// uuid is a type like github.com/google/uuid.UUID.
+type uuid [16]byte
+
+func (u uuid) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%x", u[:])), nil
+}
+
+func (u *uuid) UnmarshalText(b []byte) error {
+	if len(b) != 2*len(u) {
+		return fmt.Errorf("invalid uuid %q", b)
+	}
+
+	for i := range u {
+		v, err := strconv.ParseUint(string(b[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return err
+		}
+		u[i] = byte(v)
+	}
+	return nil
+}
+
+func TestStdlibTypes(t *testing.T) {
+	type stdlib struct {
+		URL    url.URL
+		URLPtr *url.URL
+		IP     net.IP
+		ID     uuid
+		Time   time.Time
+	}
+
+	u, _ := url.Parse("https://example.com/path?q=1")
+	v := stdlib{
+		URL:    *u,
+		URLPtr: u,
+		IP:     net.IPv4(10, 0, 0, 1),
+		ID:     uuid{0: 0xab, 15: 0x01},
+		Time:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := Marshal(v)
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'URL'='https://example.com/path?q=1','URLPtr'='https://example.com/path?q=1','IP'='10.0.0.1',"+
+		"'ID'='ab000000000000000000000000000001','Time'=D/2020/1/2:3:4:5>", string(b))
+
+	var got stdlib
+	require.Nil(t, Unmarshal(b, &got))
+	assert.Equal(t, v.URL.String(), got.URL.String())
+	assert.Equal(t, v.URLPtr.String(), got.URLPtr.String())
+	assert.True(t, v.IP.Equal(got.IP))
+	assert.Equal(t, v.ID, got.ID)
+	assert.Equal(t, v.Time, got.Time)
+
+	b, err = Marshal(struct{ URL *url.URL }{})
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'URL'=?>", string(b))
+}
+
 func TestTextMarshalerMapKeysAreSorted(t *testing.T) {
 	want := `A<1,?,'a:z'=3,'x:y'=1,'y:x'=2,'z:a'=4>`
 	b, err := Marshal(map[unmarshalerText]int{
@@ -568,6 +655,71 @@ func TestFloat64(t *testing.T) {
 	assert.Equal(t, want, string(b))
 }
 
+func TestMarshalSize(t *testing.T) {
+	v := map[string]interface{}{"K": strings.Repeat("a", 1024)}
+	want, err := Marshal(v)
+	require.Nil(t, err)
+
+	b, err := MarshalSize(v, len(want))
+	require.Nil(t, err)
+	assert.Equal(t, want, b)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SizeHint(len(want))
+	require.Nil(t, enc.Encode(v))
+	assert.Equal(t, want, buf.Bytes())
+}
+
+type fixedFloats struct {
+	Rate  float64  `oscript:"Rate,prec=2"`
+	Ptr   *float32 `oscript:"Ptr,prec=1"`
+	Small float64  `oscript:"Small"`
+}
+
+func TestFloatFixed(t *testing.T) {
+	f := float32(3)
+	v := fixedFloats{Rate: 1e21, Ptr: &f, Small: 1e-7}
+
+	b, err := Marshal(v)
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'Rate'=G1000000000000000000000.00,'Ptr'=G3.0,'Small'=G1e-7>", string(b))
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetFloatPrecision(-1)
+	require.Nil(t, enc.Encode(v))
+	assert.Equal(t, "A<1,?,'Rate'=G1000000000000000000000.00,'Ptr'=G3.0,'Small'=G0.0000001>", buf.String())
+
+	b, err = Marshal(1e-7)
+	require.Nil(t, err)
+	assert.Equal(t, "G1e-7", string(b), "encoder option must not leak through the pool")
+}
+
+type longInts struct {
+	ID    int64   `oscript:"ID,long"`
+	Count *uint32 `oscript:"Count,long,omitempty"`
+	Size  Long    `oscript:"Size"`
+	Plain int     `oscript:"Plain"`
+}
+
+func TestLong(t *testing.T) {
+	n := uint32(7)
+	v := longInts{ID: -12, Count: &n, Size: 3, Plain: 4}
+
+	b, err := Marshal(v)
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'ID'=L-12,'Count'=L7,'Size'=L3,'Plain'=4>", string(b))
+
+	b, err = Marshal(longInts{})
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'ID'=L0,'Size'=L0,'Plain'=0>", string(b))
+
+	var got longInts
+	require.Nil(t, Unmarshal([]byte("A<1,?,'ID'=12,'Count'=L7,'Size'=3,'Plain'=L4>"), &got))
+	assert.Equal(t, longInts{ID: 12, Count: &n, Size: 3, Plain: 4}, got)
+}
+
 func TestTime(t *testing.T) {
 	testdata := []struct {
 		data time.Time
@@ -575,14 +727,61 @@ func TestTime(t *testing.T) {
 	}{
 		{data: time.Date(2017, 12, 4, 11, 47, 16, 0, time.UTC), want: "D/2017/12/4:11:47:16"},
 		{data: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), want: "D/1/1/1:0:0:0"},
+		{data: time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), want: "D/0/1/1:0:0:0"},
+		{data: time.Date(-1, 12, 31, 23, 59, 59, 0, time.UTC), want: "D/-1/12/31:23:59:59"},
+		{data: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), want: "D/2020/2/29:0:0:0"},
+		{data: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), want: "D/9999/12/31:23:59:59"},
 	}
 
 	for _, tt := range testdata {
 		b, err := Marshal(tt.data)
 		require.Nil(t, err)
 		assert.Equal(t, tt.want, string(b))
+
+		var v time.Time
+		require.Nil(t, Unmarshal(b, &v))
+		assert.Equal(t, tt.data, v)
+	}
+
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	instant := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	newValue := func(tm time.Time, zero float64) interface{} {
+		return M{
+			"list": []interface{}{
+				M{"b": 1, "a": []interface{}{map[string]string{"y": "1", "x": "2"}}},
+				map[int]interface{}{10: tm, 9: zero},
+			},
+			"c": struct{ F float64 }{F: zero},
+		}
+	}
+	want := "A<1,?,'c'=A<1,?,'F'=G0>,'list'={A<1,?,'a'={A<1,?,'x'='2','y'='1'>},'b'=1>,A<1,?,'10'=D/2020/1/2:10:0:0,'9'=G0>}>"
+
+	for i := 0; i < 20; i++ { // the iteration of the maps is random
+		b, err := MarshalCanonical(newValue(instant.In(loc), math.Copysign(0, -1)))
+		require.Nil(t, err)
+		assert.Equal(t, want, string(b))
 	}
 
+	b, err := Marshal(newValue(instant.In(loc), math.Copysign(0, -1)))
+	require.Nil(t, err)
+	assert.Contains(t, string(b), "D/2020/1/2:13:0:0", "the default encoding keeps the location")
+	assert.Contains(t, string(b), "G-0")
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetCanonical()
+	require.Nil(t, enc.Encode(newValue(instant, 0)))
+	assert.Equal(t, want, buf.String())
+
+	_, err = MarshalCanonical(map[unmarshalerText]int{{"a", "b:c"}: 1, {"a:b", "c"}: 2})
+	assert.IsType(t, &UnsupportedValueError{}, err)
+
+	b, err = Marshal(instant.In(loc))
+	require.Nil(t, err)
+	assert.Equal(t, "D/2020/1/2:13:0:0", string(b), "encoder option must not leak through the pool")
 }
 
 type marshalBuf struct{}
@@ -606,6 +805,22 @@ func TestMarshalerBuf(t *testing.T) {
 	assert.Equal(t, want, string(b))
 }
 
+func TestRawMessage(t *testing.T) {
+	b, err := Marshal(struct {
+		A RawMessage
+		B RawMessage
+	}{A: RawMessage("A<1,?, 'k' = 1 >")})
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'A'=A<1,?,'k'=1>,'B'=?>", string(b))
+
+	_, err = Marshal(RawMessage("A<1,?"))
+	assert.NotNil(t, err)
+
+	var v struct{ A RawMessage }
+	require.Nil(t, Unmarshal([]byte("A<1,?,'A'={1,'s'}>"), &v))
+	assert.Equal(t, RawMessage("{1,'s'}"), v.A)
+}
+
 func TestError(t *testing.T) {
 	e := Error(1024)
 	want := "E1024"
@@ -662,3 +877,19 @@ func TestMarshalPanic(t *testing.T) {
 	Marshal(&marshalPanic{})
 	t.Error("Marshal should have panicked")
 }
+
+func TestEncoder_RecoverPanics(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.RecoverPanics()
+
+	err := enc.Encode(M{"v": marshalPanic{}})
+	var merr *MarshalerError
+	require.True(t, errors.As(err, &merr))
+	assert.Equal(t, reflect.TypeOf(marshalPanic{}), merr.Type)
+
+	var perr *PanicError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, 0xdead, perr.Value)
+	assert.Equal(t, 0, buf.Len())
+}
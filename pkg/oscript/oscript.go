@@ -30,6 +30,28 @@ func (sn sdoName) encode(e *encodeState, _ reflect.Value) {
 
 type SDOName struct{}
 
+// RawMessage is a raw encoded oscript value.
+// It implements Marshaler and Unmarshaler and can be used to delay oscript decoding
+// or precompute a oscript encoding.
+type RawMessage []byte
+
+// MarshalOscript returns m as the oscript encoding of m.
+func (m RawMessage) MarshalOscript() ([]byte, error) {
+	if m == nil {
+		return []byte("?"), nil
+	}
+	return m, nil
+}
+
+// UnmarshalOscript sets *m to a copy of data.
+func (m *RawMessage) UnmarshalOscript(data []byte) error {
+	if m == nil {
+		return errors.New("oscript.RawMessage: UnmarshalOscript on nil pointer")
+	}
+	*m = append((*m)[0:0], data...)
+	return nil
+}
+
 // Error is a error string.
 type Error int
 
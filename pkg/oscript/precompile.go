@@ -0,0 +1,32 @@
+package oscript
+
+import "reflect"
+
+// Precompile builds the cached encoders and fields of the types and of the types nested in them,
+// so the first Marshal or Unmarshal does not pay for reflection. It is safe to call concurrently.
+func Precompile(types ...reflect.Type) {
+	seen := make(map[reflect.Type]bool)
+	for _, t := range types {
+		precompile(t, seen)
+	}
+}
+
+func precompile(t reflect.Type, seen map[reflect.Type]bool) {
+	if t == nil || seen[t] {
+		return
+	}
+	seen[t] = true
+	typeEncoder(t)
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		precompile(t.Elem(), seen)
+	case reflect.Map:
+		precompile(t.Key(), seen)
+		precompile(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range cachedTypeFields(t) {
+			precompile(f.typ, seen)
+		}
+	}
+}
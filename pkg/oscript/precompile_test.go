@@ -0,0 +1,38 @@
+package oscript
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type precompileInner struct {
+	A string
+}
+
+type precompileOuter struct {
+	Inner []*precompileInner
+	Map   map[string]precompileInner
+}
+
+func TestPrecompile(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Precompile(reflect.TypeOf(precompileOuter{}))
+		}()
+	}
+	wg.Wait()
+
+	for _, v := range []interface{}{precompileOuter{}, []*precompileInner{}, precompileInner{}} {
+		_, ok := encoderCache.Load(reflect.TypeOf(v))
+		assert.True(t, ok, "%T", v)
+	}
+
+	_, ok := fieldCache.Load(reflect.TypeOf(precompileInner{}))
+	assert.True(t, ok)
+}
@@ -0,0 +1,70 @@
+package oscript
+
+import "bytes"
+
+// ReplaceValues returns data with the values of the keys of the objects replaced by the encoded values returned
+// by replace, the other bytes of data are kept as is, e.g. the literals L123 and G1.50. The depth of the top-level
+// object is 0, nil returned by replace keeps the value. data is returned as is when no value is replaced.
+func ReplaceValues(data []byte, replace func(depth int, key string) []byte) ([]byte, error) {
+	var (
+		scan     scanner
+		out      []byte
+		last     int    // end of data copied to out
+		keyStart = -1   // start of the key literal
+		repl     []byte // replacement of the value of the last key
+		depth    int    // length of the parse stack in the object of the replaced value
+		valStart = -1   // start of the replaced value
+	)
+	scan.reset()
+	for i := 0; i < len(data); i++ {
+		op := scan.step(&scan, data[i])
+		n := len(scan.parseState)
+		switch op {
+		case scanError:
+			return nil, scan.err
+
+		case scanBeginLiteral, scanBeginObject, scanBeginArray:
+			if op == scanBeginLiteral && n > 0 && scan.parseState[n-1] == parseObjectKey {
+				keyStart = i
+				break
+			}
+
+			if repl != nil && valStart < 0 {
+				valStart = i
+			}
+
+		case scanObjectKey:
+			if valStart >= 0 { // the key inside of the replaced value
+				break
+			}
+
+			key, ok := unquote(bytes.TrimRight(data[keyStart:i], " \t\r\n"))
+			if !ok {
+				return nil, &SyntaxError{"invalid object key", int64(i)}
+			}
+
+			if repl = replace(n-1, key); repl != nil {
+				depth, valStart = n, -1
+			}
+
+		case scanObjectValue, scanEndObject:
+			if repl == nil || valStart < 0 || (op == scanObjectValue && n != depth) || (op == scanEndObject && n != depth-1) {
+				break
+			}
+
+			end := valStart + len(bytes.TrimRight(data[valStart:i], " \t\r\n"))
+			out = append(out, data[last:valStart]...)
+			out = append(out, repl...)
+			last, repl, valStart = end, nil, -1
+		}
+	}
+
+	if scan.eof() == scanError {
+		return nil, scan.err
+	}
+
+	if out == nil {
+		return data, nil
+	}
+	return append(out, data[last:]...), nil
+}
@@ -0,0 +1,37 @@
+package oscript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceValues(t *testing.T) {
+	replace := func(depth int, key string) []byte {
+		switch {
+		case key == "secret":
+			return []byte("'***'")
+		case key == "Results" && depth == 0:
+			return []byte("?")
+		}
+		return nil
+	}
+
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{in: "A<1,?,'ID'=L123,'f'=G1.50>", want: "A<1,?,'ID'=L123,'f'=G1.50>"},
+		{in: "A<1,?,'ID'=L123,'secret'='it''s',  'f'=G1.50>", want: "A<1,?,'ID'=L123,'secret'='***',  'f'=G1.50>"},
+		{in: "A<1,?,'a'={A<1,?,'secret'=A<1,?,'x'={1,2}> >},'secret'=D/2020/1/2:3:4:5>", want: "A<1,?,'a'={A<1,?,'secret'='***' >},'secret'='***'>"},
+		{in: "A<1,?,'Results'=A<1,?,'Results'=1>,'_Status'=0>", want: "A<1,?,'Results'=?,'_Status'=0>"},
+		{in: "{A<1,?,'Results'=1>}", want: "{A<1,?,'Results'=1>}"},
+	} {
+		got, err := ReplaceValues([]byte(tt.in), replace)
+		require.Nil(t, err, tt.in)
+		assert.Equal(t, tt.want, string(got), tt.in)
+	}
+
+	_, err := ReplaceValues([]byte("A<1,?,'secret'='p"), replace)
+	assert.NotNil(t, err)
+}
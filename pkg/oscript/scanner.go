@@ -10,6 +10,7 @@ package oscript
 // before diving into the scanner itself.
 
 import (
+	"bytes"
 	"strconv"
 )
 
@@ -22,9 +23,17 @@ func Valid(data []byte) bool {
 // scan is passed in for use by checkValid to avoid an allocation.
 func checkValid(data []byte, scan *scanner) error {
 	scan.reset()
-	for _, c := range data {
+	for i := 0; i < len(data); i++ {
+		if scan.inString {
+			n := stringSpan(data[i:])
+			scan.bytes += int64(n)
+			if i += n; i == len(data) {
+				break
+			}
+		}
+
 		scan.bytes++
-		if scan.step(scan, c) == scanError {
+		if scan.step(scan, data[i]) == scanError {
 			return scan.err
 		}
 	}
@@ -70,6 +79,16 @@ type scanner struct {
 
 	// total bytes consumed, updated by decoder.Decode
 	bytes int64
+
+	// position in the date literal
+	datePart   int
+	dateDigits int
+
+	// inside of the string, the bytes up to the quote or backslash do not change the state
+	inString bool
+
+	// the doubled quote ends the string instead of being the escaped quote
+	strictQuotes bool
 }
 
 // These values are returned by the state transition functions
@@ -116,6 +135,7 @@ func (s *scanner) reset() {
 	s.parseState = s.parseState[0:0]
 	s.err = nil
 	s.endTop = false
+	s.inString = false
 }
 
 // eof tells the scanner that the end of input has been reached.
@@ -183,6 +203,7 @@ func stateBeginValue(s *scanner, c byte) int {
 		return scanBeginArray
 	case '\'':
 		s.step = stateInString
+		s.inString = true
 		return scanBeginLiteral
 	case '-':
 		s.step = stateLNeg
@@ -212,16 +233,53 @@ func stateBeginValue(s *scanner, c byte) int {
 
 // stateInDate is the state after reading `D`.
 func stateInDate(s *scanner, c byte) int {
-	switch c {
-	case '/', ':':
+	if c == '/' {
+		s.step = stateDateYear
+		s.datePart, s.dateDigits = 0, 0
 		return scanContinue
-	default:
-		if '0' <= c && c <= '9' {
-			return scanContinue
+	}
+	return s.error(c, "in date literal")
+}
+
+// stateDateYear is the state after reading `D/`, the year may be negative.
+func stateDateYear(s *scanner, c byte) int {
+	s.step = stateDateNum
+	if c == '-' {
+		return scanContinue
+	}
+	return stateDateNum(s, c)
+}
+
+// stateDateNum is the state in the parts of the date `D/2006/1/2:15:4:5`, all parts except year
+// have one or two digits.
+func stateDateNum(s *scanner, c byte) int {
+	if '0' <= c && c <= '9' {
+		s.dateDigits++
+		if s.datePart > 0 && s.dateDigits > 2 {
+			return s.error(c, "in date literal")
 		}
-		//return s.error(c, "in time literal")
+		return scanContinue
 	}
-	return stateEndValue(s, c)
+
+	if s.dateDigits == 0 {
+		return s.error(c, "in date literal")
+	}
+
+	if s.datePart == 5 {
+		return stateEndValue(s, c)
+	}
+
+	sep := byte('/')
+	if s.datePart >= 2 {
+		sep = ':'
+	}
+
+	if c != sep {
+		return s.error(c, "in date literal")
+	}
+	s.datePart++
+	s.dateDigits = 0
+	return scanContinue
 }
 
 // stateInError is the state after reading `E`.
@@ -310,6 +368,7 @@ func stateBeginString(s *scanner, c byte) int {
 	}
 	if c == '\'' {
 		s.step = stateInString
+		s.inString = true
 		return scanBeginLiteral
 	}
 	return s.error(c, "looking for beginning of object key string")
@@ -381,23 +440,61 @@ func stateEndTop(s *scanner, c byte) int {
 // stateInString is the state after reading `'`.
 func stateInString(s *scanner, c byte) int {
 	if c == '\'' {
-		s.step = stateEndValue
+		s.step = stateInStringQuote
+		if s.strictQuotes {
+			s.step = stateEndValue
+		}
+		s.inString = false
 		return scanContinue
 	}
 
 	if c == '\\' {
 		s.step = stateInStringEsc
+		s.inString = false
 		return scanContinue
 	}
 
 	return scanContinue
 }
 
+// stateInStringQuote is the state after reading `'` during a quoted string, the next quote makes
+// the doubled quote '' which some servers send instead of \'.
+func stateInStringQuote(s *scanner, c byte) int {
+	if c == '\'' {
+		s.step = stateInString
+		s.inString = true
+		return scanContinue
+	}
+	return stateEndValue(s, c)
+}
+
+// stringSpan returns the number of the bytes at the beginning of data which are neither a quote nor a backslash.
+// Inside of the string such bytes do not change the state of the scanner and may be skipped at once.
+func stringSpan(data []byte) int {
+	if len(data) > stringWindow {
+		data = data[:stringWindow] // bounds the work when the escapes are frequent
+	}
+
+	n := bytes.IndexByte(data, '\'')
+	if n == -1 {
+		n = len(data)
+	}
+
+	if i := bytes.IndexByte(data[:n], '\\'); i != -1 {
+		return i
+	}
+	return n
+}
+
+// stringWindow is the maximum number of the bytes searched by stringSpan at once.
+const stringWindow = 256
+
 // stateInStringEsc is the state after reading `'\` during a quoted string.
 func stateInStringEsc(s *scanner, c byte) int {
 	switch c {
 	case 'b', 'f', 'n', 'r', 't', '\\', '/', '"', '\'':
 		s.step = stateInString
+		s.inString = true
 		return scanContinue
 	case 'u':
 		s.step = stateInStringEscU
@@ -441,6 +538,7 @@ func stateInStringEscU12(s *scanner, c byte) int {
 func stateInStringEscU123(s *scanner, c byte) int {
 	if '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F' {
 		s.step = stateInString
+		s.inString = true
 		return scanContinue
 	}
 	// numbers
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 var validTests = []struct {
@@ -56,6 +58,60 @@ var ex1i = `{
 	G-5e+2
 }`
 
+func TestStringSpan(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"ab'c", 2},
+		{"a\\b'c", 1},
+		{"'", 0},
+		{strings.Repeat("a", 1000), stringWindow},
+		{strings.Repeat("a", 100) + "\\", 100},
+	}
+
+	for _, tt := range tests {
+		if got := stringSpan([]byte(tt.data)); got != tt.want {
+			t.Errorf("stringSpan(%q) = %d; want %d", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestLongString(t *testing.T) {
+	want := strings.Repeat("plain text ", 1000) + strings.Repeat("'quoted' \\ ", 100) + strings.Repeat("x", 1000)
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !Valid(data) {
+		t.Fatal("Valid = false; want true")
+	}
+
+	var got string
+	if err := Unmarshal(data, &got); err != nil || got != want {
+		t.Fatalf("Unmarshal = %q, %v; want %q", got, err, want)
+	}
+
+	// the string is split between reads of the decoder
+	got = ""
+	dec := NewDecoder(iotest.OneByteReader(bytes.NewReader(data)))
+	if err := dec.Decode(&got); err != nil || got != want {
+		t.Fatalf("Decode = %q, %v; want %q", got, err, want)
+	}
+
+	var v struct{ A, B string }
+	if err := Unmarshal([]byte("A<1,?,'A'='"+strings.Repeat("a", 500)+"','B'='b'>"), &v); err != nil || len(v.A) != 500 || v.B != "b" {
+		t.Fatalf("Unmarshal = %+v, %v", v, err)
+	}
+
+	if Valid([]byte("'" + strings.Repeat("a", 500))) {
+		t.Error("Valid of unterminated string = true; want false")
+	}
+}
+
 func TestCompact(t *testing.T) {
 	var buf bytes.Buffer
 	for _, tt := range examples {
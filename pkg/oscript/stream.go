@@ -12,6 +12,8 @@ type Decoder struct {
 	d       decodeState
 	scanp   int   // start of unread data in buf
 	scanned int64 // amount of data already scanned
+	valueN  int64 // size of the last decoded value
+	charset transcoder // the values are transcoded to UTF-8 when it is set
 	scan    scanner
 	err     error
 
@@ -32,6 +34,21 @@ func NewDecoder(r io.Reader) *Decoder {
 // non-ignored, exported fields in the destination.
 func (dec *Decoder) DisallowUnknownFields() { dec.d.disallowUnknownFields = true }
 
+// OnUnknownField sets f which is called for every key of the object which does not match any field
+// of the destination struct, e.g. to find the fields added in the new version of the server.
+func (dec *Decoder) OnUnknownField(f func(UnknownField)) { dec.d.unknownField = f }
+
+// StrictQuotes causes the Decoder to reject the doubled quote '' inside of the strings,
+// by default it is decoded as the escaped quote \'.
+func (dec *Decoder) StrictQuotes() {
+	dec.scan.strictQuotes = true
+	dec.d.scan.strictQuotes = true
+}
+
+// CollectErrors causes the Decoder to decode the value best-effort and to return DecodeErrors
+// with all errors of the types instead of the first one. It suits validation of the large payloads.
+func (dec *Decoder) CollectErrors() { dec.d.collectErrors = true }
+
 // Decode reads the next Oscript-encoded value from its
 // input and stores it in the value pointed to by v.
 //
@@ -51,12 +68,20 @@ func (dec *Decoder) Decode(v interface{}) error {
 	}
 
 	// Read whole value into buffer.
+	dec.valueN = 0
 	n, err := dec.readValue()
 	if err != nil {
 		return err
 	}
-	dec.d.init(dec.buf[dec.scanp : dec.scanp+n])
+	dec.valueN = int64(n)
+	data := dec.buf[dec.scanp : dec.scanp+n]
 	dec.scanp += n
+	if dec.charset != nil && !isASCII(data) {
+		if data, err = dec.charset.Bytes(data); err != nil {
+			return err
+		}
+	}
+	dec.d.init(data)
 
 	// Don't save err from unmarshal into dec.err:
 	// the connection is still usable since we read a complete oscript
@@ -67,6 +92,13 @@ func (dec *Decoder) Decode(v interface{}) error {
 	return err
 }
 
+// ValueBytes returns the number of bytes of the input consumed by the last call to Decode,
+// including the white space before the value. It is zero when the value has not been read.
+// The size is known even when the value failed to unmarshal.
+func (dec *Decoder) ValueBytes() int64 {
+	return dec.valueN
+}
+
 // Buffered returns a reader of the data remaining in the Decoder's
 // buffer. The reader is valid until the next call to Decode.
 func (dec *Decoder) Buffered() io.Reader {
@@ -83,9 +115,18 @@ func (dec *Decoder) readValue() (int, error) {
 Input:
 	for {
 		// Look in the buffer for a new value.
-		for i, c := range dec.buf[scanp:] {
+		buf := dec.buf[scanp:]
+		for i := 0; i < len(buf); i++ {
+			if dec.scan.inString {
+				n := stringSpan(buf[i:])
+				dec.scan.bytes += int64(n)
+				if i += n; i == len(buf) {
+					break
+				}
+			}
+
 			dec.scan.bytes++
-			v := dec.scan.step(&dec.scan, c)
+			v := dec.scan.step(&dec.scan, buf[i])
 			if v == scanEnd {
 				scanp += i
 				break Input
@@ -446,6 +487,12 @@ type Encoder struct {
 	err error
 
 	enabledEncodeNL bool
+	sizeHint        int
+	floatFixed      bool
+	floatPrec       int
+	canonical       bool
+	charset         transcoder
+	recoverPanics   bool
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -463,6 +510,14 @@ func (enc *Encoder) Encode(v interface{}) error {
 		return enc.err
 	}
 	e := newEncodeState()
+	if enc.sizeHint > 0 {
+		e.Grow(enc.sizeHint)
+	}
+	e.floatFixed, e.floatPrec = enc.floatFixed, enc.floatPrec
+	e.canonical = enc.canonical
+	e.charset = enc.charset
+	e.recoverPanics = enc.recoverPanics
+
 	err := e.marshal(v)
 	if err != nil {
 		return err
@@ -488,3 +543,26 @@ func (enc *Encoder) Encode(v interface{}) error {
 func (enc *Encoder) EnableEncodeNL() {
 	enc.enabledEncodeNL = true
 }
+
+// SizeHint sets expected size of the encoded values, the internal buffer is preallocated before every call Encode.
+func (enc *Encoder) SizeHint(n int) {
+	enc.sizeHint = n
+}
+
+// SetFloatPrecision forces fixed-point format of the floats instead of switching to exponent for
+// the very small and large values, prec is the number of digits after the point, -1 uses the smallest
+// number of digits necessary to represent the value exactly. The tag option "prec" takes precedence.
+func (enc *Encoder) SetFloatPrecision(prec int) {
+	enc.floatFixed, enc.floatPrec = true, prec
+}
+
+// SetCanonical enables the canonical encoding of the values, see MarshalCanonical.
+func (enc *Encoder) SetCanonical() {
+	enc.canonical = true
+}
+
+// RecoverPanics makes Encode return the panic of MarshalOscript as MarshalerError with PanicError
+// of the type of the marshaler instead of propagating the panic.
+func (enc *Encoder) RecoverPanics() {
+	enc.recoverPanics = true
+}
@@ -123,6 +123,110 @@ func TestDecoderBuffered(t *testing.T) {
 	}
 }
 
+func TestDecoderValueBytes(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`A<1,?,'Name'='Gopher'> 'tail' 12`))
+	var v interface{}
+	for _, want := range []int64{22, 7, 3} {
+		if err := d.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+
+		if g := d.ValueBytes(); g != want {
+			t.Errorf("ValueBytes = %d; want %d", g, want)
+		}
+	}
+
+	var n int
+	d = NewDecoder(strings.NewReader(`'text'`))
+	if err := d.Decode(&n); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if g := d.ValueBytes(); g != 6 {
+		t.Errorf("ValueBytes of the failed value = %d; want 6", g)
+	}
+
+	if err := d.Decode(&n); err == nil {
+		t.Fatal("expected EOF")
+	}
+
+	if g := d.ValueBytes(); g != 0 {
+		t.Errorf("ValueBytes after EOF = %d; want 0", g)
+	}
+}
+
+func TestDecoderOnUnknownField(t *testing.T) {
+	type inner struct {
+		ID int64
+	}
+	var v struct {
+		Name  string
+		Inner inner
+		Map   map[string]int
+	}
+
+	var got []UnknownField
+	d := NewDecoder(strings.NewReader(`A<1,?,'Name'='Gopher','Tags'={'a','b'},'Inner'=A<1,?,'ID'=1,'Size'=L12>,` +
+		`'Map'=A<1,?,'x'=1>,'Long'='` + strings.Repeat("x", 100) + `'>`))
+	d.OnUnknownField(func(f UnknownField) { got = append(got, f) })
+	if err := d.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	typ, innerType := reflect.TypeOf(v), reflect.TypeOf(inner{})
+	want := []UnknownField{
+		{Type: typ, Field: "Tags", Sample: "{'a','b'}"},
+		{Type: innerType, Field: "Size", Sample: "L12"},
+		{Type: typ, Field: "Long", Sample: "'" + strings.Repeat("x", 63)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unknown fields = %+v; want %+v", got, want)
+	}
+
+	if v.Name != "Gopher" || v.Inner.ID != 1 || v.Map["x"] != 1 {
+		t.Errorf("decoded = %+v", v)
+	}
+}
+
+func TestDecoderCollectErrors(t *testing.T) {
+	var v struct {
+		A int
+		B string
+		C []int
+		D bool
+	}
+
+	d := NewDecoder(strings.NewReader(`A<1,?,'A'='a','B'=2,'C'={1,'x',3},'D'=true>`))
+	d.CollectErrors()
+	err := d.Decode(&v)
+
+	errs, ok := err.(DecodeErrors)
+	if !ok {
+		t.Fatalf("Decode error = %T; want DecodeErrors", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("len(errs) = %d; want 3: %v", len(errs), errs)
+	}
+	for _, err := range errs {
+		if _, ok := err.(*UnmarshalTypeError); !ok {
+			t.Errorf("error = %T; want *UnmarshalTypeError", err)
+		}
+	}
+	if want := []int{1, 0, 3}; !reflect.DeepEqual(v.C, want) || !v.D {
+		t.Errorf("value = %+v; want C=%v D=true", v, want)
+	}
+	if !strings.Contains(err.Error(), "\n") {
+		t.Errorf("Error() = %q; want errors separated by new line", err.Error())
+	}
+
+	// the next value is decoded without the previous errors
+	d = NewDecoder(strings.NewReader(`A<1,?,'A'=1>`))
+	d.CollectErrors()
+	if err := d.Decode(&v); err != nil {
+		t.Errorf("Decode error = %v; want nil", err)
+	}
+}
+
 func nlines(s string, n int) string {
 	if n <= 0 {
 		return ""
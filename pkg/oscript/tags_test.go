@@ -21,3 +21,14 @@ func TestTagParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestTagGet(t *testing.T) {
+	_, opts := parseTag("field,omitempty,prec=2")
+	if v, ok := opts.Get("prec"); !ok || v != "2" {
+		t.Errorf("Get(prec) = %q, %v", v, ok)
+	}
+
+	if v, ok := opts.Get("omitempty"); ok {
+		t.Errorf("Get(omitempty) = %q, %v", v, ok)
+	}
+}
@@ -0,0 +1,125 @@
+package oscript
+
+import (
+	"strconv"
+	"time"
+)
+
+// Value is a value with the fixed literal of oscript, it is created by Int, Real, Str and Date.
+// The arguments of the services are validated by the type, e.g. Real(1) is sent as G1 while float64
+// from the decoded JSON would be sent as integer by the mistake of the conversion.
+type Value struct {
+	kind byte // 'I', 'G', 'S' or 'D'
+	i    int64
+	f    float64
+	s    string
+	t    time.Time
+}
+
+// Int returns integer value, it is encoded as 123.
+func Int(n int64) Value {
+	return Value{kind: 'I', i: n}
+}
+
+// Real returns real value, it is encoded as G1.5 even if it is integral.
+func Real(f float64) Value {
+	return Value{kind: 'G', f: f}
+}
+
+// Str returns string value, it is encoded as '...'.
+func Str(s string) Value {
+	return Value{kind: 'S', s: s}
+}
+
+// Date returns date value, it is encoded as D/2006/1/2:15:4:5.
+func Date(t time.Time) Value {
+	return Value{kind: 'D', t: t}
+}
+
+// Interface returns the value as int64, float64, string or time.Time, nil for the zero Value.
+func (v Value) Interface() interface{} {
+	switch v.kind {
+	case 'I':
+		return v.i
+	case 'G':
+		return v.f
+	case 'S':
+		return v.s
+	case 'D':
+		return v.t
+	}
+	return nil
+}
+
+// MarshalOscriptBuf implements MarshalerBuf, the zero Value is encoded as undefined.
+func (v Value) MarshalOscriptBuf(buf Buffer) error {
+	switch v.kind {
+	case 'S':
+		buf.WriteStringValue(v.s)
+	case 0:
+		buf.WriteByte(undefined)
+	default:
+		buf.WriteEncode(v.Interface())
+	}
+	return nil
+}
+
+// Long is an integer encoded in the long form L123, some methods of the services require it.
+// Both forms are decoded to it.
+type Long int64
+
+// MarshalOscriptBuf implements MarshalerBuf.
+func (n Long) MarshalOscriptBuf(buf Buffer) error {
+	buf.WriteByte('L')
+	buf.WriteString(strconv.FormatInt(int64(n), 10))
+	return nil
+}
+
+// Args builds the arguments of the service method with the fixed literals of the values.
+//
+//	args := oscript.NewArgs().Int("ID", 2000).Real("Size", 1).Date("Since", since)
+type Args struct {
+	m M
+}
+
+// NewArgs returns empty arguments.
+func NewArgs() *Args {
+	return &Args{m: M{}}
+}
+
+// Int sets the integer argument.
+func (a *Args) Int(key string, n int64) *Args {
+	return a.Set(key, Int(n))
+}
+
+// Real sets the real argument.
+func (a *Args) Real(key string, f float64) *Args {
+	return a.Set(key, Real(f))
+}
+
+// Str sets the string argument.
+func (a *Args) Str(key, s string) *Args {
+	return a.Set(key, Str(s))
+}
+
+// Date sets the date argument.
+func (a *Args) Date(key string, t time.Time) *Args {
+	return a.Set(key, Date(t))
+}
+
+// Set sets the argument of any type which is encoded by Marshal, e.g. bool, struct or the list.
+func (a *Args) Set(key string, v interface{}) *Args {
+	a.m[key] = v
+	return a
+}
+
+// M returns the arguments, they are passed to the call as oscript.M.
+func (a *Args) M() M {
+	return a.m
+}
+
+// MarshalOscriptBuf implements MarshalerBuf.
+func (a *Args) MarshalOscriptBuf(buf Buffer) error {
+	buf.WriteEncode(a.m)
+	return nil
+}
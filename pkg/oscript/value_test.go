@@ -0,0 +1,41 @@
+package oscript
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	date := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		in   Value
+		want string
+	}{
+		{in: Int(12), want: "12"},
+		{in: Real(1), want: "G1"},
+		{in: Real(0.5), want: "G0.5"},
+		{in: Str("it's"), want: `'it\'s'`},
+		{in: Date(date), want: "D/2020/1/2:10:0:0"},
+		{in: Value{}, want: "?"},
+	} {
+		b, err := Marshal(tt.in)
+		require.Nil(t, err)
+		assert.Equal(t, tt.want, string(b))
+	}
+}
+
+func TestArgs(t *testing.T) {
+	args := NewArgs().Int("ID", 2000).Real("Size", 3).Str("Name", "a").Date("Since", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)).Set("Force", true)
+
+	b, err := Marshal(args)
+	require.Nil(t, err)
+	assert.Equal(t, "A<1,?,'Force'=true,'ID'=2000,'Name'='a','Since'=D/2020/1/2:0:0:0,'Size'=G3>", string(b))
+
+	m, err := Marshal(args.M())
+	require.Nil(t, err)
+	assert.Equal(t, string(b), string(m))
+	assert.Equal(t, int64(2000), args.M()["ID"].(Value).Interface())
+}
@@ -0,0 +1,160 @@
+package ot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// FileRef is a version of the document, zero version is the latest one.
+type FileRef struct {
+	NodeID  int64
+	Version int64
+}
+
+// PrefetchSink returns the writer of the content of the file, the writer is closed when the content is written
+// or the download has failed.
+type PrefetchSink func(ref FileRef) (io.WriteCloser, error)
+
+// PrefetchOptions are options of the Prefetcher.
+type PrefetchOptions struct {
+	// Concurrency is the maximum number of the simultaneous downloads, the default is 1.
+	// It should not exceed the size of the connection pool of the endpoint.
+	Concurrency int
+	// Ordered calls the sink in the order of the files, the contents downloaded ahead
+	// of the turn are kept in memory, at most Concurrency of them.
+	Ordered bool
+}
+
+// Prefetcher downloads contents of many files concurrently into the sinks.
+type Prefetcher struct {
+	s    *Session
+	opts PrefetchOptions
+}
+
+// NewPrefetcher creates prefetcher of the files.
+func NewPrefetcher(s *Session, opts PrefetchOptions) *Prefetcher {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	return &Prefetcher{s: s, opts: opts}
+}
+
+// prefetched is the downloaded content waiting for the turn.
+type prefetched struct {
+	buf bytes.Buffer
+	err error
+}
+
+// Run downloads the files and returns the first error, the other downloads are canceled on error.
+func (p *Prefetcher) Run(ctx context.Context, refs []FileRef, sink PrefetchSink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			first = err
+			cancel()
+		})
+	}
+
+	slots := make(chan struct{}, p.opts.Concurrency)
+	results := make([]chan *prefetched, len(refs))
+	for i := range results {
+		results[i] = make(chan *prefetched, 1)
+	}
+
+	if p.opts.Ordered {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range refs {
+				var res *prefetched
+				select {
+				case res = <-results[i]:
+				case <-ctx.Done():
+					return
+				}
+
+				if res.err == nil {
+					res.err = p.write(refs[i], &res.buf, sink)
+				}
+
+				if res.err != nil {
+					fail(res.err)
+					return
+				}
+				<-slots
+			}
+		}()
+	}
+
+Dispatch:
+	for i, ref := range refs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break Dispatch
+		}
+
+		if ctx.Err() != nil { // the slot is released after the failure
+			break
+		}
+
+		wg.Add(1)
+		go func(ref FileRef, out chan<- *prefetched) {
+			defer wg.Done()
+
+			if p.opts.Ordered {
+				res := &prefetched{}
+				_, res.err = p.s.ReadFile(ctx, ref.NodeID, ref.Version, &res.buf)
+				out <- res // the slot is released after writing into the sink
+				return
+			}
+
+			defer func() { <-slots }()
+			if err := p.stream(ctx, ref, sink); err != nil {
+				fail(err)
+			}
+		}(ref, results[i])
+	}
+
+	wg.Wait()
+	if first == nil {
+		return ctx.Err()
+	}
+	return first
+}
+
+// stream writes the content directly into the sink.
+func (p *Prefetcher) stream(ctx context.Context, ref FileRef, sink PrefetchSink) error {
+	w, err := sink(ref)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.s.ReadFile(ctx, ref.NodeID, ref.Version, w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (p *Prefetcher) write(ref FileRef, r io.Reader, sink PrefetchSink) error {
+	w, err := sink(ref)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
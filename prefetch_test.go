@@ -0,0 +1,95 @@
+package ot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type prefetchBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *prefetchBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func prefetchSession(t *testing.T, content string) *Session {
+	b, err := ioutil.ReadFile("testdata/read-file")
+	require.Nil(t, err)
+
+	return session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		id := req["Arguments"].(map[string]interface{})["ID"].(int64)
+		time.Sleep(time.Duration(5-id%5) * 5 * time.Millisecond) // the first files are downloaded last
+
+		if id == 0 {
+			w.WriteString("A<1,?,'_Status'=1,'_errMsg'='failed'>")
+		} else {
+			w.Write(b)
+			w.WriteString(content)
+		}
+		assert.Nil(t, w.Flush())
+	})
+}
+
+func TestPrefetcher_Run(t *testing.T) {
+	t.Parallel()
+
+	contentFile := "content of the file"
+	for _, ordered := range []bool{false, true} {
+		var (
+			mu    sync.Mutex
+			order []int64
+			sinks = map[int64]*prefetchBuffer{}
+		)
+
+		refs := []FileRef{{NodeID: 1}, {NodeID: 2}, {NodeID: 3}, {NodeID: 4}, {NodeID: 5, Version: 2}}
+		err := NewPrefetcher(prefetchSession(t, contentFile), PrefetchOptions{Concurrency: 3, Ordered: ordered}).
+			Run(context.Background(), refs, func(ref FileRef) (io.WriteCloser, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				order = append(order, ref.NodeID)
+				sinks[ref.NodeID] = &prefetchBuffer{}
+				return sinks[ref.NodeID], nil
+			})
+		require.Nil(t, err)
+
+		require.Len(t, sinks, len(refs))
+		for _, ref := range refs {
+			assert.Equal(t, contentFile, sinks[ref.NodeID].String())
+			assert.True(t, sinks[ref.NodeID].closed)
+		}
+
+		if ordered {
+			assert.Equal(t, []int64{1, 2, 3, 4, 5}, order)
+		}
+	}
+}
+
+func TestPrefetcher_RunError(t *testing.T) {
+	t.Parallel()
+
+	for _, ordered := range []bool{false, true} {
+		var got []int64
+		refs := []FileRef{{NodeID: 1}, {NodeID: 0}, {NodeID: 2}}
+		err := NewPrefetcher(prefetchSession(t, "content of the file"), PrefetchOptions{Ordered: ordered}).
+			Run(context.Background(), refs, func(ref FileRef) (io.WriteCloser, error) {
+				got = append(got, ref.NodeID)
+				return &prefetchBuffer{}, nil
+			})
+		assert.EqualError(t, err, "ot: failed")
+		assert.Equal(t, []int64{1}, got[:1])
+		assert.NotContains(t, got, int64(2))
+	}
+}
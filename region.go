@@ -0,0 +1,92 @@
+package ot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AttrRegion returns name of the search region of the attribute of the category, e.g. Attr_12345_2.
+func AttrRegion(catID int64, attrID int) string {
+	return "Attr_" + strconv.FormatInt(catID, 10) + "_" + strconv.Itoa(attrID)
+}
+
+// ParseAttrRegion returns ids of the category and the attribute of the search region,
+// it reports false when the region is not a region of the attribute.
+func ParseAttrRegion(region string) (catID int64, attrID int, ok bool) {
+	s := strings.Split(region, "_")
+	if len(s) != 3 || s[0] != "Attr" {
+		return 0, 0, false
+	}
+
+	catID, err := strconv.ParseInt(s[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	attrID, err = strconv.Atoi(s[2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return catID, attrID, true
+}
+
+// attrID returns id of the attribute of the key "category.version.attribute".
+func (v *Value) attrID() (int, bool) {
+	s := strings.Split(v.Key, ".")
+	if len(s) != 3 {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(s[2])
+	return id, err == nil
+}
+
+// Region returns name of the search region of the attribute.
+func (c *Category) Region(name string) (string, error) {
+	if c == nil {
+		return "", errCategory
+	}
+
+	catID, _ := c.IDVersion()
+	for i := range c.Data {
+		if c.Data[i].Description != name {
+			continue
+		}
+
+		attrID, ok := c.Data[i].attrID()
+		if !ok || catID == 0 {
+			return "", fmt.Errorf("invalid key of attribute \"%s\" \"%s\"", name, c.Data[i].Key)
+		}
+		return AttrRegion(catID, attrID), nil
+	}
+	return "", fmt.Errorf("not found attribute \"%s\"", name)
+}
+
+// RegionAttr returns name of the attribute of the search region.
+func (c *Category) RegionAttr(region string) (string, error) {
+	if c == nil {
+		return "", errCategory
+	}
+
+	catID, attrID, ok := ParseAttrRegion(region)
+	if id, _ := c.IDVersion(); !ok || id != catID {
+		return "", fmt.Errorf("region \"%s\" is not attribute of the category \"%s\"", region, c.DisplayName)
+	}
+
+	for i := range c.Data {
+		if id, ok := c.Data[i].attrID(); ok && id == attrID {
+			return c.Data[i].Description, nil
+		}
+	}
+	return "", fmt.Errorf("not found attribute of region \"%s\"", region)
+}
+
+// FacetByAttr requests counts of the results by values of the attribute of the category.
+func FacetByAttr(cat *Category, name string, limit int) (FacetRequest, error) {
+	region, err := cat.Region(name)
+	if err != nil {
+		return FacetRequest{}, err
+	}
+	return FacetByRegion(region, limit), nil
+}
@@ -0,0 +1,54 @@
+package ot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttrRegion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Attr_12345_2", AttrRegion(12345, 2))
+
+	catID, attrID, ok := ParseAttrRegion("Attr_12345_2")
+	assert.True(t, ok)
+	assert.Equal(t, int64(12345), catID)
+	assert.Equal(t, 2, attrID)
+
+	for _, region := range []string{"OTName", "Attr_12345", "Attr_x_2", "Attr_1_y", "Attr_1_2_3"} {
+		_, _, ok := ParseAttrRegion(region)
+		assert.False(t, ok, region)
+	}
+}
+
+func TestCategory_Region(t *testing.T) {
+	t.Parallel()
+
+	cat := &Category{DisplayName: "Invoice", Key: "12345.3", Data: []Value{
+		{Description: "Number", Key: "12345.3.2"},
+		{Description: "Broken", Key: "12345"},
+	}}
+
+	region, err := cat.Region("Number")
+	require.Nil(t, err)
+	assert.Equal(t, "Attr_12345_2", region)
+
+	name, err := cat.RegionAttr("Attr_12345_2")
+	require.Nil(t, err)
+	assert.Equal(t, "Number", name)
+
+	_, err = cat.Region("Broken")
+	assert.EqualError(t, err, `invalid key of attribute "Broken" "12345"`)
+	_, err = cat.Region("Unknown")
+	assert.EqualError(t, err, `not found attribute "Unknown"`)
+	_, err = cat.RegionAttr("Attr_1_2")
+	assert.EqualError(t, err, `region "Attr_1_2" is not attribute of the category "Invoice"`)
+	_, err = cat.RegionAttr("Attr_12345_9")
+	assert.EqualError(t, err, `not found attribute of region "Attr_12345_9"`)
+
+	f, err := FacetByAttr(cat, "Number", 10)
+	require.Nil(t, err)
+	assert.Equal(t, FacetRequest{Kind: FacetRegion, Region: "Attr_12345_2", Limit: 10}, f)
+}
@@ -0,0 +1,87 @@
+package ot
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RetryPolicy describes how failed connection attempts are retried.
+type RetryPolicy struct {
+	// Attempts is a maximum number of attempts, less than 2 disables retrying.
+	Attempts int
+	// Backoff is a delay before second attempt, it doubles on every next attempt.
+	Backoff time.Duration
+	// Budget limits the total number of retries, nil is unlimited. The policy is copied to the sessions
+	// of the endpoint, so the budget is shared by all of them.
+	Budget *RetryBudget
+}
+
+// wait waits before next attempt, reports false if attempts are over, the budget is spent or context is done.
+func (p RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	if attempt+1 >= p.Attempts || !p.Budget.take() {
+		return false
+	}
+
+	t := time.NewTimer(p.Backoff << uint(attempt))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// RetryBudget limits the number of retries made in the sliding window of time, e.g. when the server is down
+// the workers of the batch job fail fast instead of multiplying the retries.
+type RetryBudget struct {
+	max    int
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	retries []time.Time // times of the retries in the window, the oldest first
+}
+
+// NewRetryBudget creates budget of max retries per window.
+func NewRetryBudget(max int, window time.Duration) *RetryBudget {
+	return &RetryBudget{max: max, window: window, now: time.Now}
+}
+
+// Remaining returns the number of retries left in the current window.
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expire(b.now())
+	return b.max - len(b.retries)
+}
+
+// take spends one retry, reports false if the budget is spent.
+func (b *RetryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.expire(now)
+	if len(b.retries) >= b.max {
+		return false
+	}
+
+	b.retries = append(b.retries, now)
+	return true
+}
+
+func (b *RetryBudget) expire(now time.Time) {
+	i := 0
+	for i < len(b.retries) && now.Sub(b.retries[i]) >= b.window {
+		i++
+	}
+	b.retries = b.retries[i:]
+}
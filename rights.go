@@ -55,6 +55,8 @@ func (s *Session) AddNodeRight(ctx context.Context, id int64, right NodeRight) e
 	if err := errIn(c.Read(nil)); err != nil {
 		return err
 	}
+
+	s.events.publish(Event{Type: RightsChanged, NodeID: id})
 	return nil
 }
 
@@ -94,6 +96,8 @@ func (s *Session) UpdateNodeRight(ctx context.Context, id int64, right NodeRight
 	if err := errIn(c.Read(nil)); err != nil {
 		return err
 	}
+
+	s.events.publish(Event{Type: RightsChanged, NodeID: id})
 	return nil
 }
 
@@ -115,5 +119,52 @@ func (s *Session) RemoveNodeRight(ctx context.Context, id int64, right NodeRight
 	if err := errIn(c.Read(nil)); err != nil {
 		return err
 	}
+
+	s.events.publish(Event{Type: RightsChanged, NodeID: id})
+	return nil
+}
+
+// setNodeRights sets the rights of the node as given: owner, owner group and public rights are updated when they are
+// present, ACL rights are added or updated and the ACL rights which are missing in rights are removed.
+func (s *Session) setNodeRights(ctx context.Context, id int64, rights NodeRights) error {
+	current, err := s.GetNodeRights(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range []NodeRight{rights.OwnerRight, rights.OwnerGroupRight, rights.PublicRight} {
+		if r.Type == "" { // the node has not the right
+			continue
+		}
+
+		if err := s.UpdateNodeRight(ctx, id, r); err != nil {
+			return err
+		}
+	}
+
+	stale := make(map[int64]bool, len(current.ACLRights))
+	for _, r := range current.ACLRights {
+		stale[r.ID] = true
+	}
+
+	for _, r := range rights.ACLRights {
+		update := s.AddNodeRight
+		if stale[r.ID] {
+			update = s.UpdateNodeRight
+			delete(stale, r.ID)
+		}
+
+		if err := update(ctx, id, r); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range current.ACLRights {
+		if stale[r.ID] {
+			if err := s.RemoveNodeRight(ctx, id, r); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,30 @@
+package ot
+
+import (
+	"sync"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// SchemaDrift is a field of the results sent by the server which is unknown to the struct the results are decoded to.
+type SchemaDrift struct {
+	Type   string // the struct, e.g. "ot.Node"
+	Field  string
+	Sample string // the encoded value of the first occurrence, cut to 64 bytes
+}
+
+// schemaDrift reports the unknown fields once per the struct and the field.
+type schemaDrift struct {
+	report func(SchemaDrift)
+	seen   sync.Map // map[SchemaDrift without sample]struct{}
+}
+
+func (d *schemaDrift) observe(f oscript.UnknownField) {
+	key := SchemaDrift{Type: f.Type.String(), Field: f.Field}
+	if _, seen := d.seen.LoadOrStore(key, struct{}{}); seen {
+		return
+	}
+
+	key.Sample = f.Sample
+	d.report(key)
+}
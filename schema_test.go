@@ -0,0 +1,38 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpoint_SchemaDrift(t *testing.T) {
+	t.Parallel()
+
+	endp := endpoint(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=0,'NewOutput'=1,'Results'=A<1,?,'ID'=1,'Name'='gopher','RetentionClass'=A<1,?,'ID'=7>>>")
+		assert.Nil(t, w.Flush())
+	})
+
+	var (
+		mu  sync.Mutex
+		got []SchemaDrift
+	)
+	endp.drift = &schemaDrift{report: func(d SchemaDrift) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, d)
+	}}
+
+	for i := 0; i < 2; i++ {
+		node, err := endp.User("u", "p").GetNode(context.Background(), 1)
+		require.Nil(t, err)
+		assert.Equal(t, "gopher", node.Name)
+	}
+	assert.Equal(t, []SchemaDrift{{Type: "ot.Node", Field: "RetentionClass", Sample: "A<1,?,'ID'=7>"}}, got)
+}
@@ -0,0 +1,237 @@
+package ot
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const searchService = "SearchService"
+
+// SearchRequest is a query of the full text search.
+type SearchRequest struct {
+	Query     string `oscript:"QueryString"`           // query in the language of the search engine
+	Start     int    `oscript:"FirstResultToRetrieve"` // 1 is the first result
+	Limit     int    `oscript:"NumResultsToRetrieve"`
+	Summaries bool   `oscript:"Summaries"`    // summaries of the content are returned in SearchResult.Summary
+	Highlight bool   `oscript:"HitHighlight"` // fragments with the hits are returned in SearchResult.Highlights
+
+	Facets  []FacetRequest `oscript:"Facets,omitempty"`  // counts of the facets are returned in SearchResponse.Facets
+	Filters []FacetFilter  `oscript:"Filters,omitempty"` // drill-down to the selected values of the facets
+
+	// DataSource and Slice select the partitions of the index, the empty ones are taken from the session
+	// (see Session.WithSearchSource), otherwise the default data source of the server is searched.
+	DataSource string `oscript:"DataSourceName,omitempty"`
+	Slice      string `oscript:"SliceName,omitempty"`
+}
+
+// searchSource is the default data source and slice of the search of the session.
+type searchSource struct {
+	dataSource string
+	slice      string
+}
+
+// WithSearchSource returns session which searches the data source and the slice when they are not set
+// in SearchRequest, e.g. when the index is split into partitions and the default data source is not the right one.
+func (s *Session) WithSearchSource(dataSource, slice string) *Session {
+	c := s.clone()
+	c.search = searchSource{dataSource: dataSource, slice: slice}
+	return c
+}
+
+// SearchResult is a found node. Summary and highlights are HTML fragments, the hits are marked by tags,
+// use StripHTML to get the plain text.
+type SearchResult struct {
+	ID         int64    `oscript:"ID"`
+	Name       string   `oscript:"Name"`
+	Type       string   `oscript:"Type"`
+	Score      float64  `oscript:"Score"`
+	Summary    string   `oscript:"Summary"`
+	Highlights []string `oscript:"HitHighlights"`
+}
+
+// PlainSummary returns summary without markup.
+func (r *SearchResult) PlainSummary() string {
+	return StripHTML(r.Summary)
+}
+
+// PlainHighlights returns highlights without markup.
+func (r *SearchResult) PlainHighlights() []string {
+	if r.Highlights == nil {
+		return nil
+	}
+
+	plain := make([]string, len(r.Highlights))
+	for i, h := range r.Highlights {
+		plain[i] = StripHTML(h)
+	}
+	return plain
+}
+
+// SearchResponse is a page of the results of the search.
+type SearchResponse struct {
+	Total   int            `oscript:"NumberOfResults"` // total number of the results of the query
+	Results []SearchResult `oscript:"Results"`
+	Facets  []Facet        `oscript:"Facets"` // in the order of SearchRequest.Facets
+}
+
+// FacetKind is a kind of the facet.
+type FacetKind string
+
+const (
+	FacetSubtype FacetKind = "Subtype" // values are the subtypes of the nodes
+	FacetDate    FacetKind = "Date"    // values are the buckets of the dates of the region
+	FacetRegion  FacetKind = "Region"  // values are the values of the region, e.g. attribute of the category
+)
+
+// DateInterval is a size of the buckets of the date facet.
+type DateInterval string
+
+const (
+	IntervalDay     DateInterval = "Day"
+	IntervalWeek    DateInterval = "Week"
+	IntervalMonth   DateInterval = "Month"
+	IntervalQuarter DateInterval = "Quarter"
+	IntervalYear    DateInterval = "Year"
+)
+
+// FacetRequest requests counts of the values of the facet.
+type FacetRequest struct {
+	Kind     FacetKind    `oscript:"Kind"`
+	Region   string       `oscript:"Region,omitempty"`    // region of FacetDate and FacetRegion, e.g. OTModifyDate or Attr_12345_2
+	Interval DateInterval `oscript:"Interval,omitempty"`  // only FacetDate
+	Limit    int          `oscript:"MaxValues,omitempty"` // zero is the default limit of the server
+}
+
+// FacetBySubtype requests counts of the results by subtypes.
+func FacetBySubtype(limit int) FacetRequest {
+	return FacetRequest{Kind: FacetSubtype, Limit: limit}
+}
+
+// FacetByDate requests counts of the results by buckets of the dates of the region.
+func FacetByDate(region string, interval DateInterval) FacetRequest {
+	return FacetRequest{Kind: FacetDate, Region: region, Interval: interval}
+}
+
+// FacetByRegion requests counts of the results by values of the region.
+func FacetByRegion(region string, limit int) FacetRequest {
+	return FacetRequest{Kind: FacetRegion, Region: region, Limit: limit}
+}
+
+// FacetValue is a value of the facet with the number of the results.
+type FacetValue struct {
+	Value   string `oscript:"Value"` // subtype number, start date of the bucket in ISO format or value of the region
+	Display string `oscript:"DisplayValue"`
+	Count   int    `oscript:"Count"`
+}
+
+// Subtype returns the subtype of the value of FacetSubtype.
+func (v FacetValue) Subtype() (Subtype, bool) {
+	n, err := strconv.Atoi(v.Value)
+	return Subtype(n), err == nil
+}
+
+// Facet is the counts of the values of the requested facet.
+type Facet struct {
+	Kind   FacetKind    `oscript:"Kind"`
+	Region string       `oscript:"Region"`
+	Values []FacetValue `oscript:"Values"`
+}
+
+// Filter returns filter which drills down to the values of the facet.
+func (f *Facet) Filter(values ...string) FacetFilter {
+	return FacetFilter{Kind: f.Kind, Region: f.Region, Values: values}
+}
+
+// FacetFilter restricts the results to any of the values of the facet.
+type FacetFilter struct {
+	Kind   FacetKind `oscript:"Kind"`
+	Region string    `oscript:"Region,omitempty"`
+	Values []string  `oscript:"Values"`
+}
+
+// Search searches nodes by full text query.
+func (s *Session) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if req.DataSource == "" {
+		req.DataSource = s.search.dataSource
+	}
+
+	if req.Slice == "" {
+		req.Slice = s.search.slice
+	}
+
+	var res SearchResponse
+	if err := errIn(c.Exec(searchService, "Search", s.auth, oscript.M{"searchRequest": req}, &res)); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// inlineTags are the tags which do not separate words.
+var inlineTags = map[string]bool{
+	"a": true, "b": true, "em": true, "font": true, "i": true, "mark": true, "small": true,
+	"span": true, "strong": true, "sub": true, "sup": true, "u": true,
+}
+
+// StripHTML returns text of the HTML fragment: the tags and comments are removed with the content
+// of script and style elements, the entities are unescaped and the whitespaces are collapsed.
+// The result is plain text, it must be escaped again to be put into HTML.
+func StripHTML(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		if strings.HasPrefix(s, "<!--") {
+			s = skipPast(s, "-->")
+			continue
+		}
+
+		name := tagName(s)
+		s = skipPast(s, ">")
+		if name == "script" || name == "style" {
+			s = skipPast(skipPast(s, "</"+name), ">")
+		}
+
+		if !inlineTags[name] {
+			b.WriteByte(' ') // e.g. <br> or </p>
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// tagName returns lower case name of the tag at the start of s.
+func tagName(s string) string {
+	s = strings.TrimPrefix(s[1:], "/")
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	return strings.ToLower(s[:i])
+}
+
+// skipPast returns s after the first occurrence of sep case-insensitively, empty string when sep is not found.
+func skipPast(s, sep string) string {
+	i := strings.Index(strings.ToLower(s), strings.ToLower(sep))
+	if i < 0 {
+		return ""
+	}
+	return s[i+len(sep):]
+}
@@ -0,0 +1,126 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Search(t *testing.T) {
+	t.Parallel()
+
+	res, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "SearchService", req["ServiceName"])
+		assert.Equal(t, "Search", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{
+			"searchRequest": map[string]interface{}{
+				"QueryString":           "invoice",
+				"FirstResultToRetrieve": int64(1),
+				"NumResultsToRetrieve":  int64(10),
+				"Summaries":             true,
+				"HitHighlight":          true,
+			},
+		}, req["Arguments"])
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'NumberOfResults'=25,'Results'={A<1,?,'ID'=5,'Name'='a.pdf','Type'='Document'," +
+			"'Score'=G0.5,'Summary'='The <b>invoice</b> &amp; bill','HitHighlights'={'<em>invoice</em> 1','paid <em>invoice</em>'}>}>>")
+		assert.Nil(t, w.Flush())
+	}).Search(context.Background(), SearchRequest{Query: "invoice", Start: 1, Limit: 10, Summaries: true, Highlight: true})
+	require.Nil(t, err)
+
+	require.Len(t, res.Results, 1)
+	assert.Equal(t, 25, res.Total)
+	assert.Equal(t, 0.5, res.Results[0].Score)
+	assert.Equal(t, "The invoice & bill", res.Results[0].PlainSummary())
+	assert.Equal(t, []string{"invoice 1", "paid invoice"}, res.Results[0].PlainHighlights())
+}
+
+func TestStripHTML(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct{ in, exp string }{
+		{"", ""},
+		{"plain", "plain"},
+		{"<b>hit</b>!", "hit!"},
+		{"a<br>b<p>c</p>", "a b c"},
+		{"a <!-- <b>x</b> --> b", "a b"},
+		{"a<script type=\"x\">alert('<b>')</script>b", "a b"},
+		{"a<STYLE>p{}</STYLE>b", "a b"},
+		{"&lt;b&gt; &quot;q&quot;", `<b> "q"`},
+		{"  many \n spaces ", "many spaces"},
+		{"broken <b", "broken"},
+		{"x<scripts>y", "x y"},
+	}
+
+	for _, tt := range testCases {
+		assert.Equal(t, tt.exp, StripHTML(tt.in), tt.in)
+	}
+}
+
+func TestSession_SearchFacets(t *testing.T) {
+	t.Parallel()
+
+	res, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		sr := req["Arguments"].(map[string]interface{})["searchRequest"].(map[string]interface{})
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"Kind": "Subtype", "MaxValues": int64(5)},
+			map[string]interface{}{"Kind": "Date", "Region": "OTModifyDate", "Interval": "Month"},
+			map[string]interface{}{"Kind": "Region", "Region": "Attr_12345_2"},
+		}, sr["Facets"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"Kind": "Subtype", "Values": []interface{}{"144"}},
+		}, sr["Filters"])
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'NumberOfResults'=3,'Results'={},'Facets'={" +
+			"A<1,?,'Kind'='Subtype','Values'={A<1,?,'Value'='144','DisplayValue'='Document','Count'=3>}>," +
+			"A<1,?,'Kind'='Date','Region'='OTModifyDate','Values'={A<1,?,'Value'='2020-01-01','DisplayValue'='January 2020','Count'=3>}>," +
+			"A<1,?,'Kind'='Region','Region'='Attr_12345_2','Values'={}>}>>")
+		assert.Nil(t, w.Flush())
+	}).Search(context.Background(), SearchRequest{
+		Query:   "invoice",
+		Facets:  []FacetRequest{FacetBySubtype(5), FacetByDate("OTModifyDate", IntervalMonth), FacetByRegion("Attr_12345_2", 0)},
+		Filters: []FacetFilter{(&Facet{Kind: FacetSubtype}).Filter("144")},
+	})
+	require.Nil(t, err)
+
+	require.Len(t, res.Facets, 3)
+	assert.Equal(t, []FacetValue{{Value: "144", Display: "Document", Count: 3}}, res.Facets[0].Values)
+	st, ok := res.Facets[0].Values[0].Subtype()
+	assert.True(t, ok)
+	assert.Equal(t, SubtypeDocument, st)
+	assert.Equal(t, FacetFilter{Kind: FacetDate, Region: "OTModifyDate", Values: []string{"2020-01-01"}}, res.Facets[1].Filter("2020-01-01"))
+	assert.Empty(t, res.Facets[2].Values)
+}
+
+func TestSession_WithSearchSource(t *testing.T) {
+	t.Parallel()
+
+	var got []interface{}
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		sr := req["Arguments"].(map[string]interface{})["searchRequest"].(map[string]interface{})
+		got = append(got, []interface{}{sr["DataSourceName"], sr["SliceName"]})
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'NumberOfResults'=0,'Results'={}>>")
+		assert.Nil(t, w.Flush())
+	})
+
+	_, err := s.Search(context.Background(), SearchRequest{Query: "invoice"})
+	require.Nil(t, err)
+
+	ds := s.WithSearchSource("Archive", "2019")
+	_, err = ds.Search(context.Background(), SearchRequest{Query: "invoice"})
+	require.Nil(t, err)
+
+	_, err = ds.Search(context.Background(), SearchRequest{Query: "invoice", Slice: "2020"})
+	require.Nil(t, err)
+
+	assert.Equal(t, []interface{}{
+		[]interface{}{nil, nil},
+		[]interface{}{"Archive", "2019"},
+		[]interface{}{"Archive", "2020"},
+	}, got)
+}
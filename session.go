@@ -6,6 +6,8 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/itcomusic/ot/internal/client"
 
@@ -19,19 +21,69 @@ var (
 
 // Session a information about authentication user.
 type Session struct {
-	ep   *Endpoint
-	auth fmt.Stringer
+	ep       *Endpoint
+	auth     fmt.Stringer
+	index    ContentIndex
+	readOnly bool
+	nodes    *nodeCache
+	events   *EventBus
+	calls    *CallCache
+	search   searchSource
 }
 
 func (s *Session) clone() *Session {
+	ep := *s.ep
 	return &Session{
-		ep: &Endpoint{
-			dialer: s.ep.dialer,
-		},
-		auth: s.auth,
+		ep:       &ep,
+		auth:     s.auth,
+		index:    s.index,
+		readOnly: s.readOnly,
+		nodes:    s.nodes,
+		events:   s.events,
+		calls:    s.calls,
+		search:   s.search,
 	}
 }
 
+// readMethods are prefixes of the methods which do not change data.
+var readMethods = []string{"Get", "List", "Search", "Find", "Authenticate", "Refresh"}
+
+// ReadOnly returns session which rejects calls of the methods that may change data with ErrReadOnlySession.
+// Only methods starting with Get, List, Search, Find and authentication methods are allowed.
+func (s *Session) ReadOnly() *Session {
+	c := s.clone()
+	c.readOnly = true
+	return c
+}
+
+func checkReadOnly(_, method string) error {
+	for _, p := range readMethods {
+		if strings.HasPrefix(method, p) {
+			return nil
+		}
+	}
+	return ErrReadOnlySession
+}
+
+// As returns session with other authentication which shares connections of the endpoint,
+// it allows to serve many users without creating endpoint per user.
+func (s *Session) As(a Auth) *Session {
+	c := s.clone()
+	c.auth = a
+	return c
+}
+
+// WithNodeCache returns session which caches up to size nodes got by GetNode for ttl, zero ttl means no expiration.
+// The cached node is removed when it is updated, renamed or deleted through the session, the changes
+// made by other sessions or users are visible after expiration. The cache is not shared with other sessions
+// because the visible nodes depend on the permissions of the user.
+// The cached node is copied shallowly, the slices and the pointers of the returned node must not be modified.
+func (s *Session) WithNodeCache(size int, ttl time.Duration) *Session {
+	c := s.clone()
+	c.nodes = newNodeCache(size, ttl)
+	return c
+}
+
 // Debug wraps dialer in debug.
 func (s *Session) Debug(w io.Writer) *Session {
 	if reflect.TypeOf(s.ep.dialer) == typeDialDebug {
@@ -44,23 +96,150 @@ func (s *Session) Debug(w io.Writer) *Session {
 }
 
 func (s *Session) connect(ctx context.Context) (*client.Client, error) {
-	c, err := s.ep.dialer.DialContext(ctx)
+	var c io.ReadWriteCloser
+	if s.readOnly {
+		c = &lazyConn{dial: func() (io.ReadWriteCloser, error) { return s.dial(ctx) }}
+	} else {
+		var err error
+		if c, err = s.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	cl := client.New(c)
+	cl.Watch(ctx)
+	if s.ep.metrics != nil {
+		cl.Observe(s.ep.metrics.ObserveCall)
+	}
+
+	if s.readOnly {
+		cl.Guard(checkReadOnly)
+	}
+
+	if s.ep.capture != nil {
+		cl.Capture(s.captureFunc())
+	}
+
+	if s.ep.charset != nil {
+		cl.SetCharset(s.ep.charset)
+	}
+
+	if s.ep.drift != nil {
+		cl.OnUnknownField(s.ep.drift.observe)
+	}
+
+	if s.ep.handshakeTimeout > 0 {
+		cl.SetHandshakeTimeout(s.ep.handshakeTimeout)
+	}
+
+	if s.ep.appID != "" {
+		cl.SetHeader(headerAppID, s.ep.appID)
+	}
+
+	if id := correlationID(ctx); id != "" {
+		cl.SetHeader(headerCorrelation, id)
+	}
+	return cl, nil
+}
+
+// dial dials the connection, dialing is repeated according to the retry policy.
+func (s *Session) dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	for attempt := 0; ; attempt++ {
+		c, err := s.ep.conns.DialContext(ctx, s.ep.dialer)
+		if err == nil {
+			return c, nil
+		}
+
+		if err == conn.ErrClosed || !s.ep.retry.wait(ctx, attempt) {
+			return nil, err
+		}
+	}
+}
+
+// lazyConn dials the connection on the first write. The read-only session uses it, so the request
+// rejected by checkReadOnly is not sent and the connection is not dialed and authenticated for it.
+type lazyConn struct {
+	dial func() (io.ReadWriteCloser, error)
+
+	mu     sync.Mutex
+	conn   io.ReadWriteCloser
+	closed bool
+}
+
+func (l *lazyConn) get() (io.ReadWriteCloser, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, conn.ErrClosed
+	}
+
+	if l.conn == nil {
+		c, err := l.dial()
+		if err != nil {
+			return nil, err
+		}
+		l.conn = c
+	}
+	return l.conn, nil
+}
+
+func (l *lazyConn) Write(p []byte) (int, error) {
+	c, err := l.get()
+	if err != nil {
+		return 0, err
+	}
+	return c.Write(p)
+}
+
+func (l *lazyConn) Read(p []byte) (int, error) {
+	c, err := l.get()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	return c.Read(p)
+}
 
-	return client.New(c), nil
+func (l *lazyConn) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	if l.conn == nil {
+		return nil
+	}
+	return l.conn.Close()
 }
 
 // Call invokes the service function, waits for it to complete, and returns its error status.
-func (s *Session) Call(ctx context.Context, serviceMethod string, args oscript.M, reply interface{}) error {
+// The args is oscript.M or the pre-encoded arguments: oscript.RawMessage or oscript.MarshalerBuf.
+// Use *Outputs as reply to get the named outputs besides Results, such calls are never cached by CallCache,
+// since it keeps only Results of the response.
+func (s *Session) Call(ctx context.Context, serviceMethod string, args interface{}, reply interface{}) error {
 	dot := strings.LastIndex(serviceMethod, ".")
 	if dot <= 0 || dot == len(serviceMethod)-1 {
 		return errServiceMethod
 	}
 
-	if args == nil {
+	switch a := args.(type) {
+	case nil:
 		args = oscript.M{}
+	case oscript.M:
+		if a == nil {
+			args = oscript.M{}
+		}
+	case oscript.RawMessage:
+		if a == nil {
+			args = oscript.M{}
+		}
+	case oscript.MarshalerBuf:
+	default:
+		return errArguments
+	}
+
+	o, ok := reply.(*Outputs)
+	if !ok {
+		return s.exec(ctx, serviceMethod[:dot], serviceMethod[dot+1:], args, reply)
 	}
 
 	c, err := s.connect(ctx)
@@ -69,8 +248,26 @@ func (s *Session) Call(ctx context.Context, serviceMethod string, args oscript.M
 	}
 	defer c.Close()
 
-	if err := errIn(c.Exec(serviceMethod[:dot], serviceMethod[dot+1:], s.auth, args, reply)); err != nil {
+	if err := c.Write(serviceMethod[:dot], serviceMethod[dot+1:], s.auth, args); err != nil {
 		return err
 	}
-	return nil
+	return errIn(c.ReadOutputs(o.Results, o.Named))
+}
+
+// Outputs is the reply of Call for the services which return the named outputs besides Results.
+// Results is decoded from Results of the response, Named is decoded from the whole response,
+// for instance *struct{ Count int `oscript:"count"` }.
+type Outputs struct {
+	Results interface{}
+	Named   interface{}
+}
+
+// Call invokes the service function and returns the decoded result.
+func Call[T any](ctx context.Context, s *Session, serviceMethod string, args oscript.M) (T, error) {
+	var reply T
+	if err := s.Call(ctx, serviceMethod, args, &reply); err != nil {
+		var zero T
+		return zero, err
+	}
+	return reply, nil
 }
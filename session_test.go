@@ -4,10 +4,16 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/itcomusic/ot/internal/conn"
 	"github.com/itcomusic/ot/pkg/oscript"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -74,3 +80,210 @@ func TestSession_DebugCall(t *testing.T) {
 	assert.Equal(t, exp, w.String())
 	assert.Equal(t, "hello", result)
 }
+
+func TestSession_DebugOptions(t *testing.T) {
+	t.Parallel()
+
+	var w bytes.Buffer
+	endp := endpoint(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		buf.WriteString("A<1,?,'_Status'=0,'Results'='hello'>")
+		assert.Nil(t, buf.Flush())
+	})
+	endp.dialer = &conn.DialDebug{Dial: endp.dialer, Out: &w, MaxBytes: 10, Sample: 2, JSON: true}
+
+	s := endp.User("u", "p")
+	for i := 0; i < 3; i++ {
+		var result string
+		require.Nil(t, s.Call(context.Background(), "service.method", nil, &result))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+	require.Len(t, lines, 4) // the first and the third calls
+	assert.Equal(t, `{"op":"read","bytes":36,"head":"A\u003c1,?","tail":"llo'\u003e","skipped":26}`, lines[1])
+}
+
+type closedDialer struct{}
+
+func (closedDialer) DialContext(_ context.Context) (io.ReadWriteCloser, error) {
+	c, server := net.Pipe()
+	server.Close()
+	return c, nil
+}
+
+// countDialer counts the dials of the closed connections.
+type countDialer struct {
+	n int32
+}
+
+func (d *countDialer) DialContext(ctx context.Context) (io.ReadWriteCloser, error) {
+	atomic.AddInt32(&d.n, 1)
+	return closedDialer{}.DialContext(ctx)
+}
+
+func TestSession_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	d := &countDialer{}
+	budget := NewRetryBudget(10, time.Minute)
+	ss := (&Endpoint{dialer: d, conns: &conn.Group{}, retry: RetryPolicy{Attempts: 3, Budget: budget}}).User("u", "p").ReadOnly()
+	assert.Equal(t, ErrReadOnlySession, ss.DeleteNode(context.Background(), 1))
+	assert.Equal(t, ErrReadOnlySession, ss.Call(context.Background(), "DocumentManagement.UpdateNode", nil, nil))
+	assert.Equal(t, ErrReadOnlySession, ss.CreateFile(context.Background(), 1, "name", &FileAttr{}, strings.NewReader("content")))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&d.n), "the rejected requests are not dialed")
+	assert.Equal(t, 10, budget.Remaining(), "the rejected upload is not retried")
+
+	node, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1>>")
+		assert.Nil(t, w.Flush())
+	}).ReadOnly().GetNode(context.Background(), 1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), node.ID)
+}
+
+func TestSession_Header(t *testing.T) {
+	t.Parallel()
+
+	endp := endpoint(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "app", req["_AppID"])
+		assert.Equal(t, "1234", req["X-Correlation"])
+
+		buf.WriteString("A<1,?,'_Status'=0,'Results'=?>")
+		assert.Nil(t, buf.Flush())
+	})
+	endp.appID = "app"
+
+	ctx := ContextWithCorrelationID(context.Background(), "1234")
+	require.Nil(t, endp.User("u", "p").Call(ctx, "service.method", nil, nil))
+}
+
+func TestSession_As(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "token", req["_Cookie"])
+		assert.Nil(t, req["_UserName"])
+
+		buf.WriteString("A<1,?,'_Status'=0,'Results'='hello'>")
+		assert.Nil(t, buf.Flush())
+	})
+
+	as := s.As(TokenAuth("token"))
+	assert.Equal(t, s.ep.dialer, as.ep.dialer)
+	assert.Equal(t, s.ep.conns, as.ep.conns)
+	assert.Equal(t, "token", as.Token())
+
+	var result string
+	require.Nil(t, as.Call(context.Background(), "service.method", nil, &result))
+	assert.Equal(t, "", s.Token())
+}
+
+type rawArgs struct{ name string }
+
+func (a rawArgs) MarshalOscriptBuf(buf oscript.Buffer) error {
+	buf.WriteString("A<1,?,'name'=")
+	buf.WriteStringValue(a.name)
+	buf.WriteByte('>')
+	return nil
+}
+
+func TestSession_CallRawArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []interface{}{
+		oscript.RawMessage(`A<1,?,'name'='gopher'>`),
+		rawArgs{name: "gopher"},
+	}
+
+	for i, args := range tests {
+		var result string
+		err := session(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+			assert.Equal(t, map[string]interface{}{"name": "gopher"}, req["Arguments"])
+
+			buf.WriteString("A<1,?,'_Status'=0,'Results'='hello'>")
+			assert.Nil(t, buf.Flush())
+		}).Call(context.Background(), "service.method", args, &result)
+
+		require.Nil(t, err, fmt.Sprintf("#%d", i))
+		assert.Equal(t, "hello", result, fmt.Sprintf("#%d", i))
+	}
+
+	assert.Equal(t, errArguments, NewEndpoint("").User("", "").Call(context.Background(), "service.method", 1, nil))
+}
+
+func TestCall(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "ok":
+			buf.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='gopher'>>")
+		default:
+			buf.WriteString("A<1,?,'_Status'=1,'_errMsg'='failed'>")
+		}
+		assert.Nil(t, buf.Flush())
+	})
+
+	node, err := Call[*Node](context.Background(), s, "service.ok", nil)
+	require.Nil(t, err)
+	assert.Equal(t, &Node{ID: 1, Name: "gopher"}, node)
+
+	name, err := Call[string](context.Background(), s, "service.failed", nil)
+	assert.EqualError(t, err, "ot: failed")
+	assert.Equal(t, "", name)
+}
+
+func TestSession_CallOutputs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		results []string
+		named   struct {
+			Count  int    `oscript:"count"`
+			Cookie string `oscript:"cookie"`
+		}
+	)
+	err := session(t, func(r io.Reader, buf *bufio.Writer, req map[string]interface{}) {
+		buf.WriteString("A<1,?,'_Status'=0,'Results'={'a','b'},'count'=10,'cookie'='next'>")
+		assert.Nil(t, buf.Flush())
+	}).Call(context.Background(), "service.method", nil, &Outputs{Results: &results, Named: &named})
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, results)
+	assert.Equal(t, 10, named.Count)
+	assert.Equal(t, "next", named.Cookie)
+}
+
+type marshalPanic struct{}
+
+func (marshalPanic) MarshalOscript() ([]byte, error) { panic("broken marshaler") }
+
+// recordConn records written data.
+type recordConn struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *recordConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *recordConn) DialContext(_ context.Context) (io.ReadWriteCloser, error) {
+	return c, nil
+}
+
+func TestSession_CallMarshalerPanic(t *testing.T) {
+	t.Parallel()
+
+	c := &recordConn{}
+	err := (&Endpoint{dialer: c, conns: &conn.Group{}}).User("u", "p").Call(context.Background(), "service.method", oscript.M{"value": marshalPanic{}}, nil)
+
+	var merr *oscript.MarshalerError
+	require.True(t, errors.As(err, &merr))
+	assert.Equal(t, "ot: service.method oscript: error calling MarshalOscript for type ot.marshalPanic: panic: broken marshaler", err.Error())
+	var perr *oscript.PanicError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "broken marshaler", perr.Value)
+	assert.Equal(t, 0, c.Len(), "the partial request is not sent")
+	assert.True(t, c.closed)
+}
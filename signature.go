@@ -0,0 +1,86 @@
+package ot
+
+import (
+	"context"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const signatureService = "ContentSignature"
+
+// SignatureState is a state of the signature or the whole signature request.
+type SignatureState string
+
+const (
+	SignaturePending  SignatureState = "Pending"
+	SignatureSigned   SignatureState = "Signed"
+	SignatureRejected SignatureState = "Rejected"
+	SignatureCanceled SignatureState = "Canceled"
+)
+
+// SignatureRequest is a request to sign the version of the document.
+type SignatureRequest struct {
+	NodeID  int64               `oscript:"NodeID"`
+	Version int64               `oscript:"VersionNum"` // 0 is the latest version
+	Signers []int64             `oscript:"Signers"`    // ids of the users
+	Comment string              `oscript:"Comment"`
+	Due     Nullable[time.Time] `oscript:"DueDate,omitundef"`
+}
+
+// Signature is a signature of the signer.
+type Signature struct {
+	Signer  int64          `oscript:"Signer"`
+	State   SignatureState `oscript:"State"`
+	Date    time.Time      `oscript:"SignDate"`
+	Comment string         `oscript:"Comment"`
+}
+
+// SignatureStatus is a status of the signature request.
+type SignatureStatus struct {
+	ID         int64          `oscript:"ID"`
+	NodeID     int64          `oscript:"NodeID"`
+	Version    int64          `oscript:"VersionNum"`
+	State      SignatureState `oscript:"State"`
+	Signatures []Signature    `oscript:"Signatures"`
+}
+
+// signatureErr returns ErrSignatureUnavailable when the signature service is not installed on the server.
+func signatureErr(err error) error {
+	if _, ok := err.(*ServiceNotFoundError); ok {
+		return ErrSignatureUnavailable
+	}
+	return err
+}
+
+// RequestSignature requests signatures of the version of the document and returns the created request.
+// ErrSignatureUnavailable is returned when the content signature module is not installed.
+func (s *Session) RequestSignature(ctx context.Context, req SignatureRequest) (*SignatureStatus, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var st SignatureStatus
+	if err := errIn(c.Exec(signatureService, "RequestSignature", s.auth, oscript.M{"request": req}, &st)); err != nil {
+		return nil, signatureErr(err)
+	}
+	return &st, nil
+}
+
+// GetSignatureStatus gets status of the signature request.
+// ErrSignatureUnavailable is returned when the content signature module is not installed.
+func (s *Session) GetSignatureStatus(ctx context.Context, id int64) (*SignatureStatus, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var st SignatureStatus
+	if err := errIn(c.Exec(signatureService, "GetSignatureStatus", s.auth, oscript.M{"ID": id}, &st)); err != nil {
+		return nil, signatureErr(err)
+	}
+	return &st, nil
+}
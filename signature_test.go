@@ -0,0 +1,51 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_RequestSignature(t *testing.T) {
+	t.Parallel()
+
+	st, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "ContentSignature", req["ServiceName"])
+		assert.Equal(t, "RequestSignature", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{
+			"request": map[string]interface{}{
+				"NodeID":     int64(1),
+				"VersionNum": int64(0),
+				"Signers":    []interface{}{int64(2), int64(3)},
+				"Comment":    "sign",
+			},
+		}, req["Arguments"])
+
+		w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=10,'NodeID'=1,'VersionNum'=2,'State'='Pending'," +
+			"'Signatures'={A<1,?,'Signer'=2,'State'='Pending'>,A<1,?,'Signer'=3,'State'='Pending'>}>>")
+		assert.Nil(t, w.Flush())
+	}).RequestSignature(context.Background(), SignatureRequest{NodeID: 1, Signers: []int64{2, 3}, Comment: "sign"})
+
+	require.Nil(t, err)
+	assert.Equal(t, &SignatureStatus{
+		ID:         10,
+		NodeID:     1,
+		Version:    2,
+		State:      SignaturePending,
+		Signatures: []Signature{{Signer: 2, State: SignaturePending}, {Signer: 3, State: SignaturePending}},
+	}, st)
+}
+
+func TestSession_GetSignatureStatusUnavailable(t *testing.T) {
+	t.Parallel()
+
+	_, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=903102,'_StatusMessage'='not found service'>")
+		assert.Nil(t, w.Flush())
+	}).GetSignatureStatus(context.Background(), 10)
+	assert.Equal(t, ErrSignatureUnavailable, err)
+}
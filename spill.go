@@ -0,0 +1,109 @@
+package ot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// SpillBuffer keeps up to limit bytes in memory, the longer content is moved to the temporary file.
+// Close removes the temporary file.
+type SpillBuffer struct {
+	limit int64
+	dir   string
+	mem   bytes.Buffer
+	f     *os.File
+	size  int64
+}
+
+// NewSpillBuffer creates buffer which keeps up to limit bytes in memory, the temporary file is created
+// in dir or in the default directory when dir is empty.
+func NewSpillBuffer(limit int64, dir string) *SpillBuffer {
+	return &SpillBuffer{limit: limit, dir: dir}
+}
+
+// Write implements io.Writer.
+func (b *SpillBuffer) Write(p []byte) (int, error) {
+	if b.f == nil && int64(b.mem.Len()+len(p)) > b.limit {
+		f, err := ioutil.TempFile(b.dir, "ot-spill-")
+		if err != nil {
+			return 0, err
+		}
+		b.f = f
+
+		if _, err := b.mem.WriteTo(f); err != nil {
+			return 0, err
+		}
+		b.mem = bytes.Buffer{}
+	}
+
+	var (
+		n   int
+		err error
+	)
+	if b.f != nil {
+		n, err = b.f.Write(p)
+	} else {
+		n, err = b.mem.Write(p)
+	}
+
+	b.size += int64(n)
+	return n, err
+}
+
+// Size returns the number of the written bytes.
+func (b *SpillBuffer) Size() int64 {
+	return b.size
+}
+
+// Spilled reports whether the content is moved to the temporary file.
+func (b *SpillBuffer) Spilled() bool {
+	return b.f != nil
+}
+
+// Reader returns reader of the written content, every reader is independent.
+func (b *SpillBuffer) Reader() io.ReadSeeker {
+	if b.f != nil {
+		return io.NewSectionReader(b.f, 0, b.size)
+	}
+	return bytes.NewReader(b.mem.Bytes())
+}
+
+// Close removes the temporary file.
+func (b *SpillBuffer) Close() error {
+	if b.f == nil {
+		return nil
+	}
+
+	err := b.f.Close()
+	if rerr := os.Remove(b.f.Name()); err == nil {
+		err = rerr
+	}
+	b.f = nil
+	return err
+}
+
+// spillReader reads the content of the buffer, Close releases the buffer.
+type spillReader struct {
+	io.ReadSeeker
+	b *SpillBuffer
+}
+
+func (r spillReader) Close() error {
+	return r.b.Close()
+}
+
+// ReadFileSpill reads content into memory up to memLimit bytes, the longer content is written into
+// the temporary file. It is useful when the content must be read many times or sought and its size is unknown.
+// The returned reader must be closed to remove the temporary file.
+func (s *Session) ReadFileSpill(ctx context.Context, id, version, memLimit int64) (io.ReadSeekCloser, *FileAttr, error) {
+	b := NewSpillBuffer(memLimit, "")
+	fa, err := s.ReadFile(ctx, id, version, b)
+	if err != nil {
+		b.Close()
+		return nil, nil, err
+	}
+	return spillReader{ReadSeeker: b.Reader(), b: b}, fa, nil
+}
@@ -0,0 +1,69 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	b := NewSpillBuffer(4, dir)
+	b.Write([]byte("abc"))
+	assert.False(t, b.Spilled())
+
+	b.Write([]byte("def"))
+	require.True(t, b.Spilled())
+	assert.Equal(t, int64(6), b.Size())
+
+	r := b.Reader()
+	r.Seek(2, io.SeekStart)
+	got, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	assert.Equal(t, "cdef", string(got))
+
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 1)
+	require.Nil(t, b.Close())
+	files, _ = os.ReadDir(dir)
+	assert.Len(t, files, 0)
+}
+
+func TestSession_ReadFileSpill(t *testing.T) {
+	t.Parallel()
+
+	contentFile := "content of the file"
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		b, err := ioutil.ReadFile("testdata/read-file")
+		require.Nil(t, err)
+
+		w.Write(b)
+		w.WriteString(contentFile)
+		assert.Nil(t, w.Flush())
+	})
+
+	for _, limit := range []int64{1 << 20, 4} {
+		r, fa, err := s.ReadFileSpill(context.Background(), 1, 0, limit)
+		require.Nil(t, err)
+		assert.Equal(t, int64(len(contentFile)), fa.Size)
+
+		got, err := ioutil.ReadAll(r)
+		require.Nil(t, err)
+		assert.Equal(t, contentFile, string(got))
+
+		_, err = r.Seek(0, io.SeekStart)
+		require.Nil(t, err)
+		got, err = ioutil.ReadAll(r)
+		require.Nil(t, err)
+		assert.Equal(t, contentFile, string(got))
+		assert.Nil(t, r.Close())
+	}
+}
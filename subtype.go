@@ -0,0 +1,105 @@
+package ot
+
+import "strconv"
+
+// Subtype is a subtype of the node.
+type Subtype int
+
+// Common subtypes of the nodes.
+const (
+	SubtypeFolder                Subtype = 0
+	SubtypeShortcut              Subtype = 1 // type "Alias"
+	SubtypeGeneration            Subtype = 2
+	SubtypeWFMap                 Subtype = 128
+	SubtypeCategory              Subtype = 131
+	SubtypeCompoundDoc           Subtype = 136
+	SubtypeURL                   Subtype = 140
+	SubtypeDocument              Subtype = 144
+	SubtypeProject               Subtype = 202
+	SubtypeTaskList              Subtype = 204
+	SubtypeChannel               Subtype = 207
+	SubtypeDiscussion            Subtype = 215
+	SubtypeCollection            Subtype = 298
+	SubtypeReport                Subtype = 299
+	SubtypePhysicalItem          Subtype = 411
+	SubtypePhysicalItemContainer Subtype = 412
+	SubtypePhysicalItemBox       Subtype = 424
+	SubtypeEmail                 Subtype = 749
+)
+
+// subtypeNames are the names of the types as they are returned in Node.Type.
+var subtypeNames = map[Subtype]string{
+	SubtypeFolder:                "Folder",
+	SubtypeShortcut:              "Alias",
+	SubtypeGeneration:            "Generation",
+	SubtypeWFMap:                 "WFMap",
+	SubtypeCategory:              "Category",
+	SubtypeCompoundDoc:           "CompoundDoc",
+	SubtypeURL:                   "URL",
+	SubtypeDocument:              "Document",
+	SubtypeProject:               "Project",
+	SubtypeTaskList:              "TaskList",
+	SubtypeChannel:               "Channel",
+	SubtypeDiscussion:            "Discussion",
+	SubtypeCollection:            "Collection",
+	SubtypeReport:                "Report",
+	SubtypePhysicalItem:          "PhysicalItem",
+	SubtypePhysicalItemContainer: "PhysicalItemContainer",
+	SubtypePhysicalItemBox:       "PhysicalItemBox",
+	SubtypeEmail:                 "Email",
+}
+
+var subtypeByName = func() map[string]Subtype {
+	m := make(map[string]Subtype, len(subtypeNames))
+	for st, name := range subtypeNames {
+		m[name] = st
+	}
+	return m
+}()
+
+// String returns the name of the type as it is in Node.Type, the subtype without the name is formatted as number.
+func (st Subtype) String() string {
+	if name, ok := subtypeNames[st]; ok {
+		return name
+	}
+	return strconv.Itoa(int(st))
+}
+
+// ParseSubtype returns subtype by the name of the type or by the number, e.g. "Document" or "30000".
+func ParseSubtype(typ string) (Subtype, bool) {
+	if st, ok := subtypeByName[typ]; ok {
+		return st, true
+	}
+
+	i, err := strconv.Atoi(typ)
+	if err != nil {
+		return 0, false
+	}
+	return Subtype(i), true
+}
+
+// Subtype returns subtype of the node, false is returned when the type is unknown.
+func (n *Node) Subtype() (Subtype, bool) {
+	return ParseSubtype(n.Type)
+}
+
+// IsType reports whether the node has the subtype.
+func (n *Node) IsType(st Subtype) bool {
+	v, ok := n.Subtype()
+	return ok && v == st
+}
+
+// IsDocument reports whether the node is a document.
+func (n *Node) IsDocument() bool {
+	return n.IsType(SubtypeDocument)
+}
+
+// IsFolder reports whether the node is a folder.
+func (n *Node) IsFolder() bool {
+	return n.IsType(SubtypeFolder)
+}
+
+// IsShortcut reports whether the node is a shortcut.
+func (n *Node) IsShortcut() bool {
+	return n.IsType(SubtypeShortcut)
+}
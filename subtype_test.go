@@ -0,0 +1,50 @@
+package ot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSubtype(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		typ  string
+		want Subtype
+		ok   bool
+	}{
+		{typ: "Document", want: SubtypeDocument, ok: true},
+		{typ: "Folder", want: SubtypeFolder, ok: true},
+		{typ: "Alias", want: SubtypeShortcut, ok: true},
+		{typ: "30000", want: 30000, ok: true},
+		{typ: "144", want: SubtypeDocument, ok: true},
+		{typ: "Unknown", ok: false},
+		{typ: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		st, ok := ParseSubtype(tt.typ)
+		assert.Equal(t, tt.ok, ok, tt.typ)
+		assert.Equal(t, tt.want, st, tt.typ)
+	}
+}
+
+func TestSubtype_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Document", SubtypeDocument.String())
+	assert.Equal(t, "Alias", SubtypeShortcut.String())
+	assert.Equal(t, "30000", Subtype(30000).String())
+}
+
+func TestNode_IsType(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, (&Node{Type: "Document"}).IsDocument())
+	assert.True(t, (&Node{Type: "Folder"}).IsFolder())
+	assert.True(t, (&Node{Type: "Alias"}).IsShortcut())
+	assert.False(t, (&Node{Type: "Folder"}).IsDocument())
+	assert.False(t, (&Node{}).IsFolder())
+	assert.True(t, (&Node{Type: "30000"}).IsType(30000))
+}
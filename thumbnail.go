@@ -0,0 +1,71 @@
+package ot
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// ThumbnailSize is a bounding box of the thumbnail, the aspect ratio of the page is kept.
+type ThumbnailSize struct {
+	Width  int `oscript:"Width"`
+	Height int `oscript:"Height"`
+}
+
+var (
+	ThumbnailSmall  = ThumbnailSize{Width: 64, Height: 64}
+	ThumbnailMedium = ThumbnailSize{Width: 256, Height: 256}
+	ThumbnailLarge  = ThumbnailSize{Width: 1024, Height: 1024}
+)
+
+// ThumbnailKey returns key of the thumbnail for the cache of the client, e.g. of the gallery view.
+// The thumbnail of the numbered version never changes, so the cached thumbnail may be kept without expiration
+// and a new version gets a new key. Zero version is the latest one, its thumbnail changes with the next version,
+// so the key should be of the resolved number, e.g. NodeVersionInfo.VersionNum.
+func ThumbnailKey(id, version int64, size ThumbnailSize) string {
+	return fmt.Sprintf("%d/%d/%dx%d", id, version, size.Width, size.Height)
+}
+
+// RequestThumbnail requests generation of the thumbnail of the version in the background,
+// zero version means the latest version. It allows to prepare thumbnails of the gallery before they are shown.
+func (s *Session) RequestThumbnail(ctx context.Context, id, version int64, size ThumbnailSize) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return errIn(c.Exec(docmanService, "RequestThumbnail", s.auth, oscript.M{"ID": id, "versionNum": version, "size": size}, nil))
+}
+
+// GetThumbnail writes the thumbnail of the version to w, zero version means the latest version.
+// ErrThumbnailNotReady is returned when the thumbnail is still generated, see RequestThumbnail.
+func (s *Session) GetThumbnail(ctx context.Context, id, version int64, size ThumbnailSize, w io.Writer) (*FileAttr, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if err := c.Write(docmanService, "GetThumbnail", s.auth, oscript.M{"ID": id, "versionNum": version, "size": size}); err != nil {
+		return nil, err
+	}
+
+	fa := &FileAttr{}
+	if err := errIn(c.ReadFile(fa)); err != nil {
+		return nil, err
+	}
+
+	if fa.Size == 0 {
+		return nil, ErrThumbnailNotReady
+	}
+
+	if err := c.ReadTo(w, fa.Size); err != nil {
+		return nil, err
+	}
+
+	fa.NodeID = id
+	return fa, nil
+}
@@ -0,0 +1,49 @@
+package ot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Thumbnail(t *testing.T) {
+	t.Parallel()
+
+	png := "\x89PNG thumbnail"
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"Width": int64(64), "Height": int64(64)}, args["size"])
+
+		switch req["ServiceMethod"] {
+		case "RequestThumbnail":
+			w.WriteString("A<1,?,'_Status'=0>")
+		case "GetThumbnail":
+			if args["ID"] == int64(1) {
+				fmt.Fprintf(w, "A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=%d,'Name'='1.png'>,'_Status'=0>", len(png))
+				w.WriteString(png)
+			} else {
+				w.WriteString("A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=0>,'_Status'=0>")
+			}
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	require.Nil(t, s.RequestThumbnail(context.Background(), 1, 0, ThumbnailSmall))
+
+	var buf bytes.Buffer
+	fa, err := s.GetThumbnail(context.Background(), 1, 0, ThumbnailSmall, &buf)
+	require.Nil(t, err)
+	assert.Equal(t, png, buf.String())
+	assert.Equal(t, int64(1), fa.NodeID)
+
+	_, err = s.GetThumbnail(context.Background(), 2, 0, ThumbnailSmall, &buf)
+	assert.Equal(t, ErrThumbnailNotReady, err)
+
+	assert.Equal(t, "1/2/64x64", ThumbnailKey(1, 2, ThumbnailSmall))
+}
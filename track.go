@@ -0,0 +1,155 @@
+package ot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// AttrChange is a change of the values of the attribute since Category.Track.
+type AttrChange struct {
+	Category string
+	Name     string
+	Old      []interface{}
+	New      []interface{}
+}
+
+func (c AttrChange) String() string {
+	return fmt.Sprintf("%s/%s: %s -> %s", c.Category, c.Name, formatValues(c.Old), formatValues(c.New))
+}
+
+func formatValues(v []interface{}) string {
+	s := make([]string, len(v))
+	for i, e := range v {
+		s[i] = fmt.Sprintf("%#v", e)
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+// tracking is the state of Category.Track. The values at the time of Track are never modified, so the copies
+// of the category share them. The mutex guards the values of the category, the categories sharing Data share it too.
+type tracking struct {
+	mu     sync.Mutex
+	values []Value
+}
+
+// copy returns the state for the copy of the category, the copy has own values and mutex.
+func (t *tracking) copy() *tracking {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &tracking{values: t.values}
+}
+
+// Track starts tracking of the changes of the values, Changes returns the attributes modified since the call.
+// The values are copied, so the tracking is not affected by the modifications of the category and its copies.
+// Track must be called before the category is shared by the goroutines, Set, Track and Changes of the tracked
+// category are safe for concurrent use.
+func (c *Category) Track() {
+	if c.tracked == nil {
+		c.tracked = &tracking{}
+	}
+
+	c.tracked.mu.Lock()
+	defer c.tracked.mu.Unlock()
+
+	values := make([]Value, len(c.Data))
+	for i, v := range c.Data {
+		values[i] = v
+		values[i].Value = append([]interface{}(nil), v.Value...)
+	}
+	c.tracked.values = values
+}
+
+// Tracked reports whether the changes of the category are tracked.
+func (c *Category) Tracked() bool {
+	return c.tracked != nil
+}
+
+// Changes returns the attributes modified since Track in the order of the category, nil when the category
+// is not tracked. The attributes are matched by Key, by Description when the key is empty.
+func (c *Category) Changes() []AttrChange {
+	if c.tracked == nil {
+		return nil
+	}
+
+	c.tracked.mu.Lock()
+	defer c.tracked.mu.Unlock()
+
+	var changes []AttrChange
+	for _, v := range c.Data {
+		var old []interface{}
+		for _, t := range c.tracked.values {
+			if t.trackKey() == v.trackKey() {
+				old = t.Value
+				break
+			}
+		}
+
+		if !equalValues(old, v.Value) {
+			changes = append(changes, AttrChange{Category: c.DisplayName, Name: v.Description, Old: old, New: v.Value})
+		}
+	}
+	return changes
+}
+
+func (v Value) trackKey() string {
+	if v.Key != "" {
+		return v.Key
+	}
+	return v.Description
+}
+
+// equalValues reports whether the values are equal, nil and empty values are equal.
+func equalValues(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !reflect.DeepEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Modified reports whether the category is not tracked or its values are modified since Track.
+func (c *Category) Modified() bool {
+	return c.tracked == nil || len(c.Changes()) > 0
+}
+
+// Track starts tracking of the changes of all categories.
+func (m Metadata) Track() {
+	for i := range m.Categories {
+		m.Categories[i].Track()
+	}
+}
+
+// Changes returns the modified attributes of the tracked categories.
+func (m Metadata) Changes() []AttrChange {
+	var changes []AttrChange
+	for i := range m.Categories {
+		changes = append(changes, m.Categories[i].Changes()...)
+	}
+	return changes
+}
+
+// modified returns the metadata sent by UpdateNode: the tracked categories without the changes are omitted.
+func (m Metadata) modified() Metadata {
+	var categories []Category
+	for i := range m.Categories {
+		if m.Categories[i].Modified() {
+			categories = append(categories, m.Categories[i])
+		}
+	}
+
+	if len(categories) == len(m.Categories) {
+		return m
+	}
+	return Metadata{Categories: categories}
+}
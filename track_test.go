@@ -0,0 +1,95 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategory_Track(t *testing.T) {
+	t.Parallel()
+
+	cat := Category{DisplayName: "Contract", Data: []Value{
+		{Description: "Status", Type: StringType, Value: []interface{}{"open"}},
+		{Description: "Amount", Type: IntType, Value: []interface{}{10}},
+	}}
+	assert.Nil(t, cat.Changes())
+	assert.True(t, cat.Modified(), "untracked category is always modified")
+
+	cat.Track()
+	assert.False(t, cat.Modified())
+
+	cp := cat.Copy()
+	require.Nil(t, cp.Set(AttrString("Status", "signed")))
+	assert.False(t, cat.Modified(), "the copy does not affect the original")
+
+	require.Nil(t, cat.Set(AttrString("Status", "closed"), AttrInt("Amount", 10)))
+	changes := cat.Changes()
+	assert.Equal(t, []AttrChange{{Category: "Contract", Name: "Status", Old: []interface{}{"open"}, New: []interface{}{"closed"}}}, changes)
+	assert.Equal(t, `Contract/Status: ["open"] -> ["closed"]`, changes[0].String())
+	assert.Equal(t, "Status", cp.Changes()[0].Name)
+}
+
+func TestCategory_TrackKey(t *testing.T) {
+	t.Parallel()
+
+	cat := Category{DisplayName: "Contract", Data: []Value{
+		{Description: "Tags", Key: "1.1.2", Type: StringType, Value: []interface{}{}},
+		{Description: "Status", Key: "1.1.3", Type: StringType, Value: []interface{}{"open"}},
+	}}
+	cat.Track()
+	assert.False(t, cat.Modified(), "empty values are not changed")
+
+	cat.Data[1].Description = "State" // renamed in the new version of the category
+	assert.False(t, cat.Modified(), "the attributes are matched by key")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.Nil(t, cat.Set(AttrString("State", strconv.Itoa(i))))
+			cat.Changes()
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, cat.Modified())
+}
+
+func TestSession_UpdateNodeChanges(t *testing.T) {
+	t.Parallel()
+
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		node := req["Arguments"].(map[string]interface{})["node"].(map[string]interface{})
+		groups := node["Metadata"].(map[string]interface{})["AttributeGroups"].([]interface{})
+		require.Len(t, groups, 1, "the unmodified category is not sent")
+		assert.Equal(t, "Contract", groups[0].(map[string]interface{})["DisplayName"])
+		w.WriteString("A<1,?,'_Status'=0>")
+		assert.Nil(t, w.Flush())
+	})
+
+	bus := NewEventBus()
+	var events []Event
+	bus.Subscribe(func(e Event) { events = append(events, e) })
+
+	node := &Node{ID: 1, Metadata: Metadata{Categories: []Category{{DisplayName: "Contract", Data: []Value{
+		{Description: "Status", Type: StringType, Value: []interface{}{"open"}},
+	}}, {DisplayName: "Invoice", Data: []Value{
+		{Description: "Amount", Type: IntType, Value: []interface{}{10}},
+	}}}}}
+	node.Metadata.Track()
+	require.Nil(t, node.Metadata.Categories[0].Set(AttrString("Status", "closed")))
+
+	require.Nil(t, s.WithEvents(bus).UpdateNode(context.Background(), node))
+	require.Len(t, events, 1)
+	require.Len(t, events[0].Changes, 1)
+	assert.Equal(t, []interface{}{"closed"}, events[0].Changes[0].New)
+	assert.False(t, node.Metadata.Categories[0].Modified(), "tracked from the saved values")
+	assert.Len(t, node.Metadata.Categories, 2)
+}
@@ -0,0 +1,105 @@
+package ot
+
+import (
+	"context"
+	"sort"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+// GetVersion gets version of the node, zero number means the latest version.
+func (s *Session) GetVersion(ctx context.Context, id, number int64) (*Version, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var v Version
+	if err := errIn(c.Exec(docmanService, "GetVersion", s.auth, oscript.M{"ID": id, "versionNum": number}, &v)); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListVersions returns the complete history of the versions.
+func (s *Session) ListVersions(ctx context.Context, id int64) ([]Version, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var versions []Version
+	if err := errIn(c.Exec(docmanService, "ListVersions", s.auth, oscript.M{"ID": id}, &versions)); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// VersionIterator iterates over versions of the node from the latest to the first one.
+// The history is requested once by the first call of Next, purged versions are absent in it.
+type VersionIterator struct {
+	s        *Session
+	id       int64
+	listed   bool
+	versions []Version // remaining versions from the latest
+	v        *Version
+	err      error
+}
+
+// Versions returns iterator over versions of the node from the latest to the first one.
+func (s *Session) Versions(id int64) *VersionIterator {
+	return &VersionIterator{s: s, id: id}
+}
+
+// Next gets the next version, it returns false when versions are exhausted or an error occurred.
+func (it *VersionIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.listed {
+		versions, err := it.s.ListVersions(ctx, it.id)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Number > versions[j].Number })
+		it.versions, it.listed = versions, true
+	}
+
+	if len(it.versions) == 0 {
+		it.v = nil
+		return false
+	}
+
+	it.v = &it.versions[0]
+	it.versions = it.versions[1:]
+	return true
+}
+
+// Version returns the current version.
+func (it *VersionIterator) Version() *Version {
+	return it.v
+}
+
+// Err returns the error occurred during iteration.
+func (it *VersionIterator) Err() error {
+	return it.err
+}
+
+// LatestVersions returns up to n latest versions of the node, the latest is first.
+func (s *Session) LatestVersions(ctx context.Context, id int64, n int) ([]Version, error) {
+	var versions []Version
+	it := s.Versions(id)
+	for len(versions) < n && it.Next(ctx) {
+		versions = append(versions, *it.Version())
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
@@ -0,0 +1,54 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LatestVersions(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	versions, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "ListVersions", req["ServiceMethod"])
+		calls++
+
+		// version 3 is purged
+		w.WriteString("A<1,?,'_Status'=0,'Results'={" +
+			"A<1,?,'NodeID'=1,'Number'=1>,A<1,?,'NodeID'=1,'Number'=2>,A<1,?,'NodeID'=1,'Number'=4>}>")
+		assert.Nil(t, w.Flush())
+	}).LatestVersions(context.Background(), 1, 3)
+	require.Nil(t, err)
+	assert.Equal(t, []Version{{NodeID: 1, Number: 4}, {NodeID: 1, Number: 2}, {NodeID: 1, Number: 1}}, versions)
+	assert.Equal(t, 1, calls)
+}
+
+func Test_VersionIteratorEmpty(t *testing.T) {
+	t.Parallel()
+
+	it := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=0,'Results'={}>")
+		assert.Nil(t, w.Flush())
+	}).Versions(1)
+	assert.False(t, it.Next(context.Background()))
+	assert.False(t, it.Next(context.Background()))
+	assert.Nil(t, it.Version())
+	assert.Nil(t, it.Err())
+}
+
+func Test_VersionIteratorErr(t *testing.T) {
+	t.Parallel()
+
+	it := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		w.WriteString("A<1,?,'_Status'=1,'_StatusMessage'='error','_errMsg'='error'>")
+		assert.Nil(t, w.Flush())
+	}).Versions(1)
+	assert.False(t, it.Next(context.Background()))
+	assert.Nil(t, it.Version())
+	assert.EqualError(t, it.Err(), "ot: error")
+}
@@ -0,0 +1,44 @@
+package ot
+
+import (
+	"context"
+	"io"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const webReportsService = "WebReports"
+
+// WebReportOutput is the output of the executed web report.
+type WebReportOutput struct {
+	MimeType string `oscript:"MimeType"` // e.g. text/html, text/csv
+	Size     int64  `oscript:"DataForkSize"`
+}
+
+// ExecWebReport executes the web report and writes its output to w, params are the parameters
+// of the report as they are passed in the URL of the report.
+func (s *Session) ExecWebReport(ctx context.Context, nodeID int64, params map[string]string, w io.Writer) (*WebReportOutput, error) {
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if err := c.Write(webReportsService, "RunReport", s.auth, oscript.M{"ID": nodeID, "parameters": params}); err != nil {
+		return nil, err
+	}
+
+	var out WebReportOutput
+	if err := errIn(c.ReadFile(&out)); err != nil {
+		return nil, err
+	}
+
+	if err := c.ReadTo(w, out.Size); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
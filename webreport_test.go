@@ -0,0 +1,33 @@
+package ot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_ExecWebReport(t *testing.T) {
+	t.Parallel()
+
+	csv := "id,name\n1,a\n"
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "WebReports", req["ServiceName"])
+		assert.Equal(t, map[string]interface{}{"ID": int64(5), "parameters": map[string]interface{}{"from": "2020-01-01"}}, req["Arguments"])
+
+		fmt.Fprintf(w, "A<1,?,'FileAttributes'=A<1,?,'DataForkSize'=%d,'MimeType'='text/csv'>,'_Status'=0>", len(csv))
+		w.WriteString(csv)
+		assert.Nil(t, w.Flush())
+	})
+
+	var buf bytes.Buffer
+	out, err := s.ExecWebReport(context.Background(), 5, map[string]string{"from": "2020-01-01"}, &buf)
+	require.Nil(t, err)
+	assert.Equal(t, &WebReportOutput{MimeType: "text/csv", Size: int64(len(csv))}, out)
+	assert.Equal(t, csv, buf.String())
+}
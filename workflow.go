@@ -0,0 +1,154 @@
+package ot
+
+import (
+	"context"
+	"time"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const workflowService = "WorkflowService"
+
+// WorkItem identifies the step of the workflow.
+type WorkItem struct {
+	ProcessID    int64 `oscript:"ProcessID"`
+	SubProcessID int64 `oscript:"SubProcessID"`
+	TaskID       int64 `oscript:"TaskID"`
+}
+
+func (item WorkItem) args() oscript.M {
+	return oscript.M{
+		"processID":    item.ProcessID,
+		"subprocessID": item.SubProcessID,
+		"taskID":       item.TaskID,
+	}
+}
+
+// AssignmentPriority is a priority of the assignment.
+type AssignmentPriority int
+
+const (
+	PriorityLow    AssignmentPriority = 0
+	PriorityMedium AssignmentPriority = 50
+	PriorityHigh   AssignmentPriority = 100
+)
+
+// Assignment is a step of the workflow assigned to the user.
+type Assignment struct {
+	WorkItem
+	Workflow     string             `oscript:"WorkflowName"`
+	Step         string             `oscript:"Name"`
+	Instructions string             `oscript:"Instructions"`
+	From         int64              `oscript:"FromUserID"`
+	Due          time.Time          `oscript:"DueDate"` // zero when the step has no due date
+	Priority     AssignmentPriority `oscript:"Priority"`
+	Accepted     bool               `oscript:"Accepted"`
+}
+
+// MyAssignments returns pager of the assignments of the user of the session, the pages contain up to size assignments.
+func (s *Session) MyAssignments(size int) *Pager[[]Assignment] {
+	return NewPager(s, workflowService+".ListMyAssignments", oscript.M{"pageNumber": 1, "pageSize": size},
+		PageNumber[Assignment]("pageNumber", size))
+}
+
+// assignmentPageSize is the size of the pages of ListMyAssignments.
+const assignmentPageSize = 100
+
+// ListMyAssignments returns all assignments of the user of the session.
+func (s *Session) ListMyAssignments(ctx context.Context) ([]Assignment, error) {
+	var list []Assignment
+	p := s.MyAssignments(assignmentPageSize)
+	for p.Next(ctx) {
+		list = append(list, p.Page()...)
+	}
+
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// AcceptAssignment accepts the assignment shared with the group, so the other members can not work on it.
+func (s *Session) AcceptAssignment(ctx context.Context, item WorkItem) error {
+	return s.execWorkItem(ctx, "AcceptWorkItem", item.args())
+}
+
+// CompleteAssignment completes the step of the workflow with the disposition, empty disposition is used
+// for the steps without the choice.
+func (s *Session) CompleteAssignment(ctx context.Context, item WorkItem, disposition string) error {
+	args := item.args()
+	args["disposition"] = disposition
+	return s.execWorkItem(ctx, "CompleteWorkItem", args)
+}
+
+// DelegateAssignment delegates the assignment to the user.
+func (s *Session) DelegateAssignment(ctx context.Context, item WorkItem, user int64) error {
+	args := item.args()
+	args["userID"] = user
+	return s.execWorkItem(ctx, "DelegateWorkItem", args)
+}
+
+func (s *Session) execWorkItem(ctx context.Context, method string, args oscript.M) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return errIn(c.Exec(workflowService, method, s.auth, args, nil))
+}
+
+// GetAttachmentFolder gets the folder of the attachments of the work item,
+// the folder is in the attachment volume of the workflow.
+func (s *Session) GetAttachmentFolder(ctx context.Context, item WorkItem) (*Node, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var node Node
+	if err := errIn(c.Exec(workflowService, "GetWorkItemAttachmentFolder", s.auth, item.args(), &node)); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// ListAttachments returns the attachments of the work item.
+func (s *Session) ListAttachments(ctx context.Context, item WorkItem) ([]Node, error) {
+	folder, err := s.GetAttachmentFolder(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListNodes(ctx, folder.ID)
+}
+
+// AddAttachment creates the document in the attachment folder of the work item, doc.Parent is ignored.
+func (s *Session) AddAttachment(ctx context.Context, item WorkItem, doc Document) (*Node, error) {
+	folder, err := s.GetAttachmentFolder(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Parent = folder.ID
+	return s.CreateDocument(ctx, doc)
+}
+
+// RemoveAttachment deletes the document from the attachment folder of the work item.
+// ErrNotAttachment is returned when the node is not in the folder, the node is not deleted then.
+func (s *Session) RemoveAttachment(ctx context.Context, item WorkItem, id int64) error {
+	folder, err := s.GetAttachmentFolder(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	node, err := s.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if node.Parent != folder.ID {
+		return ErrNotAttachment
+	}
+	return s.DeleteNode(ctx, id)
+}
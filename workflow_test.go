@@ -0,0 +1,127 @@
+package ot
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func attachmentFolder(t *testing.T, w *bufio.Writer, req map[string]interface{}) {
+	assert.Equal(t, "WorkflowService", req["ServiceName"])
+	assert.Equal(t, map[string]interface{}{
+		"processID":    int64(1),
+		"subprocessID": int64(1),
+		"taskID":       int64(3),
+	}, req["Arguments"])
+	w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=100,'Name'='Attachments','Type'='WFAttachments','IsContainer'=true>>")
+}
+
+func TestSession_AddAttachment(t *testing.T) {
+	t.Parallel()
+
+	content := "content of the file"
+	fa := &FileAttr{Name: "test", Size: int64(len(content))}
+	node, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "GetWorkItemAttachmentFolder":
+			attachmentFolder(t, w, req)
+		case "CreateDocument":
+			assert.Equal(t, int64(100), req["Arguments"].(map[string]interface{})["parentID"])
+
+			file := make([]byte, len(content))
+			_, err := io.ReadFull(r, file)
+			require.Nil(t, err)
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=5,'Name'='test','ParentID'=100>>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).AddAttachment(context.Background(), WorkItem{ProcessID: 1, SubProcessID: 1, TaskID: 3}, Document{Name: "test", File: fa, Reader: strings.NewReader(content)})
+	require.Nil(t, err)
+
+	assert.Equal(t, int64(5), node.ID)
+	assert.Equal(t, int64(100), node.Parent)
+}
+
+func TestSession_RemoveAttachment(t *testing.T) {
+	t.Parallel()
+
+	var deleted []interface{}
+	s := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		args := req["Arguments"].(map[string]interface{})
+		switch req["ServiceMethod"] {
+		case "GetWorkItemAttachmentFolder":
+			attachmentFolder(t, w, req)
+		case "GetNode":
+			if args["ID"] == int64(5) {
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=5,'ParentID'=100>>")
+			} else {
+				w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=6,'ParentID'=2000>>")
+			}
+		case "DeleteNode":
+			deleted = append(deleted, args["ID"])
+			w.WriteString("A<1,?,'_Status'=0>")
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	})
+
+	item := WorkItem{ProcessID: 1, SubProcessID: 1, TaskID: 3}
+	require.Nil(t, s.RemoveAttachment(context.Background(), item, 5))
+	assert.Equal(t, ErrNotAttachment, s.RemoveAttachment(context.Background(), item, 6))
+	assert.Equal(t, []interface{}{int64(5)}, deleted)
+}
+
+func TestSession_ListMyAssignments(t *testing.T) {
+	t.Parallel()
+
+	list, err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "ListMyAssignments", req["ServiceMethod"])
+		args := req["Arguments"].(map[string]interface{})
+		assert.Equal(t, int64(assignmentPageSize), args["pageSize"])
+
+		switch args["pageNumber"] {
+		case int64(1):
+			w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ProcessID'=1,'SubProcessID'=1,'TaskID'=3,'WorkflowName'='Invoice'," +
+				"'Name'='Approve','DueDate'=D/2020/1/2:10:0:0,'Priority'=100,'Accepted'=true>}>")
+		default:
+			t.Errorf("unexpected page %v", args)
+		}
+		assert.Nil(t, w.Flush())
+	}).ListMyAssignments(context.Background())
+	require.Nil(t, err)
+
+	assert.Equal(t, []Assignment{{
+		WorkItem: WorkItem{ProcessID: 1, SubProcessID: 1, TaskID: 3},
+		Workflow: "Invoice",
+		Step:     "Approve",
+		Due:      time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC),
+		Priority: PriorityHigh,
+		Accepted: true,
+	}}, list)
+}
+
+func TestSession_CompleteAssignment(t *testing.T) {
+	t.Parallel()
+
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		assert.Equal(t, "CompleteWorkItem", req["ServiceMethod"])
+		assert.Equal(t, map[string]interface{}{
+			"processID":    int64(1),
+			"subprocessID": int64(1),
+			"taskID":       int64(3),
+			"disposition":  "Approve",
+		}, req["Arguments"])
+
+		w.WriteString("A<1,?,'_Status'=0>")
+		assert.Nil(t, w.Flush())
+	}).CompleteAssignment(context.Background(), WorkItem{ProcessID: 1, SubProcessID: 1, TaskID: 3}, "Approve")
+	require.Nil(t, err)
+}
@@ -0,0 +1,68 @@
+package ot
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+
+	"github.com/itcomusic/ot/pkg/oscript"
+)
+
+const zipService = "ZipDownload"
+
+// DownloadZip writes in w zip archive of the nodes with all descendants.
+// The archive is created by the server when the zip service is available,
+// otherwise it is assembled on the client side.
+func (s *Session) DownloadZip(ctx context.Context, ids []int64, w io.Writer) error {
+	err := s.downloadZip(ctx, ids, w)
+	if _, ok := err.(*ServiceNotFoundError); !ok {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, id := range ids {
+		if err := s.Walk(ctx, id, func(p string, node *Node) error {
+			if node.IsContainer {
+				_, err := zw.Create(p + "/")
+				return err
+			}
+
+			if !node.IsVersional {
+				return nil
+			}
+
+			f, err := zw.CreateHeader(&zip.FileHeader{Name: p, Method: zip.Deflate, Modified: node.ModifyDate})
+			if err != nil {
+				return err
+			}
+
+			_, err = s.ReadFile(ctx, node.ID, 0, f) // 0 is the latest version
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// downloadZip requests archive from the server.
+func (s *Session) downloadZip(ctx context.Context, ids []int64, w io.Writer) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Write(zipService, "GetZipContents", s.auth, oscript.M{"IDs": ids}); err != nil {
+		return err
+	}
+
+	if err := errIn(c.ReadFile(&FileAttr{})); err != nil {
+		return err
+	}
+
+	if err := c.ReadTo(w, -1); err != nil { // size of the archive is unknown
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package ot
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_DownloadZip(t *testing.T) {
+	t.Parallel()
+
+	contentFile := "content of the file"
+	var w bytes.Buffer
+	err := session(t, func(r io.Reader, w *bufio.Writer, req map[string]interface{}) {
+		switch req["ServiceMethod"] {
+		case "GetZipContents":
+			w.WriteString("A<1,?,'_Status'=903102,'_StatusMessage'='not found service'>")
+		case "GetNode":
+			w.WriteString("A<1,?,'_Status'=0,'Results'=A<1,?,'ID'=1,'Name'='root','Type'='Folder','IsContainer'=true>>")
+		case "ListNodes":
+			w.WriteString("A<1,?,'_Status'=0,'Results'={A<1,?,'ID'=2,'Name'='test.txt','Type'='Document','IsVersionable'=true>}>")
+		case "GetVersionContents":
+			b, err := ioutil.ReadFile("testdata/read-file")
+			require.Nil(t, err)
+			w.Write(b)
+			w.WriteString(contentFile)
+		default:
+			t.Errorf("unexpected method %s", req["ServiceMethod"])
+		}
+		assert.Nil(t, w.Flush())
+	}).DownloadZip(context.Background(), []int64{1}, &w)
+	require.Nil(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Bytes()), int64(w.Len()))
+	require.Nil(t, err)
+	require.Len(t, zr.File, 2)
+	assert.Equal(t, "root/", zr.File[0].Name)
+	assert.Equal(t, "root/test.txt", zr.File[1].Name)
+
+	f, err := zr.File[1].Open()
+	require.Nil(t, err)
+	b, err := ioutil.ReadAll(f)
+	require.Nil(t, err)
+	assert.Equal(t, contentFile, string(b))
+}